@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/atadzan/grinex-rate-service/internal/config"
+	"github.com/atadzan/grinex-rate-service/internal/database"
+	"github.com/atadzan/grinex-rate-service/internal/export"
+	"github.com/atadzan/grinex-rate-service/internal/service"
+)
+
+// runExport implements the export subcommand, dumping stored rates to CSV. It accepts the
+// configuration flags of the server (--config, --db-host, ...) next to its own:
+//
+//	grinex-rate-service export --pair usdtrub --from 2025-07-01T00:00:00Z --to 2025-07-02T00:00:00Z --out rates.csv
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	pair := flags.String("pair", service.USDTMarket, "Grinex market symbol (e.g. usdtrub) or trading pair label (e.g. USDT/RUB)")
+	from := flags.String("from", "", "Start of the time range in RFC3339, defaults to 24 hours before --to")
+	to := flags.String("to", "", "End of the time range in RFC3339, defaults to now")
+	out := flags.String("out", "-", "Output file, - for stdout")
+	configFlags := config.RegisterFlags(flags)
+	_ = flags.Parse(args) // ExitOnError never returns an error
+
+	end := time.Now()
+	if *to != "" {
+		parsed, err := time.Parse(time.RFC3339, *to)
+		if err != nil {
+			return fmt.Errorf("invalid --to: %w", err)
+		}
+		end = parsed
+	}
+
+	start := end.Add(-24 * time.Hour)
+	if *from != "" {
+		parsed, err := time.Parse(time.RFC3339, *from)
+		if err != nil {
+			return fmt.Errorf("invalid --from: %w", err)
+		}
+		start = parsed
+	}
+
+	cfg, err := configFlags.Load()
+	if err != nil && !errors.Is(err, config.ErrConfigFileNotFound) {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	logger, err := initLogger(cfg.Logging.Level)
+	if err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	defer logger.Sync()
+
+	labels, err := service.MergePairLabels(cfg.Grinex.PairLabelsFile, cfg.Grinex.PairLabels)
+	if err != nil {
+		return fmt.Errorf("failed to load pair labels: %w", err)
+	}
+	tradingPair := *pair
+	if !strings.Contains(tradingPair, "/") {
+		tradingPair = service.LookupPairLabel(labels, tradingPair)
+	}
+
+	db, err := database.NewDatabase(&database.Config{
+		DSN:             cfg.Database.GetDSN(),
+		MaxQueryRange:   cfg.Database.MaxQueryRange,
+		Schema:          cfg.Database.Schema,
+		TablePrefix:     cfg.Database.TablePrefix,
+		MaxOpenConns:    cfg.Database.MaxOpenConns,
+		MaxIdleConns:    cfg.Database.MaxIdleConns,
+		ConnMaxLifetime: cfg.Database.ConnMaxLifetime,
+		ConnMaxIdleTime: cfg.Database.ConnMaxIdleTime,
+	}, logger)
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer db.Close()
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	count, err := export.WriteRatesCSV(w, db, tradingPair, start, end)
+	if err != nil {
+		return fmt.Errorf("failed to export rates: %w", err)
+	}
+
+	logger.Info("Exported rates to CSV",
+		zap.String("trading_pair", tradingPair),
+		zap.Time("from", start),
+		zap.Time("to", end),
+		zap.String("out", *out),
+		zap.Int("rows", count),
+	)
+
+	return nil
+}
@@ -2,10 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
+	_ "time/tzdata" // Embed the timezone database for minimal container images
 
 	"go.uber.org/zap"
 
@@ -14,7 +17,19 @@ import (
 )
 
 func main() {
-	cfg := config.Load()
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		if err := runExport(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Export failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	cfg, cfgErr := config.Load()
+	if cfgErr != nil && !errors.Is(cfgErr, config.ErrConfigFileNotFound) {
+		fmt.Printf("Failed to load configuration: %v\n", cfgErr)
+		os.Exit(1)
+	}
 
 	logger, err := initLogger(cfg.Logging.Level)
 	if err != nil {
@@ -23,11 +38,23 @@ func main() {
 	}
 	defer logger.Sync()
 
-	_, err = server.SetupMetrics()
+	if cfgErr != nil {
+		logger.Warn("Config file not found, using defaults, environment and flags", zap.Error(cfgErr))
+	}
+
+	meterProvider, err := server.SetupMetrics(cfg.Metrics)
 	if err != nil {
 		logger.Error("Failed to setup metrics", zap.Error(err))
 		os.Exit(1)
 	}
+	defer func() {
+		// Flushes metrics collected since the last OTLP export
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := meterProvider.Shutdown(shutdownCtx); err != nil {
+			logger.Warn("Failed to shut down metrics", zap.Error(err))
+		}
+	}()
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -1,78 +1,323 @@
 package config
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io/fs"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
+// Behaviors when fetching a rate from Grinex fails
+const (
+	OnFailureError     = "error"
+	OnFailureLastKnown = "last_known"
+)
+
+// Serve modes
+const (
+	// ServeModeLive fetches rates from Grinex
+	ServeModeLive = "live"
+	// ServeModeDBOnly serves the latest stored rates without ever calling Grinex
+	ServeModeDBOnly = "db_only"
+)
+
+// Metrics backends
+const (
+	// MetricsBackendPrometheus exposes metrics for Prometheus to scrape
+	MetricsBackendPrometheus = "prometheus"
+	// MetricsBackendOTLP pushes metrics to an OTLP collector over gRPC
+	MetricsBackendOTLP = "otlp"
+)
+
 // Config holds all configuration for the application
 type Config struct {
 	Server   ServerConfig   `mapstructure:"server"`
 	Database DatabaseConfig `mapstructure:"database"`
 	Grinex   GrinexConfig   `mapstructure:"grinex"`
 	Logging  LoggingConfig  `mapstructure:"logging"`
+	Metrics  MetricsConfig  `mapstructure:"metrics"`
+	Poller   PollerConfig   `mapstructure:"poller"`
 }
 
 type ServerConfig struct {
-	Port string `mapstructure:"port"`
+	Port              string        `mapstructure:"port"`
+	RequiredMetadata  []string      `mapstructure:"required_metadata"`
+	AlertPollInterval time.Duration `mapstructure:"alert_poll_interval"`
+	AlertDebounce     time.Duration `mapstructure:"alert_debounce"`
+	AlertPollJitter   int           `mapstructure:"alert_poll_jitter"`
+	TLSCertFile       string        `mapstructure:"tls_cert_file"`
+	TLSKeyFile        string        `mapstructure:"tls_key_file"`
+	TLSClientCAFile   string        `mapstructure:"tls_client_ca_file"`
+	AdminToken        string        `mapstructure:"admin_token"`
+	ServeMode         string        `mapstructure:"serve_mode"`
+	DefaultLanguage   string        `mapstructure:"default_language"`
+	// MethodTimeouts maps lower-cased RPC method names to their deadline, e.g. getrates=10s
+	MethodTimeouts          map[string]time.Duration `mapstructure:"method_timeouts"`
+	DefaultMethodTimeout    time.Duration            `mapstructure:"default_method_timeout"`
+	AllowedMarkets          []string                 `mapstructure:"allowed_markets"`
+	HeartbeatInterval       time.Duration            `mapstructure:"heartbeat_interval"`
+	HealthFailureThreshold  int                      `mapstructure:"health_failure_threshold"`
+	HealthRecoveryThreshold int                      `mapstructure:"health_recovery_threshold"`
+	HealthWeightDatabase    int                      `mapstructure:"health_weight_database"`
+	HealthWeightGrinex      int                      `mapstructure:"health_weight_grinex"`
+	HealthWeightFreshness   int                      `mapstructure:"health_weight_freshness"`
+	HealthMaxLatency        time.Duration            `mapstructure:"health_max_latency"`
+	HealthMaxRateAge        time.Duration            `mapstructure:"health_max_rate_age"`
+	FailOnPersistError      bool                     `mapstructure:"fail_on_persist_error"`
 }
 
 type DatabaseConfig struct {
-	Host     string `mapstructure:"host"`
-	Port     int    `mapstructure:"port"`
-	User     string `mapstructure:"user"`
-	Password string `mapstructure:"password"`
-	DBName   string `mapstructure:"dbname"`
-	SSLMode  string `mapstructure:"sslmode"`
+	Host              string        `mapstructure:"host"`
+	Port              int           `mapstructure:"port"`
+	User              string        `mapstructure:"user"`
+	Password          string        `mapstructure:"password"`
+	DBName            string        `mapstructure:"dbname"`
+	SSLMode           string        `mapstructure:"sslmode"`
+	MaxQueryRange     time.Duration `mapstructure:"max_query_range"`
+	PersistRates      bool          `mapstructure:"persist_rates"`
+	PersistRateLevels bool          `mapstructure:"persist_rate_levels"`
+	PersistHeartbeats bool          `mapstructure:"persist_heartbeats"`
+	MaxRateLevels     int           `mapstructure:"max_rate_levels"`
+	PrepareStatements bool          `mapstructure:"prepare_statements"`
+	MigrationLockKey  int64         `mapstructure:"migration_lock_key"`
+	Schema            string        `mapstructure:"schema"`
+	TablePrefix       string        `mapstructure:"table_prefix"`
+	MaxOpenConns      int           `mapstructure:"max_open_conns"`
+	MaxIdleConns      int           `mapstructure:"max_idle_conns"`
+	ConnMaxLifetime   time.Duration `mapstructure:"conn_max_lifetime"`
+	ConnMaxIdleTime   time.Duration `mapstructure:"conn_max_idle_time"`
+	RateRetention     time.Duration `mapstructure:"rate_retention"`
 }
 
 type GrinexConfig struct {
-	BaseURL   string        `mapstructure:"base_url"`
-	Timeout   time.Duration `mapstructure:"timeout"`
-	UserAgent string        `mapstructure:"user_agent"`
+	BaseURL               string            `mapstructure:"base_url"`
+	Timeout               time.Duration     `mapstructure:"timeout"`
+	UserAgent             string            `mapstructure:"user_agent"`
+	PairLabels            map[string]string `mapstructure:"pair_labels"`
+	PairLabelsFile        string            `mapstructure:"pair_labels_file"`
+	PriceStrategy         string            `mapstructure:"price_strategy"`
+	TrimFraction          float64           `mapstructure:"trim_fraction"`
+	RateSource            string            `mapstructure:"rate_source"`
+	RateCacheTTL          time.Duration     `mapstructure:"rate_cache_ttl"`
+	HedgeDelay            time.Duration     `mapstructure:"hedge_delay"`
+	OnFailure             string            `mapstructure:"on_failure"`
+	LastKnownMaxAge       time.Duration     `mapstructure:"last_known_max_age"`
+	TimestampTrades       int               `mapstructure:"timestamp_trades"`
+	BaseURLOverrideHosts  []string          `mapstructure:"base_url_override_hosts"`
+	TradesLimit           int               `mapstructure:"trades_limit"`
+	MaxTradesLimit        int               `mapstructure:"max_trades_limit"`
+	MarketMaxTradesLimits map[string]int    `mapstructure:"market_max_trades_limits"`
+	HealthMaxTradeAge     time.Duration     `mapstructure:"health_max_trade_age"`
+	HTTPVersion           string            `mapstructure:"http_version"`
+	PriceDecimals         map[string]int    `mapstructure:"price_decimals"`
+	BodyReadTimeout       time.Duration     `mapstructure:"body_read_timeout"`
+	CheckContentType      bool              `mapstructure:"check_content_type"`
+	MinTrades             int               `mapstructure:"min_trades"`
+	DepthLimit            int               `mapstructure:"depth_limit"`
+	DepthCacheTTL         time.Duration     `mapstructure:"depth_cache_ttl"`
+	ConnectTimeout        time.Duration     `mapstructure:"connect_timeout"`
+	TLSHandshakeTimeout   time.Duration     `mapstructure:"tls_handshake_timeout"`
+	FeeBps                map[string]int    `mapstructure:"fee_bps"`
+	TimestampBucket       time.Duration     `mapstructure:"timestamp_bucket"`
+	KeepRawTimestamp      bool              `mapstructure:"keep_raw_timestamp"`
 }
 
 type LoggingConfig struct {
 	Level string `mapstructure:"level"`
 }
 
-// Load loads configuration from environment variables and command line flags
-func Load() *Config {
+type MetricsConfig struct {
+	Backend        string        `mapstructure:"backend"`
+	OTLPEndpoint   string        `mapstructure:"otlp_endpoint"`
+	OTLPInsecure   bool          `mapstructure:"otlp_insecure"`
+	ExportInterval time.Duration `mapstructure:"export_interval"`
+	Port           string        `mapstructure:"port"`
+	DropLabels     []string      `mapstructure:"drop_labels"`
+}
+
+type PollerConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Interval time.Duration `mapstructure:"interval"`
+	Pairs    []string      `mapstructure:"pairs"`
+}
+
+// ErrConfigFileNotFound is returned along with the configuration when the requested config file
+// does not exist. The configuration is then loaded from defaults, environment variables and flags.
+var ErrConfigFileNotFound = errors.New("config file not found")
+
+// Load loads configuration from the config file, environment variables and command line flags
+func Load() (*Config, error) {
+	args := os.Args[1:]
+	if flag.Parsed() {
+		// The global flag set was already parsed by someone else (e.g. the testing
+		// package), so the process arguments are not meant for us
+		args = nil
+	}
+	return LoadArgs(args)
+}
+
+// LoadArgs loads configuration from the given command line arguments, environment variables and
+// an optional YAML or JSON config file named by --config or CONFIG_FILE, in that order of
+// precedence, falling back to the defaults. It uses its own flag set, so it can be called
+// repeatedly. A missing config file returns the configuration without it together with an error
+// wrapping ErrConfigFileNotFound; a config file that cannot be parsed fails the load.
+func LoadArgs(args []string) (*Config, error) {
+	return load(parseFlags(args))
+}
+
+// Flags holds the configuration flags registered on a subcommand's flag set
+type Flags struct {
+	line commandLine
+}
+
+// RegisterFlags registers the configuration flags (--config, --db-host, ...) on the flag set of a
+// subcommand, so they can be given next to its own flags. Once the set is parsed, Load loads the
+// configuration from them as LoadArgs does.
+func RegisterFlags(flags *flag.FlagSet) *Flags {
+	f := &Flags{}
+	f.line.register(flags)
+	return f
+}
 
-	setDefaults()
+// Load loads the configuration overridden by the parsed flags, see LoadArgs
+func (f *Flags) Load() (*Config, error) {
+	return load(f.line)
+}
 
-	loadFromEnv()
+// load loads the configuration overridden by the given command line flags
+func load(flags commandLine) (*Config, error) {
+	v := viper.New()
+	setDefaults(v)
 
-	loadFromFlags()
+	configFile := flags.configFile
+	if configFile == "" {
+		configFile = os.Getenv("CONFIG_FILE")
+	}
+
+	var fileErr error
+	if configFile != "" {
+		v.SetConfigFile(configFile)
+		if err := v.ReadInConfig(); err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				return nil, fmt.Errorf("failed to read config file %s: %w", configFile, err)
+			}
+			fileErr = fmt.Errorf("%w: %s", ErrConfigFileNotFound, configFile)
+		}
+	}
+
+	// base holds the defaults overridden by the config file, which environment variables and then
+	// flags override in turn
+	base := &Config{}
+	if err := v.Unmarshal(base); err != nil {
+		return nil, fmt.Errorf("failed to decode config file %s: %w", configFile, err)
+	}
 
 	cfg := &Config{
 		Server: ServerConfig{
-			Port: getString("SERVER_PORT", "8080"),
+			Port:                    getString("SERVER_PORT", base.Server.Port),
+			RequiredMetadata:        getStringSlice("REQUIRED_METADATA", base.Server.RequiredMetadata),
+			AlertPollInterval:       getDuration("ALERT_POLL_INTERVAL", base.Server.AlertPollInterval),
+			AlertDebounce:           getDuration("ALERT_DEBOUNCE", base.Server.AlertDebounce),
+			AlertPollJitter:         getInt("ALERT_POLL_JITTER", base.Server.AlertPollJitter),
+			TLSCertFile:             getString("TLS_CERT_FILE", base.Server.TLSCertFile),
+			TLSKeyFile:              getString("TLS_KEY_FILE", base.Server.TLSKeyFile),
+			TLSClientCAFile:         getString("TLS_CLIENT_CA_FILE", base.Server.TLSClientCAFile),
+			AdminToken:              getString("ADMIN_TOKEN", base.Server.AdminToken),
+			ServeMode:               getString("SERVE_MODE", base.Server.ServeMode),
+			DefaultLanguage:         getString("DEFAULT_LANGUAGE", base.Server.DefaultLanguage),
+			MethodTimeouts:          getDurationMap("METHOD_TIMEOUTS", base.Server.MethodTimeouts),
+			DefaultMethodTimeout:    getDuration("DEFAULT_METHOD_TIMEOUT", base.Server.DefaultMethodTimeout),
+			AllowedMarkets:          getStringSlice("ALLOWED_MARKETS", base.Server.AllowedMarkets),
+			HeartbeatInterval:       getDuration("HEARTBEAT_INTERVAL", base.Server.HeartbeatInterval),
+			HealthFailureThreshold:  getInt("HEALTH_FAILURE_THRESHOLD", base.Server.HealthFailureThreshold),
+			HealthRecoveryThreshold: getInt("HEALTH_RECOVERY_THRESHOLD", base.Server.HealthRecoveryThreshold),
+			HealthWeightDatabase:    getInt("HEALTH_WEIGHT_DATABASE", base.Server.HealthWeightDatabase),
+			HealthWeightGrinex:      getInt("HEALTH_WEIGHT_GRINEX", base.Server.HealthWeightGrinex),
+			HealthWeightFreshness:   getInt("HEALTH_WEIGHT_FRESHNESS", base.Server.HealthWeightFreshness),
+			HealthMaxLatency:        getDuration("HEALTH_MAX_LATENCY", base.Server.HealthMaxLatency),
+			HealthMaxRateAge:        getDuration("HEALTH_MAX_RATE_AGE", base.Server.HealthMaxRateAge),
+			FailOnPersistError:      getBool("FAIL_ON_PERSIST_ERROR", base.Server.FailOnPersistError),
 		},
 		Database: DatabaseConfig{
-			Host:     getString("DB_HOST", "localhost"),
-			Port:     getInt("DB_PORT", 5460),
-			User:     getString("DB_USER", "db_admin"),
-			Password: getString("DB_PASSWORD", "3Qv@e8U0ImT"),
-			DBName:   getString("DB_NAME", "grinex_rates"),
-			SSLMode:  getString("DB_SSLMODE", "disable"),
+			Host:              getString("DB_HOST", base.Database.Host),
+			Port:              getInt("DB_PORT", base.Database.Port),
+			User:              getString("DB_USER", base.Database.User),
+			Password:          getString("DB_PASSWORD", base.Database.Password),
+			DBName:            getString("DB_NAME", base.Database.DBName),
+			SSLMode:           getString("DB_SSLMODE", base.Database.SSLMode),
+			MaxQueryRange:     getDuration("MAX_QUERY_RANGE", base.Database.MaxQueryRange),
+			PersistRates:      getBool("DB_PERSIST_RATES", base.Database.PersistRates),
+			PersistRateLevels: getBool("DB_PERSIST_RATE_LEVELS", base.Database.PersistRateLevels),
+			PersistHeartbeats: getBool("DB_PERSIST_HEARTBEATS", base.Database.PersistHeartbeats),
+			MaxRateLevels:     getInt("DB_MAX_RATE_LEVELS", base.Database.MaxRateLevels),
+			PrepareStatements: getBool("DB_PREPARE_STATEMENTS", base.Database.PrepareStatements),
+			MigrationLockKey:  int64(getInt("DB_MIGRATION_LOCK_KEY", int(base.Database.MigrationLockKey))),
+			Schema:            getString("DB_SCHEMA", base.Database.Schema),
+			TablePrefix:       getString("DB_TABLE_PREFIX", base.Database.TablePrefix),
+			MaxOpenConns:      getInt("DB_MAX_OPEN_CONNS", base.Database.MaxOpenConns),
+			MaxIdleConns:      getInt("DB_MAX_IDLE_CONNS", base.Database.MaxIdleConns),
+			ConnMaxLifetime:   getDuration("DB_CONN_MAX_LIFETIME", base.Database.ConnMaxLifetime),
+			ConnMaxIdleTime:   getDuration("DB_CONN_MAX_IDLE_TIME", base.Database.ConnMaxIdleTime),
+			RateRetention:     getDuration("RATE_RETENTION", base.Database.RateRetention),
 		},
 		Grinex: GrinexConfig{
-			BaseURL:   getString("GRINEX_BASE_URL", "https://grinex.io"),
-			Timeout:   getDuration("GRINEX_TIMEOUT", 30*time.Second),
-			UserAgent: getString("GRINEX_USER_AGENT", "GrinexRateService/1.0"),
+			BaseURL:               getString("GRINEX_BASE_URL", base.Grinex.BaseURL),
+			Timeout:               getDuration("GRINEX_TIMEOUT", base.Grinex.Timeout),
+			UserAgent:             getString("GRINEX_USER_AGENT", base.Grinex.UserAgent),
+			PairLabels:            getStringMap("GRINEX_PAIR_LABELS", base.Grinex.PairLabels),
+			PairLabelsFile:        getString("GRINEX_PAIR_LABELS_FILE", base.Grinex.PairLabelsFile),
+			PriceStrategy:         getString("GRINEX_PRICE_STRATEGY", base.Grinex.PriceStrategy),
+			TrimFraction:          getFloat("GRINEX_TRIM_FRACTION", base.Grinex.TrimFraction),
+			RateSource:            getString("GRINEX_RATE_SOURCE", base.Grinex.RateSource),
+			RateCacheTTL:          getDuration("GRINEX_RATE_CACHE_TTL", base.Grinex.RateCacheTTL),
+			HedgeDelay:            getDuration("GRINEX_HEDGE_DELAY", base.Grinex.HedgeDelay),
+			OnFailure:             getString("GRINEX_ON_FAILURE", base.Grinex.OnFailure),
+			LastKnownMaxAge:       getDuration("GRINEX_LAST_KNOWN_MAX_AGE", base.Grinex.LastKnownMaxAge),
+			TimestampTrades:       getInt("GRINEX_TIMESTAMP_TRADES", base.Grinex.TimestampTrades),
+			BaseURLOverrideHosts:  getStringSlice("GRINEX_BASE_URL_OVERRIDE_HOSTS", base.Grinex.BaseURLOverrideHosts),
+			TradesLimit:           getInt("GRINEX_TRADES_LIMIT", base.Grinex.TradesLimit),
+			MaxTradesLimit:        getInt("GRINEX_MAX_TRADES_LIMIT", base.Grinex.MaxTradesLimit),
+			MarketMaxTradesLimits: getIntMap("GRINEX_MARKET_MAX_TRADES_LIMITS", base.Grinex.MarketMaxTradesLimits),
+			HealthMaxTradeAge:     getDuration("GRINEX_HEALTH_MAX_TRADE_AGE", base.Grinex.HealthMaxTradeAge),
+			HTTPVersion:           getString("GRINEX_HTTP_VERSION", base.Grinex.HTTPVersion),
+			PriceDecimals:         getIntMap("GRINEX_PRICE_DECIMALS", base.Grinex.PriceDecimals),
+			BodyReadTimeout:       getDuration("GRINEX_BODY_READ_TIMEOUT", base.Grinex.BodyReadTimeout),
+			CheckContentType:      getBool("GRINEX_CHECK_CONTENT_TYPE", base.Grinex.CheckContentType),
+			MinTrades:             getInt("GRINEX_MIN_TRADES", base.Grinex.MinTrades),
+			DepthLimit:            getInt("GRINEX_DEPTH_LIMIT", base.Grinex.DepthLimit),
+			DepthCacheTTL:         getDuration("GRINEX_DEPTH_CACHE_TTL", base.Grinex.DepthCacheTTL),
+			ConnectTimeout:        getDuration("GRINEX_CONNECT_TIMEOUT", base.Grinex.ConnectTimeout),
+			TLSHandshakeTimeout:   getDuration("GRINEX_TLS_HANDSHAKE_TIMEOUT", base.Grinex.TLSHandshakeTimeout),
+			FeeBps:                getIntMap("GRINEX_FEE_BPS", base.Grinex.FeeBps),
+			TimestampBucket:       getDuration("TIMESTAMP_BUCKET", base.Grinex.TimestampBucket),
+			KeepRawTimestamp:      getBool("TIMESTAMP_KEEP_RAW", base.Grinex.KeepRawTimestamp),
 		},
 		Logging: LoggingConfig{
-			Level: getString("LOG_LEVEL", "info"),
+			Level: getString("LOG_LEVEL", base.Logging.Level),
+		},
+		Metrics: MetricsConfig{
+			Backend:        getString("METRICS_BACKEND", base.Metrics.Backend),
+			OTLPEndpoint:   getString("METRICS_OTLP_ENDPOINT", base.Metrics.OTLPEndpoint),
+			OTLPInsecure:   getBool("METRICS_OTLP_INSECURE", base.Metrics.OTLPInsecure),
+			ExportInterval: getDuration("METRICS_EXPORT_INTERVAL", base.Metrics.ExportInterval),
+			Port:           getString("METRICS_PORT", base.Metrics.Port),
+			DropLabels:     getStringSlice("METRICS_DROP_LABELS", base.Metrics.DropLabels),
+		},
+		Poller: PollerConfig{
+			Enabled:  getBool("POLL_ENABLED", base.Poller.Enabled),
+			Interval: getDuration("POLL_INTERVAL", base.Poller.Interval),
+			Pairs:    getStringSlice("POLL_PAIRS", base.Poller.Pairs),
 		},
 	}
+	flags.apply(cfg)
 
-	return cfg
+	return cfg, fileErr
 }
 
 // GetDSN returns the PostgreSQL connection string
@@ -81,68 +326,178 @@ func (c *DatabaseConfig) GetDSN() string {
 		c.Host, c.Port, c.User, c.Password, c.DBName, c.SSLMode)
 }
 
-func setDefaults() {
-	viper.SetDefault("server.port", "8080")
-	viper.SetDefault("database.host", "localhost")
-	viper.SetDefault("database.port", 5432)
-	viper.SetDefault("database.user", "postgres")
-	viper.SetDefault("database.password", "password")
-	viper.SetDefault("database.dbname", "grinex_rates")
-	viper.SetDefault("database.sslmode", "disable")
-	viper.SetDefault("grinex.base_url", "https://grinex.io")
-	viper.SetDefault("grinex.timeout", "30s")
-	viper.SetDefault("grinex.user_agent", "GrinexRateService/1.0")
-	viper.SetDefault("logging.level", "info")
+// setDefaults registers the default of every setting, the values used when neither the config
+// file, the environment nor a flag sets them
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("server.port", "8080")
+	v.SetDefault("server.alert_poll_interval", "5s")
+	v.SetDefault("server.alert_debounce", "1m")
+	v.SetDefault("server.alert_poll_jitter", 0)
+	v.SetDefault("server.tls_cert_file", "")
+	v.SetDefault("server.tls_key_file", "")
+	v.SetDefault("server.tls_client_ca_file", "")
+	v.SetDefault("server.admin_token", "")
+	v.SetDefault("server.serve_mode", ServeModeLive)
+	v.SetDefault("server.default_language", "en")
+	v.SetDefault("server.default_method_timeout", "30s")
+	v.SetDefault("server.heartbeat_interval", "0s")
+	v.SetDefault("server.health_failure_threshold", 1)
+	v.SetDefault("server.health_recovery_threshold", 1)
+	v.SetDefault("server.health_weight_database", 40)
+	v.SetDefault("server.health_weight_grinex", 30)
+	v.SetDefault("server.health_weight_freshness", 30)
+	v.SetDefault("server.health_max_latency", "1s")
+	v.SetDefault("server.health_max_rate_age", "5m")
+	v.SetDefault("server.fail_on_persist_error", true)
+	v.SetDefault("database.host", "localhost")
+	v.SetDefault("database.port", 5460)
+	v.SetDefault("database.user", "db_admin")
+	v.SetDefault("database.password", "3Qv@e8U0ImT")
+	v.SetDefault("database.dbname", "grinex_rates")
+	v.SetDefault("database.sslmode", "disable")
+	v.SetDefault("database.max_query_range", "720h")
+	v.SetDefault("database.persist_rates", true)
+	v.SetDefault("database.persist_rate_levels", false)
+	v.SetDefault("database.persist_heartbeats", true)
+	v.SetDefault("database.max_rate_levels", 10)
+	v.SetDefault("database.prepare_statements", true)
+	v.SetDefault("database.migration_lock_key", 7306142)
+	v.SetDefault("database.schema", "public")
+	v.SetDefault("database.table_prefix", "")
+	v.SetDefault("database.max_open_conns", 20)
+	v.SetDefault("database.max_idle_conns", 10)
+	v.SetDefault("database.conn_max_lifetime", "30m")
+	v.SetDefault("database.conn_max_idle_time", "5m")
+	v.SetDefault("database.rate_retention", "0s")
+	v.SetDefault("grinex.base_url", "https://grinex.io")
+	v.SetDefault("grinex.timeout", "30s")
+	v.SetDefault("grinex.user_agent", "GrinexRateService/1.0")
+	v.SetDefault("grinex.pair_labels_file", "")
+	v.SetDefault("grinex.price_strategy", "extremes")
+	v.SetDefault("grinex.trim_fraction", 0.1)
+	v.SetDefault("grinex.rate_source", "trades")
+	v.SetDefault("grinex.rate_cache_ttl", "2s")
+	v.SetDefault("grinex.hedge_delay", "0s")
+	v.SetDefault("grinex.on_failure", OnFailureError)
+	v.SetDefault("grinex.last_known_max_age", "5m")
+	v.SetDefault("grinex.timestamp_trades", 1)
+	v.SetDefault("grinex.base_url_override_hosts", []string{})
+	v.SetDefault("grinex.trades_limit", 100)
+	v.SetDefault("grinex.max_trades_limit", 5000)
+	v.SetDefault("grinex.health_max_trade_age", "0s")
+	v.SetDefault("grinex.http_version", "auto")
+	v.SetDefault("grinex.body_read_timeout", "10s")
+	v.SetDefault("grinex.check_content_type", true)
+	v.SetDefault("grinex.min_trades", 1)
+	v.SetDefault("grinex.depth_limit", 20)
+	v.SetDefault("grinex.depth_cache_ttl", "1s")
+	v.SetDefault("grinex.connect_timeout", "5s")
+	v.SetDefault("grinex.tls_handshake_timeout", "10s")
+	v.SetDefault("grinex.timestamp_bucket", "0s")
+	v.SetDefault("grinex.keep_raw_timestamp", false)
+	v.SetDefault("logging.level", "info")
+	v.SetDefault("metrics.backend", MetricsBackendPrometheus)
+	v.SetDefault("metrics.otlp_endpoint", "localhost:4317")
+	v.SetDefault("metrics.otlp_insecure", false)
+	v.SetDefault("metrics.export_interval", "60s")
+	v.SetDefault("metrics.port", "9090")
+	v.SetDefault("metrics.drop_labels", []string{})
+	v.SetDefault("poller.enabled", false)
+	v.SetDefault("poller.interval", "30s")
+	v.SetDefault("poller.pairs", []string{"usdtrub"})
 }
 
-func loadFromEnv() {
-	viper.SetEnvPrefix("")
-	viper.AutomaticEnv()
+// commandLine holds the parsed command line flags, zero values meaning the flag was not given
+type commandLine struct {
+	configFile    string
+	port          string
+	dbHost        string
+	dbPort        int
+	dbUser        string
+	dbPassword    string
+	dbName        string
+	dbSSLMode     string
+	dbMaxOpen     int
+	dbMaxIdle     int
+	dbMaxLifetime time.Duration
+	dbMaxIdleTime time.Duration
+	grinexBaseURL string
+	grinexTimeout time.Duration
+	logLevel      string
 }
 
-func loadFromFlags() {
-	port := flag.String("port", "", "Server port")
-	dbHost := flag.String("db-host", "", "Database host")
-	dbPort := flag.Int("db-port", 0, "Database port")
-	dbUser := flag.String("db-user", "", "Database user")
-	dbPassword := flag.String("db-password", "", "Database password")
-	dbName := flag.String("db-name", "", "Database name")
-	dbSSLMode := flag.String("db-sslmode", "", "Database SSL mode")
-	grinexBaseURL := flag.String("grinex-base-url", "", "Grinex API base URL")
-	grinexTimeout := flag.String("grinex-timeout", "", "Grinex API timeout")
-	logLevel := flag.String("log-level", "", "Log level")
+func parseFlags(args []string) commandLine {
+	flags := flag.NewFlagSet("grinex-rate-service", flag.ExitOnError)
+
+	var c commandLine
+	c.register(flags)
+	_ = flags.Parse(args) // ExitOnError never returns an error
 
-	flag.Parse()
+	return c
+}
+
+// register defines the configuration flags on a flag set, storing their values in c
+func (c *commandLine) register(flags *flag.FlagSet) {
+	flags.StringVar(&c.configFile, "config", "", "YAML or JSON config file")
+	flags.StringVar(&c.port, "port", "", "Server port")
+	flags.StringVar(&c.dbHost, "db-host", "", "Database host")
+	flags.IntVar(&c.dbPort, "db-port", 0, "Database port")
+	flags.StringVar(&c.dbUser, "db-user", "", "Database user")
+	flags.StringVar(&c.dbPassword, "db-password", "", "Database password")
+	flags.StringVar(&c.dbName, "db-name", "", "Database name")
+	flags.StringVar(&c.dbSSLMode, "db-sslmode", "", "Database SSL mode")
+	flags.IntVar(&c.dbMaxOpen, "db-max-open-conns", 0, "Maximum open database connections")
+	flags.IntVar(&c.dbMaxIdle, "db-max-idle-conns", 0, "Maximum idle database connections")
+	flags.DurationVar(&c.dbMaxLifetime, "db-conn-max-lifetime", 0, "Maximum lifetime of a database connection")
+	flags.DurationVar(&c.dbMaxIdleTime, "db-conn-max-idle-time", 0, "Maximum idle time of a database connection")
+	flags.StringVar(&c.grinexBaseURL, "grinex-base-url", "", "Grinex API base URL")
+	flags.DurationVar(&c.grinexTimeout, "grinex-timeout", 0, "Grinex API timeout")
+	flags.StringVar(&c.logLevel, "log-level", "", "Log level")
+}
 
-	if *port != "" {
-		viper.Set("server.port", *port)
+// apply overrides cfg with the flags that were given
+func (c commandLine) apply(cfg *Config) {
+	if c.port != "" {
+		cfg.Server.Port = c.port
+	}
+	if c.dbHost != "" {
+		cfg.Database.Host = c.dbHost
+	}
+	if c.dbPort != 0 {
+		cfg.Database.Port = c.dbPort
+	}
+	if c.dbUser != "" {
+		cfg.Database.User = c.dbUser
+	}
+	if c.dbPassword != "" {
+		cfg.Database.Password = c.dbPassword
 	}
-	if *dbHost != "" {
-		viper.Set("database.host", *dbHost)
+	if c.dbName != "" {
+		cfg.Database.DBName = c.dbName
 	}
-	if *dbPort != 0 {
-		viper.Set("database.port", *dbPort)
+	if c.dbSSLMode != "" {
+		cfg.Database.SSLMode = c.dbSSLMode
 	}
-	if *dbUser != "" {
-		viper.Set("database.user", *dbUser)
+	if c.dbMaxOpen != 0 {
+		cfg.Database.MaxOpenConns = c.dbMaxOpen
 	}
-	if *dbPassword != "" {
-		viper.Set("database.password", *dbPassword)
+	if c.dbMaxIdle != 0 {
+		cfg.Database.MaxIdleConns = c.dbMaxIdle
 	}
-	if *dbName != "" {
-		viper.Set("database.dbname", *dbName)
+	if c.dbMaxLifetime != 0 {
+		cfg.Database.ConnMaxLifetime = c.dbMaxLifetime
 	}
-	if *dbSSLMode != "" {
-		viper.Set("database.sslmode", *dbSSLMode)
+	if c.dbMaxIdleTime != 0 {
+		cfg.Database.ConnMaxIdleTime = c.dbMaxIdleTime
 	}
-	if *grinexBaseURL != "" {
-		viper.Set("grinex.base_url", *grinexBaseURL)
+	if c.grinexBaseURL != "" {
+		cfg.Grinex.BaseURL = c.grinexBaseURL
 	}
-	if *grinexTimeout != "" {
-		viper.Set("grinex.timeout", *grinexTimeout)
+	if c.grinexTimeout != 0 {
+		cfg.Grinex.Timeout = c.grinexTimeout
 	}
-	if *logLevel != "" {
-		viper.Set("logging.level", *logLevel)
+	if c.logLevel != "" {
+		cfg.Logging.Level = c.logLevel
 	}
 }
 
@@ -162,6 +517,24 @@ func getInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+func getBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
 func getDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
@@ -170,3 +543,76 @@ func getDuration(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+// getStringSlice parses a comma separated list, skipping empty entries
+func getStringSlice(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var result []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// getStringMap parses a comma separated list of key=value pairs, e.g. "usdtrub=USDT/RUB,btcrub=BTC/RUB".
+// Keys are lower-cased; malformed entries are skipped.
+func getStringMap(key string, defaultValue map[string]string) map[string]string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	result := make(map[string]string)
+	for _, entry := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(entry, "=")
+		k, v = strings.ToLower(strings.TrimSpace(k)), strings.TrimSpace(v)
+		if !ok || k == "" || v == "" {
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// getIntMap parses a comma separated list of key=integer pairs, e.g. "usdtrub=2000,btcrub=500".
+// Keys are lower-cased; malformed entries are skipped.
+func getIntMap(key string, defaultValue map[string]int) map[string]int {
+	values := getStringMap(key, nil)
+	if values == nil {
+		return defaultValue
+	}
+
+	result := make(map[string]int, len(values))
+	for k, v := range values {
+		intValue, err := strconv.Atoi(v)
+		if err != nil {
+			continue
+		}
+		result[k] = intValue
+	}
+	return result
+}
+
+// getDurationMap parses key=duration pairs, skipping entries whose value is not a valid duration
+func getDurationMap(key string, defaultValue map[string]time.Duration) map[string]time.Duration {
+	values := getStringMap(key, nil)
+	if values == nil {
+		return defaultValue
+	}
+
+	result := make(map[string]time.Duration, len(values))
+	for k, v := range values {
+		duration, err := time.ParseDuration(v)
+		if err != nil {
+			continue
+		}
+		result[k] = duration
+	}
+	return result
+}
@@ -1,11 +1,14 @@
 package config
 
 import (
+	"flag"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestLoadWithEnvVars(t *testing.T) {
@@ -21,6 +24,7 @@ func TestLoadWithEnvVars(t *testing.T) {
 	os.Setenv("GRINEX_TIMEOUT", "60s")
 	os.Setenv("GRINEX_USER_AGENT", "TestAgent/1.0")
 	os.Setenv("LOG_LEVEL", "debug")
+	os.Setenv("MAX_QUERY_RANGE", "168h")
 
 	// Clear environment after test
 	defer func() {
@@ -35,9 +39,11 @@ func TestLoadWithEnvVars(t *testing.T) {
 		os.Unsetenv("GRINEX_TIMEOUT")
 		os.Unsetenv("GRINEX_USER_AGENT")
 		os.Unsetenv("LOG_LEVEL")
+		os.Unsetenv("MAX_QUERY_RANGE")
 	}()
 
-	cfg := Load()
+	cfg, err := Load()
+	require.NoError(t, err)
 
 	assert.Equal(t, "9090", cfg.Server.Port)
 	assert.Equal(t, "test-host", cfg.Database.Host)
@@ -50,6 +56,7 @@ func TestLoadWithEnvVars(t *testing.T) {
 	assert.Equal(t, 60*time.Second, cfg.Grinex.Timeout)
 	assert.Equal(t, "TestAgent/1.0", cfg.Grinex.UserAgent)
 	assert.Equal(t, "debug", cfg.Logging.Level)
+	assert.Equal(t, 168*time.Hour, cfg.Database.MaxQueryRange)
 }
 
 func TestGetDSN(t *testing.T) {
@@ -68,3 +75,229 @@ func TestGetDSN(t *testing.T) {
 	expected := "host=localhost port=5432 user=postgres password=password dbname=testdb sslmode=disable"
 	assert.Equal(t, expected, dsn)
 }
+
+func TestGetStringMap(t *testing.T) {
+	os.Setenv("GRINEX_PAIR_LABELS", "USDTRUB=USDT/RUB, btcrub = BTC/RUB,invalid,=empty")
+	defer os.Unsetenv("GRINEX_PAIR_LABELS")
+
+	labels := getStringMap("GRINEX_PAIR_LABELS", nil)
+
+	assert.Equal(t, map[string]string{"usdtrub": "USDT/RUB", "btcrub": "BTC/RUB"}, labels)
+}
+
+func TestGetIntMap(t *testing.T) {
+	os.Setenv("GRINEX_MARKET_MAX_TRADES_LIMITS", "USDTRUB=2000, btcrub = 500,invalid,ethrub=many")
+	defer os.Unsetenv("GRINEX_MARKET_MAX_TRADES_LIMITS")
+
+	limits := getIntMap("GRINEX_MARKET_MAX_TRADES_LIMITS", nil)
+
+	assert.Equal(t, map[string]int{"usdtrub": 2000, "btcrub": 500}, limits)
+}
+
+func TestGetDurationMap(t *testing.T) {
+	os.Setenv("METHOD_TIMEOUTS", "GetRates=10s, GetTWAP = 500ms,invalid,FindGaps=soon")
+	defer os.Unsetenv("METHOD_TIMEOUTS")
+
+	timeouts := getDurationMap("METHOD_TIMEOUTS", nil)
+
+	assert.Equal(t, map[string]time.Duration{"getrates": 10 * time.Second, "gettwap": 500 * time.Millisecond}, timeouts)
+}
+
+func TestLoadTwice(t *testing.T) {
+	assert.NotPanics(t, func() {
+		Load()
+		Load()
+	})
+}
+
+func TestLoadArgs(t *testing.T) {
+	assert.NotPanics(t, func() {
+		LoadArgs([]string{"--port=9090", "--log-level=debug"})
+		LoadArgs([]string{"--port=9091"})
+	})
+}
+
+func TestRegisterFlags(t *testing.T) {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	pair := flags.String("pair", "", "Trading pair")
+	configFlags := RegisterFlags(flags)
+
+	require.NoError(t, flags.Parse([]string{"--pair", "btcrub", "--db-host", "replica", "--db-port=6432"}))
+	cfg, err := configFlags.Load()
+
+	require.NoError(t, err)
+	assert.Equal(t, "btcrub", *pair)
+	assert.Equal(t, "replica", cfg.Database.Host)
+	assert.Equal(t, 6432, cfg.Database.Port)
+}
+
+// writeConfigFile writes content to a file named name in a temporary directory and returns its path
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadArgs_ConfigFile(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+server:
+  port: "7070"
+  allowed_markets: [usdtrub, btcrub]
+  method_timeouts:
+    GetRates: 5s
+database:
+  host: db.internal
+  port: 6432
+  schema: rates_svc
+grinex:
+  timeout: 45s
+  trim_fraction: 0.2
+  fee_bps:
+    usdtrub: 25
+logging:
+  level: warn
+poller:
+  enabled: true
+  pairs: [usdtrub, btcrub]
+`)
+
+	cfg, err := LoadArgs([]string{"--config=" + path})
+
+	require.NoError(t, err)
+	assert.Equal(t, "7070", cfg.Server.Port)
+	assert.Equal(t, []string{"usdtrub", "btcrub"}, cfg.Server.AllowedMarkets)
+	assert.Equal(t, map[string]time.Duration{"getrates": 5 * time.Second}, cfg.Server.MethodTimeouts)
+	assert.Equal(t, "db.internal", cfg.Database.Host)
+	assert.Equal(t, 6432, cfg.Database.Port)
+	assert.Equal(t, "rates_svc", cfg.Database.Schema)
+	assert.Equal(t, 45*time.Second, cfg.Grinex.Timeout)
+	assert.Equal(t, 0.2, cfg.Grinex.TrimFraction)
+	assert.Equal(t, map[string]int{"usdtrub": 25}, cfg.Grinex.FeeBps)
+	assert.Equal(t, "warn", cfg.Logging.Level)
+	assert.True(t, cfg.Poller.Enabled)
+	assert.Equal(t, []string{"usdtrub", "btcrub"}, cfg.Poller.Pairs)
+	// Settings the file leaves out keep their defaults
+	assert.Equal(t, 30*time.Second, cfg.Poller.Interval)
+	assert.Equal(t, "grinex_rates", cfg.Database.DBName)
+	assert.Equal(t, "", cfg.Database.TablePrefix)
+	assert.Equal(t, 100, cfg.Grinex.TradesLimit)
+}
+
+func TestLoadArgs_ConfigFilePrecedence(t *testing.T) {
+	path := writeConfigFile(t, "config.json", `{
+		"server": {"port": "7070"},
+		"database": {"host": "file-host", "user": "file-user"},
+		"logging": {"level": "warn"}
+	}`)
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("DB_HOST", "env-host")
+	t.Setenv("SERVER_PORT", "7171")
+
+	cfg, err := LoadArgs([]string{"--port=7272"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "7272", cfg.Server.Port, "flags override the environment")
+	assert.Equal(t, "env-host", cfg.Database.Host, "the environment overrides the file")
+	assert.Equal(t, "file-user", cfg.Database.User, "the file overrides the defaults")
+	assert.Equal(t, "warn", cfg.Logging.Level)
+}
+
+func TestLoadArgs_PoolSettings(t *testing.T) {
+	t.Setenv("DB_MAX_IDLE_CONNS", "4")
+	t.Setenv("DB_CONN_MAX_IDLE_TIME", "1m")
+
+	cfg, err := LoadArgs([]string{"--db-max-open-conns=50", "--db-conn-max-lifetime=10m"})
+
+	require.NoError(t, err)
+	assert.Equal(t, 50, cfg.Database.MaxOpenConns)
+	assert.Equal(t, 4, cfg.Database.MaxIdleConns)
+	assert.Equal(t, 10*time.Minute, cfg.Database.ConnMaxLifetime)
+	assert.Equal(t, time.Minute, cfg.Database.ConnMaxIdleTime)
+}
+
+func TestLoadArgs_PersistenceToggles(t *testing.T) {
+	cfg, err := LoadArgs(nil)
+	require.NoError(t, err)
+	assert.True(t, cfg.Database.PersistRates)
+	assert.False(t, cfg.Database.PersistRateLevels)
+	assert.True(t, cfg.Database.PersistHeartbeats)
+
+	t.Setenv("DB_PERSIST_RATES", "false")
+	t.Setenv("DB_PERSIST_RATE_LEVELS", "true")
+
+	cfg, err = LoadArgs(nil)
+	require.NoError(t, err)
+	assert.False(t, cfg.Database.PersistRates)
+	assert.True(t, cfg.Database.PersistRateLevels)
+	assert.True(t, cfg.Database.PersistHeartbeats)
+}
+
+func TestLoadArgs_HealthScore(t *testing.T) {
+	cfg, err := LoadArgs(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 40, cfg.Server.HealthWeightDatabase)
+	assert.Equal(t, 30, cfg.Server.HealthWeightGrinex)
+	assert.Equal(t, 30, cfg.Server.HealthWeightFreshness)
+	assert.Equal(t, time.Second, cfg.Server.HealthMaxLatency)
+	assert.Equal(t, 5*time.Minute, cfg.Server.HealthMaxRateAge)
+
+	t.Setenv("HEALTH_WEIGHT_FRESHNESS", "0")
+	t.Setenv("HEALTH_MAX_LATENCY", "250ms")
+
+	cfg, err = LoadArgs(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, cfg.Server.HealthWeightFreshness)
+	assert.Equal(t, 250*time.Millisecond, cfg.Server.HealthMaxLatency)
+}
+
+func TestLoadArgs_FailOnPersistError(t *testing.T) {
+	cfg, err := LoadArgs(nil)
+	require.NoError(t, err)
+	assert.True(t, cfg.Server.FailOnPersistError)
+
+	t.Setenv("FAIL_ON_PERSIST_ERROR", "false")
+
+	cfg, err = LoadArgs(nil)
+	require.NoError(t, err)
+	assert.False(t, cfg.Server.FailOnPersistError)
+}
+
+func TestLoadArgs_CheckContentType(t *testing.T) {
+	cfg, err := LoadArgs(nil)
+	require.NoError(t, err)
+	assert.True(t, cfg.Grinex.CheckContentType)
+
+	t.Setenv("GRINEX_CHECK_CONTENT_TYPE", "false")
+
+	cfg, err = LoadArgs(nil)
+	require.NoError(t, err)
+	assert.False(t, cfg.Grinex.CheckContentType)
+}
+
+func TestLoadArgs_MissingConfigFile(t *testing.T) {
+	cfg, err := LoadArgs([]string{"--config=" + filepath.Join(t.TempDir(), "missing.yaml")})
+
+	assert.ErrorIs(t, err, ErrConfigFileNotFound)
+	require.NotNil(t, cfg)
+	assert.Equal(t, "8080", cfg.Server.Port)
+}
+
+func TestLoadArgs_MalformedConfigFile(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "server: [port: 7070\n")
+
+	cfg, err := LoadArgs([]string{"--config=" + path})
+
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrConfigFileNotFound)
+	assert.Nil(t, cfg)
+}
+
+func TestLoadArgs_InvalidConfigValue(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "grinex:\n  timeout: soon\n")
+
+	_, err := LoadArgs([]string{"--config=" + path})
+
+	assert.ErrorContains(t, err, "failed to decode config file")
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	_ "github.com/lib/pq"
@@ -18,42 +19,225 @@ type RateRecord struct {
 	BidPrice    float64
 	Timestamp   time.Time
 	CreatedAt   time.Time
+	// Strategy is the name of the price strategy that produced the rate
+	Strategy string
+	// RawTimestamp is the rate time before bucketing, zero when it was not kept
+	RawTimestamp time.Time
+	// Source is the kind of Grinex data the rate was computed from
+	Source string
+	// VWAP is the volume weighted average price of the trades behind the rate, zero when unknown
+	VWAP float64
 }
 
+const (
+	// DefaultStrategy is stored for rates saved without a strategy name, matching legacy rows
+	DefaultStrategy = "extremes"
+	// DefaultSource is stored for rates saved without a source, matching legacy rows
+	DefaultSource = "trades"
+)
+
+// Order book sides of a rate level
+const (
+	SideAsk = "ask"
+	SideBid = "bid"
+)
+
+// RateLevel is a single order book level stored alongside a rate record
+type RateLevel struct {
+	Side string
+	// Level is the 1-based position of the level on its side of the book
+	Level  int
+	Price  float64
+	Volume float64
+}
+
+var (
+	// ErrInvalidTimeRange is returned when a requested time range is inverted or too wide
+	ErrInvalidTimeRange = errors.New("invalid time range")
+	// ErrNoRates is returned when no stored rates match a query
+	ErrNoRates = errors.New("no rates found for trading pair")
+	// ErrInvalidRecord is returned when a rate record is rejected before it is stored
+	ErrInvalidRecord = errors.New("invalid rate record")
+)
+
+// Config holds configuration for the database
+type Config struct {
+	DSN string
+	// MaxQueryRange bounds the span of time range queries, zero means unlimited
+	MaxQueryRange time.Duration
+	// PersistRates enables storing rates, without it SaveRate is a no-op
+	PersistRates bool
+	// PersistRateLevels enables storing order book levels linked to rate records
+	PersistRateLevels bool
+	// PersistHeartbeats enables storing heartbeats, without it SaveHeartbeat is a no-op
+	PersistHeartbeats bool
+	// MaxRateLevels limits the stored levels per side, zero means unlimited
+	MaxRateLevels int
+	// PrepareStatements prepares the hot path statements once and reuses them
+	PrepareStatements bool
+	// Schema qualifies the tables, validated by NewTables
+	Schema string
+	// TablePrefix is prepended to the table names, empty for none
+	TablePrefix string
+	// Connection pool limits, zero keeping the database/sql default
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// The hot path queries, formatted with the name of the rates table
+const (
+	saveRateQuery = `
+		INSERT INTO %s (trading_pair, ask_price, bid_price, timestamp, created_at, strategy, raw_timestamp, source, vwap)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id`
+
+	latestRateQuery = `
+		SELECT id, trading_pair, ask_price, bid_price, timestamp, created_at, vwap
+		FROM %s
+		WHERE trading_pair = $1
+		ORDER BY created_at DESC
+		LIMIT 1`
+)
+
 type Database struct {
-	db     *sql.DB
-	logger *zap.Logger
+	db                *sql.DB
+	logger            *zap.Logger
+	maxQueryRange     time.Duration
+	persistRateLevels bool
+	maxRateLevels     int
+	tables            Tables
+	// skipRates and skipHeartbeats turn the writes of their category into no-ops, off in the
+	// zero value so a wrapped handle stores everything but rate levels
+	skipRates      bool
+	skipHeartbeats bool
+
+	// Prepared statements for the hot paths, nil when statement preparation is disabled.
+	// sql.Stmt is safe for concurrent use and transparently re-prepares itself on new connections.
+	saveRateStmt   *sql.Stmt
+	latestRateStmt *sql.Stmt
 }
 
-func NewDatabase(dsn string, logger *zap.Logger) (*Database, error) {
-	db, err := sql.Open("postgres", dsn)
+func NewDatabase(config *Config, logger *zap.Logger) (*Database, error) {
+	tables, err := NewTables(config.Schema, config.TablePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("postgres", config.DSN)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	configurePool(db, config)
 
 	if err := db.Ping(); err != nil {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
+	database := New(db, logger)
+	database.maxQueryRange = config.MaxQueryRange
+	database.persistRateLevels = config.PersistRateLevels
+	database.skipRates = !config.PersistRates
+	database.skipHeartbeats = !config.PersistHeartbeats
+	database.maxRateLevels = config.MaxRateLevels
+	database.tables = tables
+
+	if config.PrepareStatements {
+		if err := database.prepareStatements(); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	return database, nil
+}
+
+// configurePool applies the pool limits of config that are set. Unset limits are skipped rather
+// than applied as zero, which for idle connections would disable keeping any.
+func configurePool(db *sql.DB, config *Config) {
+	if config.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(config.MaxOpenConns)
+	}
+	if config.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(config.MaxIdleConns)
+	}
+	if config.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(config.ConnMaxLifetime)
+	}
+	if config.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+	}
+}
+
+// New wraps an already opened database handle, using unqualified and unprefixed table names
+func New(db *sql.DB, logger *zap.Logger) *Database {
 	return &Database{
 		db:     db,
 		logger: logger,
-	}, nil
+	}
 }
 
+func (d *Database) prepareStatements() error {
+	var err error
+
+	if d.saveRateStmt, err = d.db.Prepare(d.saveRateQuery()); err != nil {
+		return fmt.Errorf("failed to prepare save rate statement: %w", err)
+	}
+
+	if d.latestRateStmt, err = d.db.Prepare(d.latestRateQuery()); err != nil {
+		d.saveRateStmt.Close()
+		return fmt.Errorf("failed to prepare latest rate statement: %w", err)
+	}
+
+	return nil
+}
+
+func (d *Database) saveRateQuery() string {
+	return fmt.Sprintf(saveRateQuery, d.tables.Name(ratesTable))
+}
+
+func (d *Database) latestRateQuery() string {
+	return fmt.Sprintf(latestRateQuery, d.tables.Name(ratesTable))
+}
+
+// queryRow runs a prepared statement when available and falls back to the plain query otherwise
+func (d *Database) queryRow(stmt *sql.Stmt, query string, args ...interface{}) *sql.Row {
+	if stmt != nil {
+		return stmt.QueryRow(args...)
+	}
+	return d.db.QueryRow(query, args...)
+}
+
+// SaveRate stores a rate record and sets its ID. It is a no-op, leaving the ID zero, when rate
+// persistence is disabled.
 func (d *Database) SaveRate(record *RateRecord) error {
-	query := `
-		INSERT INTO rates (trading_pair, ask_price, bid_price, timestamp, created_at)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id`
+	if record.Timestamp.IsZero() {
+		return fmt.Errorf("%w: timestamp is zero for trading pair %s", ErrInvalidRecord, record.TradingPair)
+	}
+	if d.skipRates {
+		d.logger.Debug("Rate persistence is disabled, not saving rate", zap.String("trading_pair", record.TradingPair))
+		return nil
+	}
+	if record.Strategy == "" {
+		record.Strategy = DefaultStrategy
+	}
+	if record.Source == "" {
+		record.Source = DefaultSource
+	}
 
-	err := d.db.QueryRow(
-		query,
+	err := d.queryRow(
+		d.saveRateStmt,
+		d.saveRateQuery(),
 		record.TradingPair,
 		record.AskPrice,
 		record.BidPrice,
 		record.Timestamp,
 		record.CreatedAt,
+		record.Strategy,
+		sql.NullTime{Time: record.RawTimestamp, Valid: !record.RawTimestamp.IsZero()},
+		record.Source,
+		sql.NullFloat64{Float64: record.VWAP, Valid: record.VWAP != 0},
 	).Scan(&record.ID)
 
 	if err != nil {
@@ -65,47 +249,237 @@ func (d *Database) SaveRate(record *RateRecord) error {
 		zap.Float64("ask_price", record.AskPrice),
 		zap.Float64("bid_price", record.BidPrice),
 		zap.Time("timestamp", record.Timestamp),
+		zap.String("strategy", record.Strategy),
+		zap.String("source", record.Source),
 	)
 
 	return nil
 }
 
-func (d *Database) GetLatestRate(tradingPair string) (*RateRecord, error) {
-	query := `
-		SELECT id, trading_pair, ask_price, bid_price, timestamp, created_at
-		FROM rates
-		WHERE trading_pair = $1
-		ORDER BY created_at DESC
-		LIMIT 1`
+// SaveRateLevels stores the top order book levels of a rate record in a single transaction.
+// It is a no-op unless rate level persistence is enabled.
+func (d *Database) SaveRateLevels(rateID int64, levels []RateLevel) (err error) {
+	if !d.persistRateLevels || len(levels) == 0 {
+		return nil
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	stmt, err := tx.Prepare(fmt.Sprintf(`
+		INSERT INTO %s (rate_id, side, level, price, volume)
+		VALUES ($1, $2, $3, $4, $5)`, d.tables.Name(rateLevelsTable)))
+	if err != nil {
+		return fmt.Errorf("failed to prepare rate level insert: %w", err)
+	}
+	defer stmt.Close()
 
+	saved := 0
+	for _, level := range levels {
+		if d.maxRateLevels > 0 && level.Level > d.maxRateLevels {
+			continue
+		}
+		if _, err = stmt.Exec(rateID, level.Side, level.Level, level.Price, level.Volume); err != nil {
+			return fmt.Errorf("failed to save rate level: %w", err)
+		}
+		saved++
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit rate levels: %w", err)
+	}
+
+	d.logger.Debug("Rate levels saved to database",
+		zap.Int64("rate_id", rateID),
+		zap.Int("levels", saved),
+	)
+
+	return nil
+}
+
+func (d *Database) GetLatestRate(tradingPair string) (*RateRecord, error) {
 	record := &RateRecord{}
-	err := d.db.QueryRow(query, tradingPair).Scan(
+	var vwap sql.NullFloat64
+	err := d.queryRow(d.latestRateStmt, d.latestRateQuery(), tradingPair).Scan(
 		&record.ID,
 		&record.TradingPair,
 		&record.AskPrice,
 		&record.BidPrice,
 		&record.Timestamp,
 		&record.CreatedAt,
+		&vwap,
 	)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("no rate found for trading pair: %s", tradingPair)
+			return nil, fmt.Errorf("no rate found for trading pair: %s: %w", tradingPair, ErrNoRates)
 		}
 		return nil, fmt.Errorf("failed to get latest rate: %w", err)
 	}
+	record.VWAP = vwap.Float64
 
 	return record, nil
 }
 
-func (d *Database) GetRatesByTimeRange(tradingPair string, start, end time.Time) ([]*RateRecord, error) {
-	query := `
-		SELECT id, trading_pair, ask_price, bid_price, timestamp, created_at
-		FROM rates
-		WHERE trading_pair = $1 AND created_at BETWEEN $2 AND $3
+// LatestRateTime returns when the newest rate of any trading pair was stored, failing with
+// ErrNoRates when there are no rates
+func (d *Database) LatestRateTime(ctx context.Context) (time.Time, error) {
+	query := fmt.Sprintf(`SELECT MAX(created_at) FROM %s`, d.tables.Name(ratesTable))
+
+	var latest sql.NullTime
+	if err := d.db.QueryRowContext(ctx, query).Scan(&latest); err != nil {
+		return time.Time{}, fmt.Errorf("failed to get latest rate time: %w", err)
+	}
+	if !latest.Valid {
+		return time.Time{}, fmt.Errorf("no rates stored: %w", ErrNoRates)
+	}
+	return latest.Time, nil
+}
+
+// GetAllLatestRates returns the latest rate of every stored trading pair, sorted by pair, in a
+// single query. No stored rates return an empty list.
+func (d *Database) GetAllLatestRates(ctx context.Context) ([]*RateRecord, error) {
+	query := fmt.Sprintf(`
+		SELECT DISTINCT ON (trading_pair) id, trading_pair, ask_price, bid_price, timestamp, created_at, vwap
+		FROM %s
+		ORDER BY trading_pair, created_at DESC`, d.tables.Name(ratesTable))
+
+	rows, err := d.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query latest rates: %w", err)
+	}
+	defer rows.Close()
+
+	var records []*RateRecord
+	for rows.Next() {
+		record := &RateRecord{}
+		var vwap sql.NullFloat64
+		err := rows.Scan(
+			&record.ID,
+			&record.TradingPair,
+			&record.AskPrice,
+			&record.BidPrice,
+			&record.Timestamp,
+			&record.CreatedAt,
+			&vwap,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan rate record: %w", err)
+		}
+		record.VWAP = vwap.Float64
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return records, nil
+}
+
+// GetRatesByTimeRange returns the rates in the time range newest first, at most limit of them
+// when limit is positive. A non-empty source limits them to rates computed from that source.
+// Cancelling ctx stops reading rows and returns the context error.
+func (d *Database) GetRatesByTimeRange(ctx context.Context, tradingPair string, start, end time.Time, source string, limit int) ([]*RateRecord, error) {
+	var records []*RateRecord
+	err := d.streamRates(ctx, tradingPair, source, start, end, limit, func(record *RateRecord) error {
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// StreamRatesByTimeRange calls fn for each rate in the time range, newest first, without
+// buffering the whole result set. Iteration stops at the first error returned by fn.
+func (d *Database) StreamRatesByTimeRange(tradingPair string, start, end time.Time, fn func(*RateRecord) error) error {
+	return d.streamRates(context.Background(), tradingPair, "", start, end, 0, fn)
+}
+
+// streamRates implements StreamRatesByTimeRange with an optional source filter and row limit,
+// checking ctx between rows so a cancelled request does not keep scanning a large result set
+func (d *Database) streamRates(ctx context.Context, tradingPair, source string, start, end time.Time, limit int, fn func(*RateRecord) error) error {
+	if err := ValidateTimeRange(start, end, d.maxQueryRange); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, trading_pair, ask_price, bid_price, timestamp, created_at, source
+		FROM %s
+		WHERE trading_pair = $1 AND created_at BETWEEN $2 AND $3`, d.tables.Name(ratesTable))
+	args := []interface{}{tradingPair, start, end}
+	if source != "" {
+		query += ` AND source = $4`
+		args = append(args, source)
+	}
+	query += `
 		ORDER BY created_at DESC`
+	if limit > 0 {
+		args = append(args, limit)
+		query += fmt.Sprintf(`
+		LIMIT $%d`, len(args))
+	}
 
-	rows, err := d.db.Query(query, tradingPair, start, end)
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query rates: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		record := &RateRecord{}
+		err := rows.Scan(
+			&record.ID,
+			&record.TradingPair,
+			&record.AskPrice,
+			&record.BidPrice,
+			&record.Timestamp,
+			&record.CreatedAt,
+			&record.Source,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to scan rate record: %w", err)
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return nil
+}
+
+// GetRatesByStrategy returns the rates in the time range that were produced by the given
+// price strategy, newest first
+func (d *Database) GetRatesByStrategy(tradingPair, strategy string, start, end time.Time) ([]*RateRecord, error) {
+	if err := ValidateTimeRange(start, end, d.maxQueryRange); err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, trading_pair, ask_price, bid_price, timestamp, created_at, strategy
+		FROM %s
+		WHERE trading_pair = $1 AND strategy = $2 AND created_at BETWEEN $3 AND $4
+		ORDER BY created_at DESC`, d.tables.Name(ratesTable))
+
+	rows, err := d.db.Query(query, tradingPair, strategy, start, end)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query rates: %w", err)
 	}
@@ -121,6 +495,7 @@ func (d *Database) GetRatesByTimeRange(tradingPair string, start, end time.Time)
 			&record.BidPrice,
 			&record.Timestamp,
 			&record.CreatedAt,
+			&record.Strategy,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan rate record: %w", err)
@@ -135,6 +510,263 @@ func (d *Database) GetRatesByTimeRange(tradingPair string, start, end time.Time)
 	return records, nil
 }
 
+// GetVolatility returns the sample standard deviation of mid-prices stored within the given window
+func (d *Database) GetVolatility(ctx context.Context, tradingPair string, window time.Duration) (float64, error) {
+	if window <= 0 {
+		return 0, fmt.Errorf("%w: window must be positive", ErrInvalidTimeRange)
+	}
+	end := time.Now()
+	start := end.Add(-window)
+	if err := ValidateTimeRange(start, end, d.maxQueryRange); err != nil {
+		return 0, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT (ask_price + bid_price) / 2
+		FROM %s
+		WHERE trading_pair = $1 AND created_at >= $2`, d.tables.Name(ratesTable))
+
+	rows, err := d.db.QueryContext(ctx, query, tradingPair, start)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query mid prices: %w", err)
+	}
+	defer rows.Close()
+
+	var prices []float64
+	for rows.Next() {
+		var price float64
+		if err := rows.Scan(&price); err != nil {
+			return 0, fmt.Errorf("failed to scan mid price: %w", err)
+		}
+		prices = append(prices, price)
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	if len(prices) == 0 {
+		return 0, fmt.Errorf("%w: %s", ErrNoRates, tradingPair)
+	}
+
+	return sampleStdDev(prices), nil
+}
+
+// GetTWAP returns the time-weighted average mid-price of the rates stored within the time range.
+// Each rate is weighted by how long it was current: until the next stored rate, or until end for
+// the last one. A single rate, or rates that were never current for any time, yield their plain mean.
+func (d *Database) GetTWAP(ctx context.Context, tradingPair string, start, end time.Time) (float64, error) {
+	if err := ValidateTimeRange(start, end, d.maxQueryRange); err != nil {
+		return 0, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT (ask_price + bid_price) / 2, created_at
+		FROM %s
+		WHERE trading_pair = $1 AND created_at BETWEEN $2 AND $3
+		ORDER BY created_at ASC`, d.tables.Name(ratesTable))
+
+	rows, err := d.db.QueryContext(ctx, query, tradingPair, start, end)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query mid prices: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		prices []float64
+		times  []time.Time
+	)
+	for rows.Next() {
+		var (
+			price     float64
+			createdAt time.Time
+		)
+		if err := rows.Scan(&price, &createdAt); err != nil {
+			return 0, fmt.Errorf("failed to scan mid price: %w", err)
+		}
+		prices = append(prices, price)
+		times = append(times, createdAt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	if len(prices) == 0 {
+		return 0, fmt.Errorf("%w: %s", ErrNoRates, tradingPair)
+	}
+
+	return timeWeightedAverage(prices, times, end), nil
+}
+
+// Gap is a period between two consecutive stored rates of a pair with nothing stored in between
+type Gap struct {
+	// Start is the created_at of the rate before the gap
+	Start time.Time
+	// End is the created_at of the rate after the gap
+	End time.Time
+}
+
+// Duration returns the length of the gap
+func (g Gap) Duration() time.Duration {
+	return g.End.Sub(g.Start)
+}
+
+// FindGaps scans the rates of a pair stored within the time range oldest first and returns the
+// intervals where consecutive created_at values are more than maxGap apart. Only gaps between
+// stored rates are reported, not the time before the first or after the last one.
+func (d *Database) FindGaps(ctx context.Context, tradingPair string, start, end time.Time, maxGap time.Duration) ([]Gap, error) {
+	if maxGap <= 0 {
+		return nil, fmt.Errorf("%w: max gap must be positive", ErrInvalidTimeRange)
+	}
+	if err := ValidateTimeRange(start, end, d.maxQueryRange); err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT created_at
+		FROM %s
+		WHERE trading_pair = $1 AND created_at BETWEEN $2 AND $3
+		ORDER BY created_at ASC`, d.tables.Name(ratesTable))
+
+	rows, err := d.db.QueryContext(ctx, query, tradingPair, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rate times: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		gaps     []Gap
+		previous time.Time
+	)
+	for rows.Next() {
+		var createdAt time.Time
+		if err := rows.Scan(&createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan rate time: %w", err)
+		}
+		if !previous.IsZero() && createdAt.Sub(previous) > maxGap {
+			gaps = append(gaps, Gap{Start: previous, End: createdAt})
+		}
+		previous = createdAt
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return gaps, nil
+}
+
+// timeWeightedAverage weights each price by the time until the next one, the last one until end
+func timeWeightedAverage(prices []float64, times []time.Time, end time.Time) float64 {
+	var weightedSum, totalWeight, sum float64
+	for i, price := range prices {
+		until := end
+		if i+1 < len(times) {
+			until = times[i+1]
+		}
+		weight := until.Sub(times[i]).Seconds()
+		if weight < 0 {
+			weight = 0
+		}
+
+		weightedSum += price * weight
+		totalWeight += weight
+		sum += price
+	}
+
+	if totalWeight == 0 {
+		return sum / float64(len(prices))
+	}
+	return weightedSum / totalWeight
+}
+
+// sampleStdDev returns the sample standard deviation of values, zero for fewer than two values
+func sampleStdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+
+	var mean float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	var sumSquares float64
+	for _, v := range values {
+		sumSquares += (v - mean) * (v - mean)
+	}
+
+	return math.Sqrt(sumSquares / float64(len(values)-1))
+}
+
+// ValidateTimeRange checks that end is not before start and that the span does not exceed maxRange.
+// A zero maxRange disables the span check.
+func ValidateTimeRange(start, end time.Time, maxRange time.Duration) error {
+	if end.Before(start) {
+		return fmt.Errorf("%w: end %s is before start %s", ErrInvalidTimeRange, end.Format(time.RFC3339), start.Format(time.RFC3339))
+	}
+	if maxRange > 0 && end.Sub(start) > maxRange {
+		return fmt.Errorf("%w: span %s exceeds maximum of %s", ErrInvalidTimeRange, end.Sub(start), maxRange)
+	}
+	return nil
+}
+
+// deleteRatesBatchSize bounds the rows removed by one DELETE of DeleteRatesOlderThan, so pruning
+// a large backlog holds locks and grows the WAL in small steps
+const deleteRatesBatchSize = 10000
+
+// DeleteRatesOlderThan removes the rates created before cutoff, along with their levels, in
+// batches of deleteRatesBatchSize and returns the number of rates removed. Batches deleted before
+// a failure stay deleted and are included in the count.
+func (d *Database) DeleteRatesOlderThan(cutoff time.Time) (int64, error) {
+	rates := d.tables.Name(ratesTable)
+	query := fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE id IN (
+			SELECT id FROM %s
+			WHERE created_at < $1
+			ORDER BY id
+			LIMIT $2
+		)`, rates, rates)
+
+	var total int64
+	for {
+		result, err := d.db.Exec(query, cutoff, deleteRatesBatchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to delete old rates: %w", err)
+		}
+		deleted, err := result.RowsAffected()
+		if err != nil {
+			return total, fmt.Errorf("failed to count deleted rates: %w", err)
+		}
+		total += deleted
+
+		d.logger.Debug("Deleted batch of old rates", zap.Int64("rows", deleted), zap.Time("cutoff", cutoff))
+
+		if deleted < deleteRatesBatchSize {
+			break
+		}
+	}
+
+	d.logger.Info("Deleted old rates", zap.Int64("rows", total), zap.Time("cutoff", cutoff))
+
+	return total, nil
+}
+
+// SaveHeartbeat records that instance was alive and able to write to the database at the given
+// time. It is a no-op when heartbeat persistence is disabled.
+func (d *Database) SaveHeartbeat(instance string, at time.Time) error {
+	if d.skipHeartbeats {
+		return nil
+	}
+	if _, err := d.db.Exec(fmt.Sprintf("INSERT INTO %s (instance, created_at) VALUES ($1, $2)", d.tables.Name(heartbeatsTable)), instance, at); err != nil {
+		return fmt.Errorf("failed to save heartbeat: %w", err)
+	}
+	return nil
+}
+
 func (d *Database) HealthCheck() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -143,5 +775,10 @@ func (d *Database) HealthCheck() error {
 }
 
 func (d *Database) Close() error {
+	for _, stmt := range []*sql.Stmt{d.saveRateStmt, d.latestRateStmt} {
+		if stmt != nil {
+			stmt.Close()
+		}
+	}
 	return d.db.Close()
 }
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"testing"
 	"time"
@@ -31,16 +32,150 @@ func TestSaveRate(t *testing.T) {
 	}
 
 	mock.ExpectQuery("INSERT INTO rates").
-		WithArgs(record.TradingPair, record.AskPrice, record.BidPrice, record.Timestamp, record.CreatedAt).
+		WithArgs(record.TradingPair, record.AskPrice, record.BidPrice, record.Timestamp, record.CreatedAt, DefaultStrategy, sql.NullTime{}, DefaultSource, sql.NullFloat64{}).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 
 	err = database.SaveRate(record)
 	assert.NoError(t, err)
 	assert.Equal(t, int64(1), record.ID)
+	assert.Equal(t, DefaultStrategy, record.Strategy)
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestSaveRate_VWAP(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := &Database{db: db, logger: zap.NewNop()}
+	record := &RateRecord{
+		TradingPair: "USDT/RUB",
+		AskPrice:    100.50,
+		BidPrice:    100.40,
+		Timestamp:   time.Now(),
+		CreatedAt:   time.Now(),
+		VWAP:        100.45,
+	}
+
+	mock.ExpectQuery("INSERT INTO rates").
+		WithArgs(record.TradingPair, record.AskPrice, record.BidPrice, record.Timestamp, record.CreatedAt, DefaultStrategy, sql.NullTime{}, DefaultSource, sql.NullFloat64{Float64: 100.45, Valid: true}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	require.NoError(t, database.SaveRate(record))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSaveRate_ZeroTimestamp(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := New(db, zap.NewNop())
+
+	record := &RateRecord{
+		TradingPair: "USDT/RUB",
+		AskPrice:    100.50,
+		BidPrice:    100.40,
+		CreatedAt:   time.Now(),
+	}
+
+	err = database.SaveRate(record)
+
+	assert.ErrorIs(t, err, ErrInvalidRecord)
+	assert.Contains(t, err.Error(), "timestamp is zero")
+	assert.Zero(t, record.ID)
+	assert.NoError(t, mock.ExpectationsWereMet()) // Nothing is written
+}
+
+func TestSaveRate_Strategy(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := New(db, zap.NewNop())
+
+	record := &RateRecord{
+		TradingPair: "USDT/RUB",
+		AskPrice:    100.50,
+		BidPrice:    100.40,
+		Timestamp:   time.Now(),
+		CreatedAt:   time.Now(),
+		Strategy:    "vwap",
+	}
+
+	mock.ExpectQuery(`INSERT INTO rates \(trading_pair, ask_price, bid_price, timestamp, created_at, strategy, raw_timestamp, source, vwap\)`).
+		WithArgs(record.TradingPair, record.AskPrice, record.BidPrice, record.Timestamp, record.CreatedAt, "vwap", sql.NullTime{}, DefaultSource, sql.NullFloat64{}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	require.NoError(t, database.SaveRate(record))
+	assert.Equal(t, "vwap", record.Strategy)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSaveRate_RawTimestamp(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := New(db, zap.NewNop())
+
+	raw := time.Date(2025, 7, 28, 18, 22, 14, 0, time.UTC)
+	record := &RateRecord{
+		TradingPair:  "USDT/RUB",
+		AskPrice:     100.50,
+		BidPrice:     100.40,
+		Timestamp:    raw.Truncate(time.Minute),
+		CreatedAt:    time.Now(),
+		RawTimestamp: raw,
+	}
+
+	mock.ExpectQuery("INSERT INTO rates").
+		WithArgs(record.TradingPair, record.AskPrice, record.BidPrice, record.Timestamp, record.CreatedAt, DefaultStrategy, raw, DefaultSource, sql.NullFloat64{}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	require.NoError(t, database.SaveRate(record))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRatesByStrategy(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := New(db, zap.NewNop())
+
+	start := time.Now().Add(-time.Hour)
+	end := time.Now()
+	created := time.Now()
+
+	mock.ExpectQuery("SELECT id, trading_pair, ask_price, bid_price, timestamp, created_at, strategy FROM rates WHERE trading_pair = \\$1 AND strategy = \\$2").
+		WithArgs("USDT/RUB", "vwap", start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "trading_pair", "ask_price", "bid_price", "timestamp", "created_at", "strategy"}).
+			AddRow(2, "USDT/RUB", 100.60, 100.50, created, created, "vwap"))
+
+	records, err := database.GetRatesByStrategy("USDT/RUB", "vwap", start, end)
+
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, int64(2), records[0].ID)
+	assert.Equal(t, "vwap", records[0].Strategy)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRatesByStrategy_InvalidRange(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := New(db, zap.NewNop())
+
+	_, err = database.GetRatesByStrategy("USDT/RUB", "vwap", time.Now(), time.Now().Add(-time.Hour))
+
+	assert.ErrorIs(t, err, ErrInvalidTimeRange)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestGetLatestRate(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -61,10 +196,10 @@ func TestGetLatestRate(t *testing.T) {
 		CreatedAt:   time.Now(),
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "trading_pair", "ask_price", "bid_price", "timestamp", "created_at"}).
-		AddRow(expectedRecord.ID, expectedRecord.TradingPair, expectedRecord.AskPrice, expectedRecord.BidPrice, expectedRecord.Timestamp, expectedRecord.CreatedAt)
+	rows := sqlmock.NewRows([]string{"id", "trading_pair", "ask_price", "bid_price", "timestamp", "created_at", "vwap"}).
+		AddRow(expectedRecord.ID, expectedRecord.TradingPair, expectedRecord.AskPrice, expectedRecord.BidPrice, expectedRecord.Timestamp, expectedRecord.CreatedAt, nil)
 
-	mock.ExpectQuery("SELECT id, trading_pair, ask_price, bid_price, timestamp, created_at FROM rates").
+	mock.ExpectQuery("SELECT id, trading_pair, ask_price, bid_price, timestamp, created_at, vwap FROM rates").
 		WithArgs("USDT/RUB").
 		WillReturnRows(rows)
 
@@ -79,6 +214,26 @@ func TestGetLatestRate(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestGetLatestRate_VWAP(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := &Database{db: db, logger: zap.NewNop()}
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT id, trading_pair, ask_price, bid_price, timestamp, created_at, vwap FROM rates").
+		WithArgs("USDT/RUB").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "trading_pair", "ask_price", "bid_price", "timestamp", "created_at", "vwap"}).
+			AddRow(1, "USDT/RUB", 100.50, 100.40, now, now, 100.45))
+
+	record, err := database.GetLatestRate("USDT/RUB")
+	require.NoError(t, err)
+	assert.Equal(t, 100.45, record.VWAP)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestGetLatestRate_NoRows(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -90,7 +245,7 @@ func TestGetLatestRate_NoRows(t *testing.T) {
 		logger: logger,
 	}
 
-	mock.ExpectQuery("SELECT id, trading_pair, ask_price, bid_price, timestamp, created_at FROM rates").
+	mock.ExpectQuery("SELECT id, trading_pair, ask_price, bid_price, timestamp, created_at, vwap FROM rates").
 		WithArgs("USDT/RUB").
 		WillReturnError(sql.ErrNoRows)
 
@@ -98,6 +253,84 @@ func TestGetLatestRate_NoRows(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, record)
 	assert.Contains(t, err.Error(), "no rate found for trading pair: USDT/RUB")
+	assert.ErrorIs(t, err, ErrNoRates)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLatestRateTime(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := &Database{db: db, logger: zap.NewNop()}
+	latest := time.Date(2025, 7, 28, 18, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`SELECT MAX\(created_at\) FROM rates`).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(latest))
+
+	got, err := database.LatestRateTime(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, latest, got)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLatestRateTime_NoRates(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := &Database{db: db, logger: zap.NewNop()}
+
+	mock.ExpectQuery(`SELECT MAX\(created_at\) FROM rates`).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+
+	_, err = database.LatestRateTime(context.Background())
+	assert.ErrorIs(t, err, ErrNoRates)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAllLatestRates(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := &Database{db: db, logger: zap.NewNop()}
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT DISTINCT ON \(trading_pair\) id, trading_pair, ask_price, bid_price, timestamp, created_at, vwap FROM rates ORDER BY trading_pair, created_at DESC`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "trading_pair", "ask_price", "bid_price", "timestamp", "created_at", "vwap"}).
+			AddRow(7, "BTC/RUB", 9000000.0, 8990000.0, now, now, nil).
+			AddRow(9, "USDT/RUB", 100.50, 100.40, now, now, 100.45))
+
+	records, err := database.GetAllLatestRates(context.Background())
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, int64(7), records[0].ID)
+	assert.Equal(t, "BTC/RUB", records[0].TradingPair)
+	assert.Zero(t, records[0].VWAP)
+	assert.Equal(t, "USDT/RUB", records[1].TradingPair)
+	assert.Equal(t, 100.50, records[1].AskPrice)
+	assert.Equal(t, 100.45, records[1].VWAP)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAllLatestRates_Empty(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := &Database{db: db, logger: zap.NewNop()}
+
+	mock.ExpectQuery("SELECT DISTINCT ON").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "trading_pair", "ask_price", "bid_price", "timestamp", "created_at", "vwap"}))
+
+	records, err := database.GetAllLatestRates(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, records)
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -135,16 +368,16 @@ func TestGetRatesByTimeRange(t *testing.T) {
 		},
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "trading_pair", "ask_price", "bid_price", "timestamp", "created_at"})
+	rows := sqlmock.NewRows([]string{"id", "trading_pair", "ask_price", "bid_price", "timestamp", "created_at", "source"})
 	for _, record := range expectedRecords {
-		rows.AddRow(record.ID, record.TradingPair, record.AskPrice, record.BidPrice, record.Timestamp, record.CreatedAt)
+		rows.AddRow(record.ID, record.TradingPair, record.AskPrice, record.BidPrice, record.Timestamp, record.CreatedAt, "trades")
 	}
 
-	mock.ExpectQuery("SELECT id, trading_pair, ask_price, bid_price, timestamp, created_at FROM rates").
+	mock.ExpectQuery("SELECT id, trading_pair, ask_price, bid_price, timestamp, created_at, source FROM rates").
 		WithArgs("USDT/RUB", start, end).
 		WillReturnRows(rows)
 
-	records, err := database.GetRatesByTimeRange("USDT/RUB", start, end)
+	records, err := database.GetRatesByTimeRange(context.Background(), "USDT/RUB", start, end, "", 0)
 	assert.NoError(t, err)
 	assert.Len(t, records, 2)
 	assert.Equal(t, expectedRecords[0].ID, records[0].ID)
@@ -190,3 +423,734 @@ func TestHealthCheck_Error(t *testing.T) {
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestGetRatesByTimeRange_SourceFilter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := New(db, zap.NewNop())
+
+	start := time.Now().Add(-1 * time.Hour)
+	end := time.Now()
+
+	mock.ExpectQuery(`SELECT id, trading_pair, ask_price, bid_price, timestamp, created_at, source FROM rates WHERE trading_pair = \$1 AND created_at BETWEEN \$2 AND \$3 AND source = \$4 ORDER BY created_at DESC`).
+		WithArgs("USDT/RUB", start, end, "ticker").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "trading_pair", "ask_price", "bid_price", "timestamp", "created_at", "source"}).
+			AddRow(3, "USDT/RUB", 100.60, 100.50, end, end, "ticker"))
+
+	records, err := database.GetRatesByTimeRange(context.Background(), "USDT/RUB", start, end, "ticker", 0)
+
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, int64(3), records[0].ID)
+	assert.Equal(t, "ticker", records[0].Source)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRatesByTimeRange_AnySource(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := New(db, zap.NewNop())
+
+	start := time.Now().Add(-1 * time.Hour)
+	end := time.Now()
+
+	// Without a source the query has no source condition, and each record keeps its stored source
+	mock.ExpectQuery(`FROM rates WHERE trading_pair = \$1 AND created_at BETWEEN \$2 AND \$3 ORDER BY created_at DESC`).
+		WithArgs("USDT/RUB", start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "trading_pair", "ask_price", "bid_price", "timestamp", "created_at", "source"}).
+			AddRow(2, "USDT/RUB", 100.60, 100.50, end, end, "order_book").
+			AddRow(1, "USDT/RUB", 100.40, 100.30, end, end, "trades"))
+
+	records, err := database.GetRatesByTimeRange(context.Background(), "USDT/RUB", start, end, "", 0)
+
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "order_book", records[0].Source)
+	assert.Equal(t, "trades", records[1].Source)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRatesByTimeRange_Limit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := New(db, zap.NewNop())
+
+	start := time.Now().Add(-1 * time.Hour)
+	end := time.Now()
+
+	mock.ExpectQuery(`FROM rates WHERE trading_pair = \$1 AND created_at BETWEEN \$2 AND \$3 AND source = \$4 ORDER BY created_at DESC LIMIT \$5`).
+		WithArgs("USDT/RUB", start, end, "ticker", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "trading_pair", "ask_price", "bid_price", "timestamp", "created_at", "source"}).
+			AddRow(3, "USDT/RUB", 100.60, 100.50, end, end, "trades").
+			AddRow(2, "USDT/RUB", 100.40, 100.30, end, end, "trades"))
+
+	records, err := database.GetRatesByTimeRange(context.Background(), "USDT/RUB", start, end, "ticker", 2)
+
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSaveRate_Source(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := New(db, zap.NewNop())
+
+	record := &RateRecord{
+		TradingPair: "USDT/RUB",
+		AskPrice:    100.50,
+		BidPrice:    100.40,
+		Timestamp:   time.Now(),
+		CreatedAt:   time.Now(),
+		Source:      "ticker",
+	}
+
+	mock.ExpectQuery("INSERT INTO rates").
+		WithArgs(record.TradingPair, record.AskPrice, record.BidPrice, record.Timestamp, record.CreatedAt, DefaultStrategy, sql.NullTime{}, "ticker", sql.NullFloat64{}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	require.NoError(t, database.SaveRate(record))
+	assert.Equal(t, "ticker", record.Source)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// cancelAfterRows is a context that reports cancellation once Err has been checked rows times,
+// simulating a client that goes away while the result set is being read
+type cancelAfterRows struct {
+	context.Context
+	rows   int
+	checks int
+}
+
+func (c *cancelAfterRows) Err() error {
+	c.checks++
+	if c.checks > c.rows {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestGetRatesByTimeRange_CancelledDuringIteration(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := New(db, zap.NewNop())
+
+	start := time.Now().Add(-1 * time.Hour)
+	end := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "trading_pair", "ask_price", "bid_price", "timestamp", "created_at", "source"})
+	for i := 1; i <= 1000; i++ {
+		rows.AddRow(i, "USDT/RUB", 100.60, 100.50, end, end, "trades")
+	}
+	mock.ExpectQuery("SELECT id, trading_pair, ask_price, bid_price, timestamp, created_at, source FROM rates").
+		WithArgs("USDT/RUB", start, end).
+		WillReturnRows(rows)
+
+	ctx := &cancelAfterRows{Context: context.Background(), rows: 3}
+	records, err := database.GetRatesByTimeRange(ctx, "USDT/RUB", start, end, "", 0)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, records)
+	assert.Equal(t, 4, ctx.checks, "iteration stops at the first row after cancellation")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRatesByTimeRange_ExceedsMaxRange(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := &Database{
+		db:            db,
+		logger:        zap.NewNop(),
+		maxQueryRange: 24 * time.Hour,
+	}
+
+	end := time.Now()
+	start := end.Add(-48 * time.Hour)
+
+	records, err := database.GetRatesByTimeRange(context.Background(), "USDT/RUB", start, end, "", 0)
+	assert.ErrorIs(t, err, ErrInvalidTimeRange)
+	assert.Nil(t, records)
+	assert.Contains(t, err.Error(), "exceeds maximum")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRatesByTimeRange_InvertedRange(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := &Database{
+		db:     db,
+		logger: zap.NewNop(),
+	}
+
+	start := time.Now()
+	end := start.Add(-time.Minute)
+
+	records, err := database.GetRatesByTimeRange(context.Background(), "USDT/RUB", start, end, "", 0)
+	assert.ErrorIs(t, err, ErrInvalidTimeRange)
+	assert.Nil(t, records)
+	assert.Contains(t, err.Error(), "is before start")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidateTimeRange(t *testing.T) {
+	start := time.Now()
+
+	assert.NoError(t, ValidateTimeRange(start, start, time.Hour))
+	assert.NoError(t, ValidateTimeRange(start, start.Add(time.Hour), time.Hour))
+	assert.NoError(t, ValidateTimeRange(start, start.Add(1000*time.Hour), 0)) // Zero disables the span check
+	assert.ErrorIs(t, ValidateTimeRange(start, start.Add(time.Hour+time.Second), time.Hour), ErrInvalidTimeRange)
+	assert.ErrorIs(t, ValidateTimeRange(start, start.Add(-time.Second), 0), ErrInvalidTimeRange)
+}
+
+func TestSaveRateLevels(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := &Database{
+		db:                db,
+		logger:            zap.NewNop(),
+		persistRateLevels: true,
+		maxRateLevels:     2,
+	}
+
+	levels := []RateLevel{
+		{Side: SideAsk, Level: 1, Price: 81.30, Volume: 100},
+		{Side: SideAsk, Level: 2, Price: 81.35, Volume: 250},
+		{Side: SideAsk, Level: 3, Price: 81.40, Volume: 500}, // Beyond the configured depth
+		{Side: SideBid, Level: 1, Price: 81.20, Volume: 120},
+	}
+
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare("INSERT INTO rate_levels")
+	prep.ExpectExec().WithArgs(int64(7), SideAsk, 1, 81.30, 100.0).WillReturnResult(sqlmock.NewResult(1, 1))
+	prep.ExpectExec().WithArgs(int64(7), SideAsk, 2, 81.35, 250.0).WillReturnResult(sqlmock.NewResult(2, 1))
+	prep.ExpectExec().WithArgs(int64(7), SideBid, 1, 81.20, 120.0).WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectCommit()
+
+	err = database.SaveRateLevels(7, levels)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSaveRateLevels_RollbackOnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := &Database{
+		db:                db,
+		logger:            zap.NewNop(),
+		persistRateLevels: true,
+	}
+
+	levels := []RateLevel{
+		{Side: SideAsk, Level: 1, Price: 81.30, Volume: 100},
+		{Side: SideBid, Level: 1, Price: 81.20, Volume: 120},
+	}
+
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare("INSERT INTO rate_levels")
+	prep.ExpectExec().WithArgs(int64(7), SideAsk, 1, 81.30, 100.0).WillReturnResult(sqlmock.NewResult(1, 1))
+	prep.ExpectExec().WithArgs(int64(7), SideBid, 1, 81.20, 120.0).WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
+
+	err = database.SaveRateLevels(7, levels)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to save rate level")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSaveRateLevels_Disabled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := &Database{
+		db:     db,
+		logger: zap.NewNop(),
+	}
+
+	err = database.SaveRateLevels(7, []RateLevel{{Side: SideAsk, Level: 1, Price: 81.30, Volume: 100}})
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetVolatility(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := &Database{
+		db:     db,
+		logger: zap.NewNop(),
+	}
+
+	rows := sqlmock.NewRows([]string{"mid_price"}).
+		AddRow(2.0).
+		AddRow(4.0).
+		AddRow(4.0).
+		AddRow(4.0).
+		AddRow(5.0).
+		AddRow(5.0).
+		AddRow(7.0).
+		AddRow(9.0)
+
+	mock.ExpectQuery("SELECT \\(ask_price \\+ bid_price\\) / 2 FROM rates").
+		WithArgs("USDT/RUB", sqlmock.AnyArg()).
+		WillReturnRows(rows)
+
+	volatility, err := database.GetVolatility(context.Background(), "USDT/RUB", time.Hour)
+	assert.NoError(t, err)
+	assert.InDelta(t, 2.138089935, volatility, 1e-9) // Sample standard deviation of the series
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetVolatility_NoRates(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := &Database{
+		db:     db,
+		logger: zap.NewNop(),
+	}
+
+	mock.ExpectQuery("SELECT \\(ask_price \\+ bid_price\\) / 2 FROM rates").
+		WithArgs("USDT/RUB", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"mid_price"}))
+
+	_, err = database.GetVolatility(context.Background(), "USDT/RUB", time.Hour)
+	assert.ErrorIs(t, err, ErrNoRates)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetVolatility_SingleRate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := &Database{
+		db:     db,
+		logger: zap.NewNop(),
+	}
+
+	mock.ExpectQuery("SELECT \\(ask_price \\+ bid_price\\) / 2 FROM rates").
+		WithArgs("USDT/RUB", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"mid_price"}).AddRow(81.25))
+
+	volatility, err := database.GetVolatility(context.Background(), "USDT/RUB", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, volatility)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetVolatility_InvalidWindow(t *testing.T) {
+	database := &Database{
+		logger:        zap.NewNop(),
+		maxQueryRange: time.Hour,
+	}
+
+	_, err := database.GetVolatility(context.Background(), "USDT/RUB", 0)
+	assert.ErrorIs(t, err, ErrInvalidTimeRange)
+
+	_, err = database.GetVolatility(context.Background(), "USDT/RUB", 2*time.Hour)
+	assert.ErrorIs(t, err, ErrInvalidTimeRange)
+}
+
+func TestSaveRate_PreparedStatement(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	saveRate := mock.ExpectPrepare("INSERT INTO rates")
+	latestRate := mock.ExpectPrepare("SELECT id, trading_pair, ask_price, bid_price, timestamp, created_at, vwap FROM rates")
+
+	database := New(db, zap.NewNop())
+	require.NoError(t, database.prepareStatements())
+
+	records := []*RateRecord{
+		{TradingPair: "USDT/RUB", AskPrice: 100.50, BidPrice: 100.40, Timestamp: time.Now(), CreatedAt: time.Now()},
+		{TradingPair: "USDT/RUB", AskPrice: 100.60, BidPrice: 100.50, Timestamp: time.Now(), CreatedAt: time.Now()},
+	}
+
+	// Both inserts reuse the single prepared statement
+	for i, record := range records {
+		saveRate.ExpectQuery().
+			WithArgs(record.TradingPair, record.AskPrice, record.BidPrice, record.Timestamp, record.CreatedAt, DefaultStrategy, sql.NullTime{}, DefaultSource, sql.NullFloat64{}).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(i + 1))
+	}
+	latestRate.ExpectQuery().
+		WithArgs("USDT/RUB").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "trading_pair", "ask_price", "bid_price", "timestamp", "created_at", "vwap"}).
+			AddRow(2, "USDT/RUB", 100.60, 100.50, records[1].Timestamp, records[1].CreatedAt, nil))
+
+	for i, record := range records {
+		require.NoError(t, database.SaveRate(record))
+		assert.Equal(t, int64(i+1), record.ID)
+	}
+
+	latest, err := database.GetLatestRate("USDT/RUB")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), latest.ID)
+
+	saveRate.WillBeClosed()
+	latestRate.WillBeClosed()
+	mock.ExpectClose()
+	require.NoError(t, database.Close())
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func benchmarkSaveRate(b *testing.B, prepared bool) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(b, err)
+	defer db.Close()
+
+	database := New(db, zap.NewNop())
+	record := &RateRecord{TradingPair: "USDT/RUB", AskPrice: 100.50, BidPrice: 100.40, Timestamp: time.Now(), CreatedAt: time.Now()}
+
+	if prepared {
+		stmt := mock.ExpectPrepare(saveRateQuery)
+		mock.ExpectPrepare(latestRateQuery)
+		require.NoError(b, database.prepareStatements())
+		for i := 0; i < b.N; i++ {
+			stmt.ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		}
+	} else {
+		for i := 0; i < b.N; i++ {
+			mock.ExpectQuery(saveRateQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := database.SaveRate(record); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSaveRate_Prepared(b *testing.B) {
+	benchmarkSaveRate(b, true)
+}
+
+func BenchmarkSaveRate_Unprepared(b *testing.B) {
+	benchmarkSaveRate(b, false)
+}
+
+func TestGetTWAP(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := New(db, zap.NewNop())
+
+	start := time.Date(2025, 7, 28, 18, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	// 100 for 10 minutes, 110 for 30 minutes and 120 for the last 20 minutes
+	mock.ExpectQuery("SELECT \\(ask_price \\+ bid_price\\) / 2, created_at FROM rates").
+		WithArgs("USDT/RUB", start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"mid_price", "created_at"}).
+			AddRow(100.0, start).
+			AddRow(110.0, start.Add(10*time.Minute)).
+			AddRow(120.0, start.Add(40*time.Minute)))
+
+	twap, err := database.GetTWAP(context.Background(), "USDT/RUB", start, end)
+
+	require.NoError(t, err)
+	assert.InDelta(t, 6700.0/60, twap, 1e-9)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTWAP_SingleRow(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := New(db, zap.NewNop())
+
+	start := time.Date(2025, 7, 28, 18, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	mock.ExpectQuery("SELECT").
+		WithArgs("USDT/RUB", start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"mid_price", "created_at"}).AddRow(81.25, end))
+
+	twap, err := database.GetTWAP(context.Background(), "USDT/RUB", start, end)
+
+	require.NoError(t, err)
+	assert.Equal(t, 81.25, twap)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTWAP_NoRates(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := New(db, zap.NewNop())
+
+	start := time.Date(2025, 7, 28, 18, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT").
+		WillReturnRows(sqlmock.NewRows([]string{"mid_price", "created_at"}))
+
+	_, err = database.GetTWAP(context.Background(), "USDT/RUB", start, start.Add(time.Hour))
+
+	assert.ErrorIs(t, err, ErrNoRates)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTWAP_InvalidRange(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := New(db, zap.NewNop())
+
+	_, err = database.GetTWAP(context.Background(), "USDT/RUB", time.Now(), time.Now().Add(-time.Hour))
+
+	assert.ErrorIs(t, err, ErrInvalidTimeRange)
+}
+
+func TestFindGaps(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := New(db, zap.NewNop())
+
+	start := time.Date(2025, 7, 28, 18, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	// Rates every minute, except for a 10 minute outage after 18:02 and a 3 minute one after 18:15
+	mock.ExpectQuery(`SELECT created_at FROM rates WHERE trading_pair = \$1 AND created_at BETWEEN \$2 AND \$3 ORDER BY created_at ASC`).
+		WithArgs("USDT/RUB", start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).
+			AddRow(start).
+			AddRow(start.Add(1 * time.Minute)).
+			AddRow(start.Add(2 * time.Minute)).
+			AddRow(start.Add(12 * time.Minute)).
+			AddRow(start.Add(13 * time.Minute)).
+			AddRow(start.Add(15 * time.Minute)).
+			AddRow(start.Add(18 * time.Minute)))
+
+	gaps, err := database.FindGaps(context.Background(), "USDT/RUB", start, end, 2*time.Minute)
+
+	require.NoError(t, err)
+	require.Len(t, gaps, 2)
+	assert.Equal(t, start.Add(2*time.Minute), gaps[0].Start)
+	assert.Equal(t, start.Add(12*time.Minute), gaps[0].End)
+	assert.Equal(t, 10*time.Minute, gaps[0].Duration())
+	assert.Equal(t, start.Add(15*time.Minute), gaps[1].Start)
+	assert.Equal(t, 3*time.Minute, gaps[1].Duration())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFindGaps_NoGaps(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := New(db, zap.NewNop())
+
+	start := time.Date(2025, 7, 28, 18, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	// A gap of exactly maxGap is not reported
+	mock.ExpectQuery("SELECT created_at FROM rates").
+		WithArgs("USDT/RUB", start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).
+			AddRow(start).
+			AddRow(start.Add(time.Minute)))
+
+	gaps, err := database.FindGaps(context.Background(), "USDT/RUB", start, end, time.Minute)
+
+	require.NoError(t, err)
+	assert.Empty(t, gaps)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFindGaps_InvalidArguments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := New(db, zap.NewNop())
+	start := time.Now().Add(-time.Hour)
+	end := time.Now()
+
+	_, err = database.FindGaps(context.Background(), "USDT/RUB", start, end, 0)
+	assert.ErrorIs(t, err, ErrInvalidTimeRange)
+
+	_, err = database.FindGaps(context.Background(), "USDT/RUB", end, start, time.Minute)
+	assert.ErrorIs(t, err, ErrInvalidTimeRange)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSaveHeartbeat(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := &Database{db: db, logger: zap.NewNop()}
+	at := time.Date(2025, 7, 28, 18, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(`INSERT INTO heartbeats \(instance, created_at\) VALUES \(\$1, \$2\)`).
+		WithArgs("host-a", at).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	assert.NoError(t, database.SaveHeartbeat("host-a", at))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPersistenceToggles(t *testing.T) {
+	at := time.Date(2025, 7, 28, 18, 0, 0, 0, time.UTC)
+	record := func() *RateRecord {
+		return &RateRecord{TradingPair: "USDT/RUB", AskPrice: 81.30, BidPrice: 81.20, Timestamp: at, CreatedAt: at}
+	}
+
+	t.Run("rates disabled", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		database := &Database{db: db, logger: zap.NewNop(), skipRates: true}
+
+		// Only the heartbeat reaches the database
+		mock.ExpectExec("INSERT INTO heartbeats").WillReturnResult(sqlmock.NewResult(1, 1))
+
+		saved := record()
+		require.NoError(t, database.SaveRate(saved))
+		assert.Zero(t, saved.ID)
+		require.NoError(t, database.SaveHeartbeat("host-a", at))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("heartbeats disabled", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		database := &Database{db: db, logger: zap.NewNop(), skipHeartbeats: true}
+
+		// Only the rate reaches the database
+		mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+		saved := record()
+		require.NoError(t, database.SaveRate(saved))
+		assert.Equal(t, int64(1), saved.ID)
+		require.NoError(t, database.SaveHeartbeat("host-a", at))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rates still validated when disabled", func(t *testing.T) {
+		database := &Database{logger: zap.NewNop(), skipRates: true}
+
+		err := database.SaveRate(&RateRecord{TradingPair: "USDT/RUB"})
+		assert.ErrorIs(t, err, ErrInvalidRecord)
+	})
+}
+
+func TestConfigurePool(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	configurePool(db, &Config{MaxOpenConns: 7, MaxIdleConns: 3, ConnMaxLifetime: time.Minute})
+	database := New(db, zap.NewNop())
+
+	assert.Equal(t, 7, database.db.Stats().MaxOpenConnections)
+}
+
+func TestConfigurePool_ZeroKeepsDefaults(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	configurePool(db, &Config{})
+	database := New(db, zap.NewNop())
+
+	// Zero is database/sql's unlimited default, not a pool without connections
+	assert.Equal(t, 0, database.db.Stats().MaxOpenConnections)
+	require.NoError(t, database.HealthCheck())
+}
+
+func TestDeleteRatesOlderThan(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := New(db, zap.NewNop())
+	cutoff := time.Date(2025, 4, 28, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(`DELETE FROM rates\s+WHERE id IN \(\s+SELECT id FROM rates\s+WHERE created_at < \$1\s+ORDER BY id\s+LIMIT \$2`).
+		WithArgs(cutoff, deleteRatesBatchSize).
+		WillReturnResult(sqlmock.NewResult(0, 42))
+
+	deleted, err := database.DeleteRatesOlderThan(cutoff)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteRatesOlderThan_Batches(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := New(db, zap.NewNop())
+	cutoff := time.Date(2025, 4, 28, 0, 0, 0, 0, time.UTC)
+
+	// Full batches are followed by another until one comes back short
+	mock.ExpectExec("DELETE FROM rates").WithArgs(cutoff, deleteRatesBatchSize).WillReturnResult(sqlmock.NewResult(0, deleteRatesBatchSize))
+	mock.ExpectExec("DELETE FROM rates").WithArgs(cutoff, deleteRatesBatchSize).WillReturnResult(sqlmock.NewResult(0, deleteRatesBatchSize))
+	mock.ExpectExec("DELETE FROM rates").WithArgs(cutoff, deleteRatesBatchSize).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	deleted, err := database.DeleteRatesOlderThan(cutoff)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(2*deleteRatesBatchSize), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteRatesOlderThan_ErrorKeepsCount(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	database := New(db, zap.NewNop())
+	cutoff := time.Now()
+
+	mock.ExpectExec("DELETE FROM rates").WillReturnResult(sqlmock.NewResult(0, deleteRatesBatchSize))
+	mock.ExpectExec("DELETE FROM rates").WillReturnError(sql.ErrConnDone)
+
+	deleted, err := database.DeleteRatesOlderThan(cutoff)
+
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+	assert.Equal(t, int64(deleteRatesBatchSize), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,30 +1,85 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	_ "github.com/lib/pq"
 )
 
-func RunMigrations(dsn string) error {
+// DefaultMigrationLockKey is the advisory lock key taken around migrations unless configured otherwise
+const DefaultMigrationLockKey int64 = 7306142
+
+// RunMigrations applies the pending migrations. A non-zero lockKey holds a Postgres advisory
+// lock with that key while migrating, so instances starting together migrate one at a time
+// and the others wait for it and then find nothing left to do. The tables are created in the
+// schema of tables, which is created when missing, with its prefix.
+func RunMigrations(dsn string, lockKey int64, tables Tables) error {
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
 	defer db.Close()
 
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	if lockKey == 0 {
+		return migrateUp(db, tables)
+	}
+
+	// Advisory locks belong to a session, so lock and unlock on one dedicated connection
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection for migration lock: %w", err)
+	}
+	defer conn.Close()
+
+	return withAdvisoryLock(ctx, conn, lockKey, func() error {
+		return migrateUp(db, tables)
+	})
+}
+
+func migrateUp(db *sql.DB, tables Tables) error {
+	// The migrations use unqualified names, so they run on a connection whose search_path is the schema
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection for migrations: %w", err)
+	}
+	defer conn.Close()
+
+	if tables.Schema != "" {
+		// Identifiers are validated by NewTables, so they are safe to put in the statements
+		if tables.Schema != DefaultSchema {
+			if _, err := conn.ExecContext(ctx, "CREATE SCHEMA IF NOT EXISTS "+tables.Schema); err != nil {
+				return fmt.Errorf("failed to create schema %s: %w", tables.Schema, err)
+			}
+		}
+		if _, err := conn.ExecContext(ctx, "SET search_path TO "+tables.Schema); err != nil {
+			return fmt.Errorf("failed to set search_path to %s: %w", tables.Schema, err)
+		}
+	}
+
+	driver, err := postgres.WithConnection(ctx, conn, &postgres.Config{
+		SchemaName:      tables.Schema,
+		MigrationsTable: tables.Prefix + migrationsTable,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to create postgres instance: %w", err)
 	}
 
-	m, err := migrate.NewWithDatabaseInstance(
-		"file://migrations",
+	src, err := source.Open("file://migrations")
+	if err != nil {
+		return fmt.Errorf("failed to open migrations: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance(
+		"file", &prefixedSource{Driver: src, tables: tables},
 		"postgres", driver)
 	if err != nil {
 		return fmt.Errorf("failed to create migrate instance: %w", err)
@@ -37,3 +92,23 @@ func RunMigrations(dsn string) error {
 	log.Println("Database migrations completed successfully")
 	return nil
 }
+
+// execer is the part of *sql.Conn used to take and release advisory locks
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// withAdvisoryLock runs fn while holding the session advisory lock key on conn, waiting for
+// other sessions holding it. The lock is released even when fn fails.
+func withAdvisoryLock(ctx context.Context, conn execer, key int64, fn func() error) (err error) {
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", key); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, unlockErr := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", key); unlockErr != nil && err == nil {
+			err = fmt.Errorf("failed to release migration lock: %w", unlockErr)
+		}
+	}()
+
+	return fn()
+}
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAdvisoryLocks emulates Postgres session advisory locks: pg_advisory_lock blocks while
+// another session holds the key and pg_advisory_unlock releases it
+type fakeAdvisoryLocks struct {
+	mu    sync.Mutex
+	locks map[int64]*sync.Mutex
+}
+
+func (f *fakeAdvisoryLocks) lock(key int64) *sync.Mutex {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.locks == nil {
+		f.locks = make(map[int64]*sync.Mutex)
+	}
+	if f.locks[key] == nil {
+		f.locks[key] = &sync.Mutex{}
+	}
+	return f.locks[key]
+}
+
+func (f *fakeAdvisoryLocks) ExecContext(_ context.Context, query string, args ...interface{}) (sql.Result, error) {
+	lock := f.lock(args[0].(int64))
+	switch query {
+	case "SELECT pg_advisory_lock($1)":
+		lock.Lock()
+	case "SELECT pg_advisory_unlock($1)":
+		lock.Unlock()
+	default:
+		return nil, errors.New("unexpected query: " + query)
+	}
+	return nil, nil
+}
+
+func TestWithAdvisoryLock_SerializesConcurrentCalls(t *testing.T) {
+	var locks fakeAdvisoryLocks
+	var active, maxActive, runs atomic.Int32
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := withAdvisoryLock(context.Background(), &locks, DefaultMigrationLockKey, func() error {
+				n := active.Add(1)
+				for {
+					current := maxActive.Load()
+					if n <= current || maxActive.CompareAndSwap(current, n) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				active.Add(-1)
+				runs.Add(1)
+				return nil
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(5), runs.Load())
+	assert.Equal(t, int32(1), maxActive.Load(), "migrations overlapped")
+}
+
+func TestWithAdvisoryLock_Queries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(`SELECT pg_advisory_lock\(\$1\)`).WithArgs(int64(42)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`SELECT pg_advisory_unlock\(\$1\)`).WithArgs(int64(42)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	called := false
+	err = withAdvisoryLock(context.Background(), db, 42, func() error {
+		called = true
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.True(t, called)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithAdvisoryLock_UnlocksOnFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(`SELECT pg_advisory_lock\(\$1\)`).WithArgs(int64(42)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`SELECT pg_advisory_unlock\(\$1\)`).WithArgs(int64(42)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	migrateErr := errors.New("dirty database version 4")
+	err = withAdvisoryLock(context.Background(), db, 42, func() error { return migrateErr })
+
+	assert.ErrorIs(t, err, migrateErr)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithAdvisoryLock_LockFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(`SELECT pg_advisory_lock\(\$1\)`).WillReturnError(errors.New("connection reset"))
+
+	err = withAdvisoryLock(context.Background(), db, 42, func() error {
+		t.Fatal("migrated without the lock")
+		return nil
+	})
+
+	assert.ErrorContains(t, err, "failed to acquire migration lock")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
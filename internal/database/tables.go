@@ -0,0 +1,122 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// DefaultSchema is the schema the tables are created in unless configured otherwise
+const DefaultSchema = "public"
+
+// Unprefixed names of the tables created by the migrations
+const (
+	ratesTable      = "rates"
+	rateLevelsTable = "rate_levels"
+	heartbeatsTable = "heartbeats"
+	// migrationsTable is where golang-migrate records the applied version
+	migrationsTable = "schema_migrations"
+)
+
+// maxIdentifierLength is the longest identifier Postgres keeps, longer ones are silently truncated
+const maxIdentifierLength = 63
+
+// ErrInvalidIdentifier is returned for a schema or table prefix that is not a plain lower case identifier
+var ErrInvalidIdentifier = errors.New("invalid identifier")
+
+// identifierPattern matches the identifiers that are used unquoted in queries, so accepting
+// nothing else keeps configured names from injecting SQL
+var identifierPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// migrationNamePattern matches the table names and index name prefixes in the migrations
+var migrationNamePattern = regexp.MustCompile(`\b(rates|rate_levels|heartbeats)\b|\bidx_`)
+
+// Tables locates the service tables in a database shared with others: Schema qualifies every
+// table and Prefix is prepended to the table and index names. The zero value leaves names as
+// they are, resolved through the search_path.
+type Tables struct {
+	Schema string
+	Prefix string
+}
+
+// NewTables validates schema and prefix. The schema is required, the prefix may be empty.
+func NewTables(schema, prefix string) (Tables, error) {
+	if err := validateIdentifier("schema", schema); err != nil {
+		return Tables{}, err
+	}
+	if prefix != "" {
+		if err := validateIdentifier("table prefix", prefix+migrationsTable); err != nil {
+			return Tables{}, err
+		}
+	}
+	return Tables{Schema: schema, Prefix: prefix}, nil
+}
+
+func validateIdentifier(kind, name string) error {
+	if !identifierPattern.MatchString(name) {
+		return fmt.Errorf("%w: %s %q must be lower case letters, digits and underscores, not starting with a digit", ErrInvalidIdentifier, kind, name)
+	}
+	if len(name) > maxIdentifierLength {
+		return fmt.Errorf("%w: %s %q is longer than %d characters", ErrInvalidIdentifier, kind, name, maxIdentifierLength)
+	}
+	return nil
+}
+
+// Name returns the prefixed and, when a schema is set, schema qualified name of table
+func (t Tables) Name(table string) string {
+	if t.Schema == "" {
+		return t.Prefix + table
+	}
+	return t.Schema + "." + t.Prefix + table
+}
+
+// prefixMigration prepends the prefix to the table and index names of a migration. Schema
+// qualification is left to the search_path of the migrating connection, as index names
+// cannot be qualified in CREATE INDEX.
+func (t Tables) prefixMigration(migration string) string {
+	if t.Prefix == "" {
+		return migration
+	}
+	return migrationNamePattern.ReplaceAllStringFunc(migration, func(name string) string {
+		if name == "idx_" {
+			return name + t.Prefix
+		}
+		return t.Prefix + name
+	})
+}
+
+// prefixedSource is a migration source whose migrations are rewritten by prefixMigration
+type prefixedSource struct {
+	source.Driver
+	tables Tables
+}
+
+func (s *prefixedSource) ReadUp(version uint) (io.ReadCloser, string, error) {
+	r, identifier, err := s.Driver.ReadUp(version)
+	if err != nil {
+		return nil, "", err
+	}
+	return s.rewrite(r, identifier)
+}
+
+func (s *prefixedSource) ReadDown(version uint) (io.ReadCloser, string, error) {
+	r, identifier, err := s.Driver.ReadDown(version)
+	if err != nil {
+		return nil, "", err
+	}
+	return s.rewrite(r, identifier)
+}
+
+func (s *prefixedSource) rewrite(r io.ReadCloser, identifier string) (io.ReadCloser, string, error) {
+	defer r.Close()
+
+	migration, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read migration %s: %w", identifier, err)
+	}
+	return io.NopCloser(strings.NewReader(s.tables.prefixMigration(string(migration)))), identifier, nil
+}
@@ -0,0 +1,149 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestNewTables(t *testing.T) {
+	tables, err := NewTables("billing", "grx_")
+	require.NoError(t, err)
+	assert.Equal(t, Tables{Schema: "billing", Prefix: "grx_"}, tables)
+
+	tables, err = NewTables(DefaultSchema, "")
+	require.NoError(t, err)
+	assert.Equal(t, "public.rates", tables.Name(ratesTable))
+}
+
+func TestNewTables_InvalidIdentifiers(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		schema string
+		prefix string
+	}{
+		{name: "empty schema", schema: "", prefix: ""},
+		{name: "injected schema", schema: "public; DROP TABLE rates; --", prefix: ""},
+		{name: "quoted schema", schema: `"billing"`, prefix: ""},
+		{name: "upper case schema", schema: "Billing", prefix: ""},
+		{name: "leading digit", schema: "1billing", prefix: ""},
+		{name: "too long schema", schema: strings.Repeat("s", maxIdentifierLength+1), prefix: ""},
+		{name: "injected prefix", schema: DefaultSchema, prefix: "x.rates --"},
+		{name: "too long prefix", schema: DefaultSchema, prefix: strings.Repeat("p", maxIdentifierLength-len(migrationsTable)+1)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewTables(tc.schema, tc.prefix)
+			assert.ErrorIs(t, err, ErrInvalidIdentifier)
+		})
+	}
+}
+
+func TestTablesName(t *testing.T) {
+	assert.Equal(t, "rates", Tables{}.Name(ratesTable))
+	assert.Equal(t, "grx_rates", Tables{Prefix: "grx_"}.Name(ratesTable))
+	assert.Equal(t, "billing.grx_rate_levels", Tables{Schema: "billing", Prefix: "grx_"}.Name(rateLevelsTable))
+}
+
+func TestPrefixMigration(t *testing.T) {
+	migration := `CREATE TABLE IF NOT EXISTS rate_levels (
+    rate_id BIGINT NOT NULL REFERENCES rates(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_rate_levels_rate_id ON rate_levels(rate_id);
+DROP INDEX IF EXISTS idx_rates_trading_pair_source_created_at;
+INSERT INTO heartbeats (instance) VALUES ('rates');`
+
+	assert.Equal(t, `CREATE TABLE IF NOT EXISTS grx_rate_levels (
+    rate_id BIGINT NOT NULL REFERENCES grx_rates(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_grx_rate_levels_rate_id ON grx_rate_levels(rate_id);
+DROP INDEX IF EXISTS idx_grx_rates_trading_pair_source_created_at;
+INSERT INTO grx_heartbeats (instance) VALUES ('grx_rates');`, Tables{Schema: "billing", Prefix: "grx_"}.prefixMigration(migration))
+
+	assert.Equal(t, migration, Tables{Schema: "billing"}.prefixMigration(migration))
+}
+
+// newSchemaDatabase returns a database whose tables live in the billing schema with the grx_ prefix
+func newSchemaDatabase(t *testing.T) (*Database, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	database := New(db, zap.NewNop())
+	database.tables = Tables{Schema: "billing", Prefix: "grx_"}
+	database.persistRateLevels = true
+	return database, mock
+}
+
+func TestConfiguredSchema_Queries(t *testing.T) {
+	database, mock := newSchemaDatabase(t)
+	now := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO billing.grx_rates (")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectBegin()
+	mock.ExpectPrepare(regexp.QuoteMeta("INSERT INTO billing.grx_rate_levels (")).
+		ExpectExec().WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM billing.grx_rates\n\t\tWHERE trading_pair = $1\n")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "trading_pair", "ask_price", "bid_price", "timestamp", "created_at", "vwap"}).
+			AddRow(1, "USDT/RUB", 81.3, 81.2, now, now, nil))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM billing.grx_rates\n\t\tWHERE trading_pair = $1 AND created_at BETWEEN")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "trading_pair", "ask_price", "bid_price", "timestamp", "created_at", "source"}))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO billing.grx_heartbeats (")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM billing.grx_rates\n\t\tWHERE id IN (\n\t\t\tSELECT id FROM billing.grx_rates\n")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, database.SaveRate(&RateRecord{TradingPair: "USDT/RUB", AskPrice: 81.3, BidPrice: 81.2, Timestamp: now, CreatedAt: now}))
+	require.NoError(t, database.SaveRateLevels(1, []RateLevel{{Side: SideAsk, Level: 1, Price: 81.3, Volume: 10}}))
+	_, err := database.GetLatestRate("USDT/RUB")
+	require.NoError(t, err)
+	_, err = database.GetRatesByTimeRange(context.Background(), "USDT/RUB", now.Add(-time.Hour), now, "", 0)
+	require.NoError(t, err)
+	require.NoError(t, database.SaveHeartbeat("instance-1", now))
+	_, err = database.DeleteRatesOlderThan(now)
+	require.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConfiguredSchema_PreparedStatements(t *testing.T) {
+	database, mock := newSchemaDatabase(t)
+
+	mock.ExpectPrepare(regexp.QuoteMeta("INSERT INTO billing.grx_rates ("))
+	mock.ExpectPrepare(regexp.QuoteMeta("FROM billing.grx_rates"))
+
+	require.NoError(t, database.prepareStatements())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConfiguredSchema_AggregateQueries(t *testing.T) {
+	database, mock := newSchemaDatabase(t)
+	end := time.Now()
+	start := end.Add(-time.Hour)
+
+	for i := 0; i < 4; i++ {
+		mock.ExpectQuery(regexp.QuoteMeta("FROM billing.grx_rates")).WillReturnError(sql.ErrConnDone)
+	}
+
+	_, err := database.GetRatesByStrategy("USDT/RUB", DefaultStrategy, start, end)
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+	_, err = database.GetVolatility(context.Background(), "USDT/RUB", time.Hour)
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+	_, err = database.GetTWAP(context.Background(), "USDT/RUB", start, end)
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+	_, err = database.FindGaps(context.Background(), "USDT/RUB", start, end, time.Minute)
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,56 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/atadzan/grinex-rate-service/internal/database"
+)
+
+// csvHeader is the header row of exported rate files
+var csvHeader = []string{"id", "trading_pair", "ask_price", "bid_price", "timestamp", "created_at"}
+
+// RateStreamer streams stored rates in a time range
+type RateStreamer interface {
+	StreamRatesByTimeRange(tradingPair string, start, end time.Time, fn func(*database.RateRecord) error) error
+}
+
+// WriteRatesCSV writes the rates of a trading pair in [start, end] to w as CSV with a header row,
+// streaming rows as they are read. It returns the number of rows written, excluding the header.
+func WriteRatesCSV(w io.Writer, rates RateStreamer, tradingPair string, start, end time.Time) (int, error) {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(csvHeader); err != nil {
+		return 0, fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	count := 0
+	err := rates.StreamRatesByTimeRange(tradingPair, start, end, func(record *database.RateRecord) error {
+		row := []string{
+			strconv.FormatInt(record.ID, 10),
+			record.TradingPair,
+			strconv.FormatFloat(record.AskPrice, 'f', -1, 64),
+			strconv.FormatFloat(record.BidPrice, 'f', -1, 64),
+			record.Timestamp.UTC().Format(time.RFC3339Nano),
+			record.CreatedAt.UTC().Format(time.RFC3339Nano),
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return count, fmt.Errorf("failed to flush CSV: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,62 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/atadzan/grinex-rate-service/internal/database"
+)
+
+func TestWriteRatesCSV(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	start := time.Date(2025, 7, 28, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	rows := sqlmock.NewRows([]string{"id", "trading_pair", "ask_price", "bid_price", "timestamp", "created_at", "source"}).
+		AddRow(2, "USDT/RUB", 81.3, 81.2, start.Add(2*time.Hour), start.Add(2*time.Hour+time.Second), "trades").
+		AddRow(1, "USDT/RUB", 81.25, 81.1, start.Add(time.Hour), start.Add(time.Hour+time.Second), "trades")
+
+	mock.ExpectQuery("SELECT id, trading_pair, ask_price, bid_price, timestamp, created_at, source FROM rates").
+		WithArgs("USDT/RUB", start, end).
+		WillReturnRows(rows)
+
+	var buf bytes.Buffer
+	count, err := WriteRatesCSV(&buf, database.New(db, zap.NewNop()), "USDT/RUB", start, end)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, "id,trading_pair,ask_price,bid_price,timestamp,created_at\n"+
+		"2,USDT/RUB,81.3,81.2,2025-07-28T02:00:00Z,2025-07-28T02:00:01Z\n"+
+		"1,USDT/RUB,81.25,81.1,2025-07-28T01:00:00Z,2025-07-28T01:00:01Z\n", buf.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWriteRatesCSV_Empty(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	start := time.Date(2025, 7, 28, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	mock.ExpectQuery("SELECT id, trading_pair, ask_price, bid_price, timestamp, created_at, source FROM rates").
+		WithArgs("USDT/RUB", start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "trading_pair", "ask_price", "bid_price", "timestamp", "created_at", "source"}))
+
+	var buf bytes.Buffer
+	count, err := WriteRatesCSV(&buf, database.New(db, zap.NewNop()), "USDT/RUB", start, end)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, "id,trading_pair,ask_price,bid_price,timestamp,created_at\n", buf.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,290 @@
+package poller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/atadzan/grinex-rate-service/internal/service"
+)
+
+// PollFunc fetches and stores the rate of a Grinex market, e.g. through the server's rate cache
+// so a poll and a concurrent client request share one fetch, and returns the rate
+type PollFunc func(ctx context.Context, market string) (*service.Rate, error)
+
+// maxBackoffTicks bounds the ticks a failing market is skipped for
+const maxBackoffTicks = 15
+
+// MarketStatus is the polling state of a market
+type MarketStatus struct {
+	Market string
+	// LastFetch is when a poll last got a rate of the market, zero before the first one
+	LastFetch time.Time
+	// LastPrice is the mid price of that rate
+	LastPrice float64
+	// ConsecutiveFailures counts the polls failed since the last successful one
+	ConsecutiveFailures int
+	// Backoff is the time the market is skipped for after its last failed poll, zero when it is
+	// retried on the next tick
+	Backoff time.Duration
+	// LastError is the error of the last poll, empty when it succeeded
+	LastError string
+	// TradesSeen counts the distinct trades the polled rates were computed from since the
+	// poller started, a trade reappearing in a later poll being counted once
+	TradesSeen int
+	// SessionVWAP is the volume weighted average price of those trades, zero before the first
+	SessionVWAP float64
+}
+
+// marketState is the polling state of a market along with the ticks it is still skipped for
+// and the totals its session VWAP is computed from
+type marketState struct {
+	status MarketStatus
+	skip   int
+	funds  float64
+	volume float64
+}
+
+// Config holds configuration for the poller
+type Config struct {
+	Interval time.Duration
+	// Pairs to poll, e.g. "USDT/RUB" or "btcrub"
+	Pairs []string
+	// AllowedMarkets restricts the pairs that may be polled, empty allowing every market
+	AllowedMarkets []string
+}
+
+// Poller polls the rates of its markets every interval
+type Poller struct {
+	poll     PollFunc
+	interval time.Duration
+	markets  []string
+	logger   *zap.Logger
+
+	// newTicker returns the tick channel and stop function of a ticker, replaced in tests
+	newTicker func(time.Duration) (<-chan time.Time, func())
+
+	mu sync.Mutex
+	// resume is non-nil while paused and closed by Resume
+	resume chan struct{}
+	// states holds the polling state of each market
+	states map[string]*marketState
+	// watermark excludes the trades of earlier polls from the session totals
+	watermark *service.TradeWatermark
+}
+
+// New resolves the configured pairs to Grinex markets and returns a poller calling poll for
+// them. Pairs outside the allowed markets are rejected.
+func New(grinex *service.GrinexService, poll PollFunc, config Config, logger *zap.Logger) (*Poller, error) {
+	if config.Interval <= 0 {
+		return nil, fmt.Errorf("poll interval must be positive, got %s", config.Interval)
+	}
+	if len(config.Pairs) == 0 {
+		return nil, errors.New("no pairs to poll")
+	}
+
+	markets := make([]string, 0, len(config.Pairs))
+	states := make(map[string]*marketState, len(config.Pairs))
+	for _, pair := range config.Pairs {
+		market, err := grinex.ResolvePair(pair)
+		if err != nil {
+			return nil, fmt.Errorf("invalid poll pair: %w", err)
+		}
+		if !marketAllowed(config.AllowedMarkets, market) {
+			return nil, fmt.Errorf("poll pair %s is not an allowed market", pair)
+		}
+		markets = append(markets, market)
+		states[market] = &marketState{status: MarketStatus{Market: market}}
+	}
+
+	return &Poller{
+		poll:      poll,
+		interval:  config.Interval,
+		markets:   markets,
+		logger:    logger,
+		newTicker: newTimeTicker,
+		states:    states,
+		watermark: service.NewTradeWatermark(),
+	}, nil
+}
+
+// marketAllowed reports whether market is listed in allowed, an empty list allowing every market
+func marketAllowed(allowed []string, market string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, m := range allowed {
+		if strings.EqualFold(m, market) {
+			return true
+		}
+	}
+	return false
+}
+
+func newTimeTicker(interval time.Duration) (<-chan time.Time, func()) {
+	ticker := time.NewTicker(interval)
+	return ticker.C, ticker.Stop
+}
+
+// Run polls right away and then every interval until ctx is cancelled. Failures are logged per
+// market, which is retried on the next tick after its first failure and then backs off, skipping
+// 1, 3, 7... ticks up to maxBackoffTicks until a poll succeeds again. While paused the ticker is stopped and Run waits for
+// Resume, after which it polls right away and ticks again from then on.
+func (p *Poller) Run(ctx context.Context) {
+	ticks, stop := p.newTicker(p.interval)
+	defer func() { stop() }()
+
+	p.logger.Info("Poller started",
+		zap.Duration("interval", p.interval),
+		zap.Strings("markets", p.markets),
+	)
+
+	for {
+		if resume := p.pausedUntil(); resume != nil {
+			stop()
+			p.logger.Info("Poller paused")
+			select {
+			case <-ctx.Done():
+				p.logger.Info("Poller stopped")
+				return
+			case <-resume:
+			}
+			p.logger.Info("Poller resumed")
+			ticks, stop = p.newTicker(p.interval)
+		}
+
+		p.pollAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			p.logger.Info("Poller stopped")
+			return
+		case <-ticks:
+		}
+	}
+}
+
+// Pause stops polling until Resume. A poll in progress is finished first. It returns false
+// when the poller was already paused.
+func (p *Poller) Pause() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.resume != nil {
+		return false
+	}
+	p.resume = make(chan struct{})
+	return true
+}
+
+// Resume polls right away and then every interval again after Pause. It returns false when the
+// poller was not paused.
+func (p *Poller) Resume() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.resume == nil {
+		return false
+	}
+	close(p.resume)
+	p.resume = nil
+	return true
+}
+
+// Paused reports whether the poller is paused
+func (p *Poller) Paused() bool {
+	return p.pausedUntil() != nil
+}
+
+// pausedUntil returns the channel closed on Resume while paused, and nil otherwise
+func (p *Poller) pausedUntil() chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.resume
+}
+
+// Status returns the polling state of every market in the configured order
+func (p *Poller) Status() []MarketStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	statuses := make([]MarketStatus, len(p.markets))
+	for i, market := range p.markets {
+		statuses[i] = p.states[market].status
+	}
+	return statuses
+}
+
+func (p *Poller) pollAll(ctx context.Context) {
+	for _, market := range p.markets {
+		if ctx.Err() != nil {
+			return
+		}
+		if p.backingOff(market) {
+			continue
+		}
+
+		rate, err := p.poll(ctx, market)
+		if err != nil {
+			p.logger.Warn("Failed to poll rate", zap.String("market", market), zap.Error(err))
+		}
+		p.record(market, rate, err)
+	}
+}
+
+// backingOff reports whether market is skipped on this tick, counting the tick off its backoff
+func (p *Poller) backingOff(market string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	state := p.states[market]
+	if state.skip == 0 {
+		return false
+	}
+	state.skip--
+	return true
+}
+
+// record updates the polling state of market with the result of a poll
+func (p *Poller) record(market string, rate *service.Rate, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	state := p.states[market]
+	if err != nil {
+		state.status.ConsecutiveFailures++
+		state.status.LastError = err.Error()
+		state.skip = 0
+		if state.status.ConsecutiveFailures > 1 {
+			state.skip = min(2*int(state.status.Backoff/p.interval)+1, maxBackoffTicks)
+		}
+		state.status.Backoff = time.Duration(state.skip) * p.interval
+		return
+	}
+
+	state.status.ConsecutiveFailures = 0
+	state.status.LastError = ""
+	state.status.Backoff = 0
+	state.skip = 0
+	if rate == nil {
+		return
+	}
+	state.status.LastFetch = time.Now()
+	state.status.LastPrice = rate.MidPrice
+
+	// Consecutive polls mostly return the same recent trades, only the new ones are added
+	fresh := p.watermark.Filter(market, rate.Trades)
+	funds, volume := service.TradesFundsVolume(fresh)
+	state.funds += funds
+	state.volume += volume
+	state.status.TradesSeen += len(fresh)
+	if state.volume > 0 {
+		state.status.SessionVWAP = state.funds / state.volume
+	}
+}
@@ -0,0 +1,283 @@
+package poller
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/atadzan/grinex-rate-service/internal/service"
+)
+
+// recorder is a PollFunc recording the markets it was called for
+type recorder struct {
+	mu      sync.Mutex
+	markets []string
+	err     func(market string, call int) error
+	// trades are the trades of the rate returned by each call in turn
+	trades [][]service.GrinexTrade
+}
+
+func (r *recorder) poll(_ context.Context, market string) (*service.Rate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.markets = append(r.markets, market)
+	if r.err != nil {
+		if err := r.err(market, len(r.markets)); err != nil {
+			return nil, err
+		}
+	}
+	rate := &service.Rate{MidPrice: float64(len(r.markets))}
+	if call := len(r.markets); call <= len(r.trades) {
+		rate.Trades = r.trades[call-1]
+	}
+	return rate, nil
+}
+
+func (r *recorder) calls() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.markets...)
+}
+
+// newTestPoller returns a poller of pairs recording its polls, ticking on the returned channel
+// instead of a timer
+func newTestPoller(t *testing.T, pairs ...string) (*Poller, *recorder, chan time.Time) {
+	t.Helper()
+
+	rec := &recorder{}
+	grinexSvc := service.NewGrinexService(&service.GrinexConfig{}, zap.NewNop())
+	p, err := New(grinexSvc, rec.poll, Config{
+		Interval: time.Hour,
+		Pairs:    pairs,
+	}, zap.NewNop())
+	require.NoError(t, err)
+
+	ticks := make(chan time.Time)
+	p.newTicker = func(time.Duration) (<-chan time.Time, func()) {
+		return ticks, func() {}
+	}
+	return p, rec, ticks
+}
+
+// runPoller runs p until the test ends and returns a channel closed once Run returns
+func runPoller(t *testing.T, p *Poller) (context.CancelFunc, <-chan struct{}) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return cancel, done
+}
+
+func TestPoller_PollsEveryTick(t *testing.T) {
+	p, rec, ticks := newTestPoller(t, "USDT/RUB")
+
+	runPoller(t, p)
+
+	// One poll on start, one per tick
+	ticks <- time.Now()
+	ticks <- time.Now()
+	require.Eventually(t, func() bool { return len(rec.calls()) == 3 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"usdtrub", "usdtrub", "usdtrub"}, rec.calls())
+}
+
+func TestPoller_ContinuesAfterFailures(t *testing.T) {
+	p, rec, ticks := newTestPoller(t, "btcrub", "usdtrub")
+	rec.err = func(market string, call int) error {
+		if market == "btcrub" || call == 2 {
+			return errors.New("bad gateway")
+		}
+		return nil
+	}
+
+	runPoller(t, p)
+
+	// The first usdtrub poll and every btcrub poll fail, polling still goes on
+	ticks <- time.Now()
+	require.Eventually(t, func() bool { return len(rec.calls()) == 4 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"btcrub", "usdtrub", "btcrub", "usdtrub"}, rec.calls())
+}
+
+func TestPoller_BacksOffAfterFailures(t *testing.T) {
+	p, rec, ticks := newTestPoller(t, "usdtrub")
+	var failing atomic.Bool
+	failing.Store(true)
+	rec.err = func(string, int) error {
+		if failing.Load() {
+			return errors.New("bad gateway")
+		}
+		return nil
+	}
+
+	runPoller(t, p)
+
+	// Polls on start and on the first tick fail, the second failure skips one tick
+	ticks <- time.Now()
+	require.Eventually(t, func() bool { return p.Status()[0].ConsecutiveFailures == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, time.Hour, p.Status()[0].Backoff)
+	ticks <- time.Now()
+	ticks <- time.Now() // Only read once the skipped tick was handled
+	require.Eventually(t, func() bool { return p.Status()[0].ConsecutiveFailures == 3 }, time.Second, time.Millisecond)
+	assert.Len(t, rec.calls(), 3)
+	assert.Equal(t, 3*time.Hour, p.Status()[0].Backoff)
+	assert.Equal(t, "bad gateway", p.Status()[0].LastError)
+
+	// A successful poll clears the backoff
+	failing.Store(false)
+	for range 4 {
+		ticks <- time.Now()
+	}
+	require.Eventually(t, func() bool { return p.Status()[0].ConsecutiveFailures == 0 }, time.Second, time.Millisecond)
+	status := p.Status()[0]
+	assert.Len(t, rec.calls(), 4)
+	assert.Zero(t, status.Backoff)
+	assert.Empty(t, status.LastError)
+	assert.Equal(t, 4.0, status.LastPrice)
+	assert.False(t, status.LastFetch.IsZero())
+}
+
+func TestPoller_Status(t *testing.T) {
+	p, _, _ := newTestPoller(t, "btcrub", "USDT/RUB")
+
+	statuses := p.Status()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "btcrub", statuses[0].Market)
+	assert.Equal(t, "usdtrub", statuses[1].Market)
+	assert.True(t, statuses[0].LastFetch.IsZero(), "not polled yet")
+
+	runPoller(t, p)
+	require.Eventually(t, func() bool { return !p.Status()[1].LastFetch.IsZero() }, time.Second, time.Millisecond)
+	assert.Equal(t, 1.0, p.Status()[0].LastPrice)
+	assert.Equal(t, 2.0, p.Status()[1].LastPrice)
+}
+
+func TestPoller_SessionVWAPExcludesSeenTrades(t *testing.T) {
+	p, rec, ticks := newTestPoller(t, "usdtrub")
+	rec.trades = [][]service.GrinexTrade{
+		{{ID: 2, Volume: "10", Funds: "812"}, {ID: 1, Volume: "10", Funds: "810"}},
+		// The second fetch returns trade 2 again along with a new one
+		{{ID: 3, Volume: "20", Funds: "1630"}, {ID: 2, Volume: "10", Funds: "812"}},
+	}
+
+	runPoller(t, p)
+	require.Eventually(t, func() bool { return p.Status()[0].TradesSeen == 2 }, time.Second, time.Millisecond)
+	assert.InDelta(t, 81.1, p.Status()[0].SessionVWAP, 1e-9)
+
+	ticks <- time.Now()
+	require.Eventually(t, func() bool { return len(rec.calls()) == 2 }, time.Second, time.Millisecond)
+	require.Eventually(t, func() bool { return p.Status()[0].TradesSeen == 3 }, time.Second, time.Millisecond)
+	// Counting trade 2 twice would give 4064 / 50 = 81.28
+	assert.InDelta(t, 3252.0/40, p.Status()[0].SessionVWAP, 1e-9)
+}
+
+func TestPoller_StopsOnCancel(t *testing.T) {
+	p, rec, _ := newTestPoller(t, "usdtrub")
+
+	cancel, done := runPoller(t, p)
+	require.Eventually(t, func() bool { return len(rec.calls()) == 1 }, time.Second, time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("poller did not stop after cancellation")
+	}
+}
+
+func TestPoller_PauseAndResume(t *testing.T) {
+	p, rec, ticks := newTestPoller(t, "usdtrub")
+
+	runPoller(t, p)
+	require.Eventually(t, func() bool { return len(rec.calls()) == 1 }, time.Second, time.Millisecond)
+
+	assert.True(t, p.Pause())
+	assert.False(t, p.Pause(), "already paused")
+	assert.True(t, p.Paused())
+
+	// The pending tick wakes the loop, which sees the pause and stops ticking instead of polling
+	ticks <- time.Now()
+	select {
+	case ticks <- time.Now():
+		t.Fatal("paused poller still reads ticks")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Len(t, rec.calls(), 1, "no polls while paused")
+
+	// Resuming polls right away and then on every tick again
+	assert.True(t, p.Resume())
+	assert.False(t, p.Resume(), "not paused")
+	assert.False(t, p.Paused())
+
+	ticks <- time.Now()
+	require.Eventually(t, func() bool { return len(rec.calls()) == 3 }, time.Second, time.Millisecond)
+}
+
+func TestPoller_StopsWhilePaused(t *testing.T) {
+	p, rec, _ := newTestPoller(t, "usdtrub")
+	p.Pause()
+
+	cancel, done := runPoller(t, p)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("paused poller did not stop after cancellation")
+	}
+	assert.Empty(t, rec.calls()) // Paused before the first poll
+}
+
+func TestPoller_RealTicker(t *testing.T) {
+	var polls atomic.Int32
+	grinexSvc := service.NewGrinexService(&service.GrinexConfig{}, zap.NewNop())
+	p, err := New(grinexSvc, func(context.Context, string) (*service.Rate, error) {
+		polls.Add(1)
+		return &service.Rate{}, nil
+	}, Config{Interval: 10 * time.Millisecond, Pairs: []string{"usdtrub"}}, zap.NewNop())
+	require.NoError(t, err)
+
+	runPoller(t, p)
+	require.Eventually(t, func() bool { return polls.Load() >= 3 }, time.Second, 5*time.Millisecond)
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	grinexSvc := service.NewGrinexService(&service.GrinexConfig{}, zap.NewNop())
+
+	for _, config := range []Config{
+		{Interval: 0, Pairs: []string{"usdtrub"}},
+		{Interval: time.Second},
+		{Interval: time.Second, Pairs: []string{"DOGE/USD"}},
+		{Interval: time.Second, Pairs: []string{"usdtrub", "btcrub"}, AllowedMarkets: []string{"USDTRUB"}},
+	} {
+		_, err := New(grinexSvc, nil, config, zap.NewNop())
+		assert.Error(t, err, "config %+v", config)
+	}
+}
+
+func TestNew_AllowedMarkets(t *testing.T) {
+	grinexSvc := service.NewGrinexService(&service.GrinexConfig{}, zap.NewNop())
+
+	p, err := New(grinexSvc, nil, Config{
+		Interval:       time.Second,
+		Pairs:          []string{"USDT/RUB"},
+		AllowedMarkets: []string{"USDTRUB"},
+	}, zap.NewNop())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"usdtrub"}, p.markets)
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type attemptCounterKey struct{}
+
+// AttemptCounter counts the HTTP requests sent to Grinex on behalf of a context, including
+// hedged requests and extra trade pages
+type AttemptCounter struct {
+	n atomic.Int32
+}
+
+// WithAttemptCounter returns a context whose Grinex requests are counted by the returned counter
+func WithAttemptCounter(ctx context.Context) (context.Context, *AttemptCounter) {
+	counter := &AttemptCounter{}
+	return context.WithValue(ctx, attemptCounterKey{}, counter), counter
+}
+
+// Count returns the number of requests sent so far
+func (c *AttemptCounter) Count() int {
+	return int(c.n.Load())
+}
+
+// countAttempt increments the counter of ctx, if any
+func countAttempt(ctx context.Context) {
+	if counter, ok := ctx.Value(attemptCounterKey{}).(*AttemptCounter); ok {
+		counter.n.Add(1)
+	}
+}
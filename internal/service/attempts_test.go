@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAttemptCounter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"id": 1, "price": "81.25", "created_at": "2025-07-28T21:22:14+03:00"}]`))
+	}))
+	defer server.Close()
+
+	service := NewGrinexService(&GrinexConfig{BaseURL: server.URL, Timeout: 5 * time.Second}, zap.NewNop())
+
+	ctx, attempts := WithAttemptCounter(context.Background())
+	_, err := service.GetUSDTRate(ctx)
+	require.NoError(t, err)
+	_, err = service.LatestTradeTime(ctx, USDTMarket)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, attempts.Count())
+
+	// Requests without a counter are not counted anywhere
+	_, err = service.GetUSDTRate(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts.Count())
+}
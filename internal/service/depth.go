@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// orderBookRateLevels is the number of levels per side fetched for order book rates, of which
+// only the best is used
+const orderBookRateLevels = 10
+
+// executionPriceLevels is the number of levels per side fetched to fill an execution price
+const executionPriceLevels = 200
+
+// Sides of a trade whose execution price is quoted: a buy fills against the asks and a sell
+// against the bids
+const (
+	SideBuy  = "buy"
+	SideSell = "sell"
+)
+
+// ErrInsufficientDepth is returned when the order book is too thin to fill the requested size
+var ErrInsufficientDepth = errors.New("insufficient order book depth")
+
+// DepthLevel is a single price level of the order book
+type DepthLevel struct {
+	Price  float64
+	Volume float64
+}
+
+// Depth is an order book snapshot of a market, asks ascending and bids descending by price
+type Depth struct {
+	TradingPair string
+	Asks        []DepthLevel
+	Bids        []DepthLevel
+	Timestamp   time.Time
+}
+
+// grinexDepth is the response of the Grinex depth endpoint, levels being [price, volume] pairs
+type grinexDepth struct {
+	Timestamp int64       `json:"timestamp"`
+	Asks      [][2]string `json:"asks"`
+	Bids      [][2]string `json:"bids"`
+}
+
+// GetDepth returns the order book of a market with up to limit levels per side. Snapshots are
+// cached for DepthCacheTTL per market and limit, so bursts of requests hit Grinex once.
+func (g *GrinexService) GetDepth(ctx context.Context, market string, limit int) (*Depth, error) {
+	key := strings.ToLower(market) + "/" + strconv.Itoa(limit)
+	if depth, ok := g.depthCache.get(key, g.config.DepthCacheTTL); ok {
+		return depth, nil
+	}
+
+	query := url.Values{}
+	query.Add("market", market)
+	query.Add("limit", strconv.Itoa(limit))
+
+	g.logger.Info("Fetching depth from Grinex", zap.String("url", g.config.BaseURL+"/api/v2/depth?"+query.Encode()))
+
+	body, err := g.get(ctx, "/api/v2/depth", query)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw grinexDepth
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	asks, err := parseDepthLevels(raw.Asks)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ask level: %w", err)
+	}
+	bids, err := parseDepthLevels(raw.Bids)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bid level: %w", err)
+	}
+
+	// Sort rather than trust the endpoint's order, the best levels come first
+	sort.SliceStable(asks, func(i, j int) bool { return asks[i].Price < asks[j].Price })
+	sort.SliceStable(bids, func(i, j int) bool { return bids[i].Price > bids[j].Price })
+
+	timestamp := time.Now()
+	if raw.Timestamp > 0 {
+		timestamp = time.Unix(raw.Timestamp, 0)
+	}
+
+	depth := &Depth{
+		TradingPair: g.PairLabel(market),
+		Asks:        firstLevels(asks, limit),
+		Bids:        firstLevels(bids, limit),
+		Timestamp:   timestamp,
+	}
+	g.depthCache.put(key, depth, g.config.DepthCacheTTL)
+
+	return depth, nil
+}
+
+// GetUSDTRateFromOrderBook fetches the current USDT rate from the top of the Grinex order book
+func (g *GrinexService) GetUSDTRateFromOrderBook(ctx context.Context) (*Rate, error) {
+	return g.GetRateFromOrderBook(ctx, USDTMarket)
+}
+
+// GetRateFromOrderBook returns the best ask and bid of a market's order book as its rate, so the
+// spread is one that actually existed. When either side of the book is empty it falls back to
+// the rate computed from recent trades.
+func (g *GrinexService) GetRateFromOrderBook(ctx context.Context, market string) (*Rate, error) {
+	depth, err := g.GetDepth(ctx, market, orderBookRateLevels)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(depth.Asks) == 0 || len(depth.Bids) == 0 {
+		g.logger.Warn("Order book side is empty, falling back to trades",
+			zap.String("market", market),
+			zap.Int("asks", len(depth.Asks)),
+			zap.Int("bids", len(depth.Bids)),
+		)
+		return g.GetRate(ctx, market)
+	}
+
+	askPrice, bidPrice := depth.Asks[0].Price, depth.Bids[0].Price
+	rate := &Rate{
+		TradingPair: depth.TradingPair,
+		AskPrice:    askPrice,
+		BidPrice:    bidPrice,
+		MidPrice:    (askPrice + bidPrice) / 2,
+		Timestamp:   truncateToBucket(depth.Timestamp, g.config.TimestampBucket),
+		Source:      SourceOrderBook,
+		Depth:       depth,
+	}
+	if g.config.KeepRawTimestamp {
+		rate.RawTimestamp = depth.Timestamp
+	}
+
+	g.logger.Info("Successfully fetched rate from order book",
+		zap.String("market", market),
+		zap.Float64("ask_price", rate.AskPrice),
+		zap.Float64("bid_price", rate.BidPrice),
+		zap.Float64("mid_price", rate.MidPrice),
+		zap.Time("timestamp", rate.Timestamp),
+	)
+
+	return rate, nil
+}
+
+// GetExecutionPrice returns the volume weighted average price of filling size of a market on
+// side, walking the order book from the best level until size is met. It returns
+// ErrInsufficientDepth when the fetched levels don't hold size.
+func (g *GrinexService) GetExecutionPrice(ctx context.Context, market string, side string, size float64) (float64, error) {
+	if size <= 0 {
+		return 0, fmt.Errorf("size must be positive, got %v", size)
+	}
+	if side != SideBuy && side != SideSell {
+		return 0, fmt.Errorf("side must be %s or %s, got %q", SideBuy, SideSell, side)
+	}
+
+	depth, err := g.GetDepth(ctx, market, executionPriceLevels)
+	if err != nil {
+		return 0, err
+	}
+
+	levels := depth.Asks
+	if side == SideSell {
+		levels = depth.Bids
+	}
+	return executionPrice(levels, size)
+}
+
+// executionPrice fills size from levels in order and returns the average fill price
+func executionPrice(levels []DepthLevel, size float64) (float64, error) {
+	var funds, filled float64
+	for _, level := range levels {
+		volume := min(level.Volume, size-filled)
+		if volume <= 0 {
+			continue
+		}
+		funds += level.Price * volume
+		filled += volume
+		if filled >= size {
+			return funds / filled, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: filled %v of %v", ErrInsufficientDepth, filled, size)
+}
+
+// parseDepthLevels converts [price, volume] pairs to levels
+func parseDepthLevels(raw [][2]string) ([]DepthLevel, error) {
+	levels := make([]DepthLevel, 0, len(raw))
+	for _, level := range raw {
+		price, err := strconv.ParseFloat(level[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse price %q: %w", level[0], err)
+		}
+		volume, err := strconv.ParseFloat(level[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse volume %q: %w", level[1], err)
+		}
+		levels = append(levels, DepthLevel{Price: price, Volume: volume})
+	}
+	return levels, nil
+}
+
+// firstLevels returns up to limit levels, a non-positive limit keeping all
+func firstLevels(levels []DepthLevel, limit int) []DepthLevel {
+	if limit > 0 && len(levels) > limit {
+		return levels[:limit]
+	}
+	return levels
+}
+
+type cachedDepth struct {
+	depth     *Depth
+	fetchedAt time.Time
+}
+
+// depthCache keeps recent depth snapshots in memory. Entries are replaced rather than evicted,
+// which is fine for the handful of markets and limits clients ask for.
+type depthCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedDepth
+}
+
+func newDepthCache() *depthCache {
+	return &depthCache{entries: make(map[string]cachedDepth)}
+}
+
+// get returns the snapshot cached under key if it is younger than ttl, a non-positive ttl disabling the cache
+func (c *depthCache) get(key string, ttl time.Duration) (*Depth, bool) {
+	if ttl <= 0 {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Since(entry.fetchedAt) >= ttl {
+		return nil, false
+	}
+	return entry.depth, true
+}
+
+func (c *depthCache) put(key string, depth *Depth, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = cachedDepth{depth: depth, fetchedAt: time.Now()}
+}
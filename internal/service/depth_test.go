@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestParseDepthLevels(t *testing.T) {
+	raw := [][2]string{{"81.30", "100"}, {"81.35", "250.5"}, {"81.40", "500"}}
+
+	levels, err := parseDepthLevels(raw)
+	require.NoError(t, err)
+	assert.Equal(t, []DepthLevel{{Price: 81.30, Volume: 100}, {Price: 81.35, Volume: 250.5}, {Price: 81.40, Volume: 500}}, levels)
+
+	assert.Len(t, firstLevels(levels, 2), 2)
+	assert.Len(t, firstLevels(levels, 0), 3)
+
+	_, err = parseDepthLevels([][2]string{{"81.30", "lots"}})
+	assert.ErrorContains(t, err, "failed to parse volume")
+}
+
+func TestDepthCache(t *testing.T) {
+	cache := newDepthCache()
+	depth := &Depth{TradingPair: "USDT/RUB"}
+
+	cache.put("usdtrub/20", depth, time.Minute)
+	cached, ok := cache.get("usdtrub/20", time.Minute)
+	assert.True(t, ok)
+	assert.Same(t, depth, cached)
+
+	_, ok = cache.get("usdtrub/10", time.Minute)
+	assert.False(t, ok)
+
+	// A TTL shorter than the entry's age treats it as expired
+	time.Sleep(5 * time.Millisecond)
+	_, ok = cache.get("usdtrub/20", time.Millisecond)
+	assert.False(t, ok)
+
+	// A zero TTL disables caching altogether
+	cache.put("btcrub/20", depth, 0)
+	_, ok = cache.get("btcrub/20", time.Minute)
+	assert.False(t, ok)
+}
+
+// newOrderBookServer fakes Grinex with the given depth payload and testTrades for the trades endpoint
+func newOrderBookServer(t *testing.T, depth string) *GrinexService {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v2/depth":
+			w.Write([]byte(depth))
+		case "/api/v2/trades":
+			w.Write([]byte(`[
+				{"id": 2, "price": "81.25", "market": "usdtrub", "created_at": "2025-07-28T21:22:14+03:00"},
+				{"id": 1, "price": "81.20", "market": "usdtrub", "created_at": "2025-07-28T21:19:53+03:00"}
+			]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return NewGrinexService(&GrinexConfig{BaseURL: server.URL, Timeout: 5 * time.Second}, zap.NewNop())
+}
+
+func TestGetRateFromOrderBook_UnsortedLevels(t *testing.T) {
+	service := newOrderBookServer(t, `{
+		"timestamp": 1753726800,
+		"asks": [["81.40", "5"], ["81.30", "10"], ["81.35", "7"]],
+		"bids": [["81.10", "3"], ["81.22", "1"], ["81.15", "8"]]
+	}`)
+
+	rate, err := service.GetUSDTRateFromOrderBook(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "USDT/RUB", rate.TradingPair)
+	assert.Equal(t, 81.30, rate.AskPrice)
+	assert.Equal(t, 81.22, rate.BidPrice)
+	assert.InDelta(t, 81.26, rate.MidPrice, 1e-9)
+	assert.Equal(t, SourceOrderBook, rate.Source)
+	assert.True(t, time.Unix(1753726800, 0).Equal(rate.Timestamp))
+	require.NotNil(t, rate.Depth, "the book is kept for storing its levels")
+	assert.Equal(t, []DepthLevel{{Price: 81.30, Volume: 10}, {Price: 81.35, Volume: 7}, {Price: 81.40, Volume: 5}}, rate.Depth.Asks)
+}
+
+func TestGetRateFromOrderBook_EmptySideFallsBackToTrades(t *testing.T) {
+	tests := map[string]string{
+		"empty asks": `{"asks": [], "bids": [["81.22", "1"]]}`,
+		"empty bids": `{"asks": [["81.30", "10"]], "bids": []}`,
+		"empty book": `{}`,
+	}
+
+	for name, depth := range tests {
+		t.Run(name, func(t *testing.T) {
+			service := newOrderBookServer(t, depth)
+
+			rate, err := service.GetUSDTRateFromOrderBook(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, 81.25, rate.AskPrice)
+			assert.Equal(t, 81.20, rate.BidPrice)
+			assert.Equal(t, SourceTrades, rate.Source)
+			assert.Nil(t, rate.Depth)
+		})
+	}
+}
+
+// executionDepth is a sample book with unsorted levels, asks 81.30 x 10, 81.35 x 20, 81.40 x 30
+// and bids 81.20 x 5, 81.15 x 15, 81.10 x 25 once sorted
+const executionDepth = `{
+	"asks": [["81.40", "30"], ["81.30", "10"], ["81.35", "20"]],
+	"bids": [["81.10", "25"], ["81.20", "5"], ["81.15", "15"]]
+}`
+
+func TestGetExecutionPrice(t *testing.T) {
+	tests := map[string]struct {
+		side     string
+		size     float64
+		expected float64
+	}{
+		"buy within the best level":  {side: SideBuy, size: 4, expected: 81.30},
+		"buy across two levels":      {side: SideBuy, size: 20, expected: (81.30*10 + 81.35*10) / 20},
+		"buy the whole book":         {side: SideBuy, size: 60, expected: (81.30*10 + 81.35*20 + 81.40*30) / 60},
+		"sell within the best level": {side: SideSell, size: 5, expected: 81.20},
+		"sell across three levels":   {side: SideSell, size: 30, expected: (81.20*5 + 81.15*15 + 81.10*10) / 30},
+		"sell a fraction of a level": {side: SideSell, size: 7.5, expected: (81.20*5 + 81.15*2.5) / 7.5},
+	}
+
+	service := newOrderBookServer(t, executionDepth)
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			price, err := service.GetExecutionPrice(context.Background(), USDTMarket, tt.side, tt.size)
+
+			require.NoError(t, err)
+			assert.InDelta(t, tt.expected, price, 1e-9)
+		})
+	}
+}
+
+func TestGetExecutionPrice_BookTooThin(t *testing.T) {
+	service := newOrderBookServer(t, executionDepth)
+
+	_, err := service.GetExecutionPrice(context.Background(), USDTMarket, SideBuy, 61)
+	assert.ErrorIs(t, err, ErrInsufficientDepth)
+
+	_, err = service.GetExecutionPrice(context.Background(), USDTMarket, SideSell, 45.5)
+	assert.ErrorIs(t, err, ErrInsufficientDepth)
+}
+
+func TestGetExecutionPrice_InvalidArguments(t *testing.T) {
+	service := newOrderBookServer(t, executionDepth)
+
+	_, err := service.GetExecutionPrice(context.Background(), USDTMarket, "hold", 10)
+	assert.ErrorContains(t, err, "side must be buy or sell")
+
+	_, err = service.GetExecutionPrice(context.Background(), USDTMarket, SideBuy, 0)
+	assert.ErrorContains(t, err, "size must be positive")
+}
@@ -3,31 +3,134 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
+	"mime"
+	"net"
 	"net/http"
+	"net/url"
 	"sort"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
 )
 
+const (
+	// USDTMarket is the Grinex market symbol for USDT/RUB
+	USDTMarket = "usdtrub"
+	// tradesPageLimit is the maximum number of trades the trades endpoint returns per request
+	tradesPageLimit = 1000
+	// defaultTradesLimit is the number of recent trades fetched when no limit is configured
+	defaultTradesLimit = 100
+)
+
+// HTTP protocol versions used to talk to Grinex
+const (
+	// HTTPVersionAuto negotiates HTTP/2 over TLS and falls back to HTTP/1.1
+	HTTPVersionAuto = "auto"
+	// HTTPVersion1 restricts requests to HTTP/1.1, for proxies that misbehave with HTTP/2
+	HTTPVersion1 = "1.1"
+	// HTTPVersion2 requires HTTP/2, using prior knowledge h2c for plain http base URLs
+	HTTPVersion2 = "2"
+)
+
 // GrinexConfig holds configuration for the Grinex API
 type GrinexConfig struct {
 	BaseURL   string
 	UserAgent string
 	Timeout   time.Duration
+	// PairLabels maps Grinex market symbols to trading pair labels
+	PairLabels map[string]string
+	// PriceStrategy computes prices from trades, defaults to ExtremesStrategy
+	PriceStrategy PriceStrategy
+	// HedgeDelay fires a second request if the first one hasn't completed in time, zero disables hedging
+	HedgeDelay time.Duration
+	// TimestampTrades is the number of newest trades whose median time is used as the rate timestamp
+	TimestampTrades int
+	// TradesLimit is the number of recent trades a rate is computed from, defaults to 100
+	TradesLimit int
+	// MaxTradesLimit caps TradesLimit for every market, zero disables the cap
+	MaxTradesLimit int
+	// MarketMaxTradesLimits caps TradesLimit per market symbol, overriding MaxTradesLimit
+	MarketMaxTradesLimits map[string]int
+	// HealthMaxTradeAge fails the health check when the newest trade is older, zero disables the check
+	HealthMaxTradeAge time.Duration
+	// HTTPVersion selects the HTTP protocol version, defaults to HTTPVersionAuto
+	HTTPVersion string
+	// MinTrades is the number of trades with a valid price a rate needs, zero or one accepts a single trade
+	MinTrades int
+	// TimestampBucket truncates rate timestamps to a multiple of this duration, zero keeps them as is
+	TimestampBucket time.Duration
+	// KeepRawTimestamp keeps the timestamp before bucketing in Rate.RawTimestamp
+	KeepRawTimestamp bool
+	// ConnectTimeout bounds dialing Grinex separately from Timeout, zero keeps the default dialer
+	ConnectTimeout time.Duration
+	// TLSHandshakeTimeout bounds the TLS handshake, zero keeps the default transport value
+	TLSHandshakeTimeout time.Duration
+	// BodyReadTimeout bounds reading a response body once the headers arrived, zero disables the bound
+	BodyReadTimeout time.Duration
+	// CheckContentType rejects successful responses whose Content-Type is not JSON before decoding them
+	CheckContentType bool
+	// DepthCacheTTL is how long depth snapshots are served from memory, zero disables the cache
+	DepthCacheTTL time.Duration
+	// Meter records the client metrics, defaults to the global meter provider
+	Meter otelmetric.Meter
 }
 
+// ErrInsufficientTrades is returned when a market has fewer valid recent trades than MinTrades
+var ErrInsufficientTrades = errors.New("insufficient trades")
+
+// ErrBodyReadTimeout is returned when Grinex sent the response headers but the body did not
+// arrive within BodyReadTimeout. The request is safe to retry.
+var ErrBodyReadTimeout = errors.New("timed out reading Grinex response body")
+
+// ErrUnexpectedContentType is returned with CheckContentType when a successful response is not
+// JSON, e.g. an HTML page served with status 200 by a captive portal or WAF
+var ErrUnexpectedContentType = errors.New("unexpected Grinex response content type")
+
+// contentTypeSnippetSize is how much of a non-JSON body ErrUnexpectedContentType errors quote
+const contentTypeSnippetSize = 200
+
 // Rate represents a trading rate from Grinex
 type Rate struct {
 	TradingPair string
 	AskPrice    float64
 	BidPrice    float64
-	Timestamp   time.Time
+	MidPrice    float64
+	// VWAP is the volume weighted average price of the trades the rate was computed from, zero
+	// for rates taken from the order book
+	VWAP      float64
+	Timestamp time.Time
+	// RawTimestamp is the timestamp before TimestampBucket was applied, set with KeepRawTimestamp
+	RawTimestamp time.Time
+	// Source is the kind of Grinex data the rate was computed from, e.g. SourceTrades
+	Source string
+	// IngestedAt is when the service obtained the rate, stored as created_at. It is left for the
+	// caller to set.
+	IngestedAt time.Time
+	// Depth is the order book the rate was taken from, nil for rates computed from trades
+	Depth *Depth
+	// Trades are the recent trades the rate was computed from, nil for rates taken from the
+	// order book
+	Trades []GrinexTrade
 }
 
+// Kinds of Grinex data a rate can be computed from
+const (
+	// SourceTrades marks rates computed from recent trades
+	SourceTrades = "trades"
+	// SourceOrderBook marks rates taken from the best ask and bid of the order book
+	SourceOrderBook = "order_book"
+)
+
 // GrinexTrade represents a trade from Grinex API
 type GrinexTrade struct {
 	ID        int64  `json:"id"`
@@ -40,91 +143,158 @@ type GrinexTrade struct {
 }
 
 type GrinexService struct {
-	config *GrinexConfig
-	client *http.Client
-	logger *zap.Logger
+	config           *GrinexConfig
+	client           *http.Client
+	strategy         PriceStrategy
+	depthCache       *depthCache
+	bodyReadDuration otelmetric.Float64Histogram
+	requestDuration  otelmetric.Float64Histogram
+	requestFailures  otelmetric.Int64Counter
+	logger           *zap.Logger
 }
 
 func NewGrinexService(config *GrinexConfig, logger *zap.Logger) *GrinexService {
+	transport := newTransport(config.HTTPVersion)
+	setConnectTimeouts(transport, config.ConnectTimeout, config.TLSHandshakeTimeout)
 	client := &http.Client{
-		Timeout: config.Timeout,
+		Timeout:   config.Timeout,
+		Transport: transport,
 	}
 
-	return &GrinexService{
-		config: config,
-		client: client,
-		logger: logger,
+	strategy := config.PriceStrategy
+	if strategy == nil {
+		strategy = ExtremesStrategy{}
 	}
-}
 
-// GetUSDTRate fetches the current USDT rate from Grinex using recent trades
-func (g *GrinexService) GetUSDTRate(ctx context.Context) (*Rate, error) {
-	url := fmt.Sprintf("%s/api/v2/trades", g.config.BaseURL)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	meter := config.Meter
+	if meter == nil {
+		meter = otel.Meter("grinex-rate-service")
+	}
+	bodyReadDuration, err := meter.Float64Histogram("grinex_body_read_duration",
+		otelmetric.WithDescription("Time spent reading Grinex response bodies after the headers arrived"),
+		otelmetric.WithUnit("s"))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		logger.Warn("Failed to create body read duration metric", zap.Error(err))
+	}
+	requestDuration, err := meter.Float64Histogram("grinex_request_duration",
+		otelmetric.WithDescription("Duration of Grinex API requests from sending to reading the whole body"),
+		otelmetric.WithUnit("s"))
+	if err != nil {
+		logger.Warn("Failed to create request duration metric", zap.Error(err))
+	}
+	requestFailures, err := meter.Int64Counter("grinex_request_failures",
+		otelmetric.WithDescription("Grinex API requests that failed or returned a non-200 status"))
+	if err != nil {
+		logger.Warn("Failed to create request failures metric", zap.Error(err))
 	}
 
-	// Add query parameters for USDT/RUB market
-	q := req.URL.Query()
-	q.Add("market", "usdtrub")
-	q.Add("limit", "100")
-	req.URL.RawQuery = q.Encode()
+	return &GrinexService{
+		config:           config,
+		client:           client,
+		strategy:         strategy,
+		depthCache:       newDepthCache(),
+		bodyReadDuration: bodyReadDuration,
+		requestDuration:  requestDuration,
+		requestFailures:  requestFailures,
+		logger:           logger,
+	}
+}
 
-	req.Header.Set("User-Agent", g.config.UserAgent)
-	req.Header.Set("Accept", "application/json")
+// newTransport returns an HTTP transport restricted to the given protocol version.
+// Unknown versions behave like HTTPVersionAuto.
+func newTransport(version string) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	switch version {
+	case HTTPVersion1:
+		transport.ForceAttemptHTTP2 = false
+		transport.Protocols = new(http.Protocols)
+		transport.Protocols.SetHTTP1(true)
+	case HTTPVersion2:
+		transport.Protocols = new(http.Protocols)
+		transport.Protocols.SetHTTP2(true)
+		transport.Protocols.SetUnencryptedHTTP2(true)
+	}
 
-	g.logger.Info("Fetching USDT rate from Grinex", zap.String("url", req.URL.String()))
+	return transport
+}
 
-	resp, err := g.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+// setConnectTimeouts makes the transport give up on unreachable hosts after connectTimeout and on
+// stalled TLS handshakes after tlsHandshakeTimeout, while the client Timeout still bounds the
+// whole request. Zero values keep the transport defaults.
+func setConnectTimeouts(transport *http.Transport, connectTimeout, tlsHandshakeTimeout time.Duration) {
+	if connectTimeout > 0 {
+		dialer := &net.Dialer{
+			Timeout:   connectTimeout,
+			KeepAlive: 30 * time.Second,
+		}
+		transport.DialContext = dialer.DialContext
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	if tlsHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = tlsHandshakeTimeout
 	}
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
+// WithBaseURL returns a copy of the service pointing at another Grinex base URL.
+// The copy shares the HTTP client and price strategy with the original but caches depth
+// separately.
+func (g *GrinexService) WithBaseURL(baseURL string) *GrinexService {
+	config := *g.config
+	config.BaseURL = strings.TrimRight(baseURL, "/")
+
+	clone := *g
+	clone.config = &config
+	clone.depthCache = newDepthCache()
+	return &clone
+}
 
-	var trades []GrinexTrade
-	if err := json.Unmarshal(body, &trades); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+// GetUSDTRate fetches the current USDT rate from Grinex using recent trades
+func (g *GrinexService) GetUSDTRate(ctx context.Context) (*Rate, error) {
+	return g.GetRate(ctx, USDTMarket)
+}
+
+// GetRate fetches the current rate of a Grinex market using recent trades
+func (g *GrinexService) GetRate(ctx context.Context, market string) (*Rate, error) {
+	trades, err := g.recentTrades(ctx, market, g.tradesLimit(market))
+	if err != nil {
+		return nil, err
 	}
 
 	if len(trades) == 0 {
-		return nil, fmt.Errorf("no trades data available")
+		return nil, fmt.Errorf("no trades data available for %s", market)
+	}
+	if valid := len(parseTradePrices(trades)); valid < g.config.MinTrades {
+		return nil, fmt.Errorf("%w: %s has %d valid trades, %d required", ErrInsufficientTrades, market, valid, g.config.MinTrades)
 	}
 
 	// Calculate ask and bid prices from recent trades
-	askPrice, bidPrice, err := g.calculatePricesFromTrades(trades)
+	askPrice, bidPrice, midPrice, err := g.calculatePricesFromTrades(trades)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate prices from trades: %w", err)
 	}
 
-	// Get the latest trade timestamp
-	latestTrade := trades[0] // Assuming trades are sorted by time descending
-	timestamp, err := time.Parse(time.RFC3339, latestTrade.CreatedAt)
-	if err != nil {
-		timestamp = time.Now() // Fallback to current time
-	}
+	timestamp := g.rateTimestamp(trades)
 
 	rate := &Rate{
-		TradingPair: "USDT/RUB",
+		TradingPair: g.PairLabel(market),
 		AskPrice:    askPrice,
 		BidPrice:    bidPrice,
-		Timestamp:   timestamp,
+		MidPrice:    midPrice,
+		VWAP:        TradesVWAP(trades, askPrice),
+		Timestamp:   truncateToBucket(timestamp, g.config.TimestampBucket),
+		Source:      SourceTrades,
+		Trades:      trades,
+	}
+	if g.config.KeepRawTimestamp {
+		rate.RawTimestamp = timestamp
 	}
 
-	g.logger.Info("Successfully fetched USDT rate",
+	g.logger.Info("Successfully fetched rate",
+		zap.String("market", market),
 		zap.Float64("ask_price", rate.AskPrice),
 		zap.Float64("bid_price", rate.BidPrice),
+		zap.Float64("mid_price", rate.MidPrice),
+		zap.Float64("vwap", rate.VWAP),
 		zap.Time("timestamp", rate.Timestamp),
 		zap.Int("trades_count", len(trades)),
 	)
@@ -132,40 +302,394 @@ func (g *GrinexService) GetUSDTRate(ctx context.Context) (*Rate, error) {
 	return rate, nil
 }
 
-// calculatePricesFromTrades calculates ask and bid prices from recent trades
-func (g *GrinexService) calculatePricesFromTrades(trades []GrinexTrade) (askPrice, bidPrice float64, err error) {
+// tradesLimit returns the effective number of recent trades to fetch for a market,
+// capped by the market's own limit or else the global one
+func (g *GrinexService) tradesLimit(market string) int {
+	limit := g.config.TradesLimit
+	if limit <= 0 {
+		limit = defaultTradesLimit
+	}
+
+	maxLimit := g.config.MaxTradesLimit
+	if marketMax, ok := g.config.MarketMaxTradesLimits[strings.ToLower(market)]; ok {
+		maxLimit = marketMax
+	}
+	if maxLimit > 0 && limit > maxLimit {
+		g.logger.Warn("Capping trades limit",
+			zap.String("market", market),
+			zap.Int("limit", limit),
+			zap.Int("max_limit", maxLimit),
+		)
+		limit = maxLimit
+	}
+	return limit
+}
+
+// recentTrades fetches the newest limit trades of a market, newest first. Limits above the
+// endpoint's page size are fetched page by page with the to cursor, stopping as soon as
+// enough trades are collected.
+func (g *GrinexService) recentTrades(ctx context.Context, market string, limit int) ([]GrinexTrade, error) {
+	if limit <= tradesPageLimit {
+		query := url.Values{}
+		query.Add("market", market)
+		query.Add("limit", strconv.Itoa(limit))
+
+		g.logger.Info("Fetching recent trades from Grinex", zap.String("url", g.config.BaseURL+"/api/v2/trades?"+query.Encode()))
+
+		return g.tradesPage(ctx, query)
+	}
+
+	g.logger.Info("Fetching recent trades from Grinex in pages", zap.String("market", market), zap.Int("limit", limit))
+
+	trades := make([]GrinexTrade, 0, limit)
+	var cursor int64 // The to cursor is exclusive, zero means the newest trades
+	for len(trades) < limit {
+		query := url.Values{}
+		query.Add("market", market)
+		query.Add("limit", strconv.Itoa(min(limit-len(trades), tradesPageLimit)))
+		query.Add("order_by", "desc")
+		if cursor > 0 {
+			query.Add("to", strconv.FormatInt(cursor, 10))
+		}
+
+		page, err := g.tradesPage(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		previous := cursor
+		for _, trade := range page {
+			if cursor == 0 || trade.ID < cursor {
+				cursor = trade.ID
+			}
+		}
+		trades = append(trades, page...)
+
+		// Stop when trades run out, and if the cursor didn't move to avoid looping forever
+		if len(page) < tradesPageLimit || cursor == previous {
+			break
+		}
+	}
+
+	if len(trades) > limit {
+		trades = trades[:limit]
+	}
+	return trades, nil
+}
+
+// LatestTradeTime returns the creation time of the newest trade of a market
+func (g *GrinexService) LatestTradeTime(ctx context.Context, market string) (time.Time, error) {
+	query := url.Values{}
+	query.Add("market", market)
+	query.Add("limit", "1")
+
+	trades, err := g.tradesPage(ctx, query)
+	if err != nil {
+		return time.Time{}, err
+	}
 	if len(trades) == 0 {
-		return 0, 0, fmt.Errorf("no trades to calculate prices from")
+		return time.Time{}, fmt.Errorf("no trades data available")
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, trades[0].CreatedAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse trade time: %w", err)
+	}
+	return timestamp, nil
+}
+
+// tradesPage fetches a single page of trades with the given query
+func (g *GrinexService) tradesPage(ctx context.Context, query url.Values) ([]GrinexTrade, error) {
+	body, err := g.get(ctx, "/api/v2/trades", query)
+	if err != nil {
+		return nil, err
 	}
 
-	// Sort trades by price to find highest (ask) and lowest (bid) recent prices
-	var prices []float64
+	var trades []GrinexTrade
+	if err := json.Unmarshal(body, &trades); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return trades, nil
+}
+
+// truncateToBucket rounds t down to a multiple of bucket since the zero time, so buckets of a
+// minute or an hour align to UTC boundaries. A non-positive bucket returns t unchanged.
+func truncateToBucket(t time.Time, bucket time.Duration) time.Time {
+	if bucket <= 0 {
+		return t
+	}
+	return t.Truncate(bucket)
+}
+
+// rateTimestamp returns the median creation time of the newest TimestampTrades trades, so a
+// single bogus timestamp can't skew the rate time. Trades are ordered by their parsed creation
+// time rather than trusting the order Grinex returned them in, and trades with an unparseable
+// time are skipped. Falls back to the current time when no trade time parses.
+func (g *GrinexService) rateTimestamp(trades []GrinexTrade) time.Time {
+	timestamps := make([]time.Time, 0, len(trades))
 	for _, trade := range trades {
-		price, err := strconv.ParseFloat(trade.Price, 64)
+		timestamp, err := time.Parse(time.RFC3339, trade.CreatedAt)
 		if err != nil {
-			g.logger.Warn("Failed to parse trade price", zap.String("price", trade.Price), zap.Error(err))
 			continue
 		}
-		prices = append(prices, price)
+		timestamps = append(timestamps, timestamp)
 	}
 
-	if len(prices) == 0 {
-		return 0, 0, fmt.Errorf("no valid prices found in trades")
+	if len(timestamps) == 0 {
+		g.logger.Warn("No trade has a valid creation time, using the current time as the rate timestamp",
+			zap.Int("trades_count", len(trades)),
+		)
+		return time.Now()
 	}
 
-	// Sort prices in descending order
-	sort.Sort(sort.Reverse(sort.Float64Slice(prices)))
+	// Newest first
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i].After(timestamps[j]) })
 
-	// Use the highest price as ask and lowest as bid
-	askPrice = prices[0]             // Highest price
-	bidPrice = prices[len(prices)-1] // Lowest price
+	k := g.config.TimestampTrades
+	if k < 1 {
+		k = 1
+	}
+	if k > len(timestamps) {
+		k = len(timestamps)
+	}
+	timestamps = timestamps[:k]
 
-	// If we have very few trades, use the same price for both
-	if len(prices) < 2 {
-		bidPrice = askPrice
+	mid := len(timestamps) / 2
+	if len(timestamps)%2 == 1 {
+		return timestamps[mid]
 	}
+	return timestamps[mid].Add(timestamps[mid-1].Sub(timestamps[mid]) / 2)
+}
+
+// calculatePricesFromTrades calculates ask, bid and mid prices from recent trades using the configured strategy
+func (g *GrinexService) calculatePricesFromTrades(trades []GrinexTrade) (askPrice, bidPrice, midPrice float64, err error) {
+	if len(trades) == 0 {
+		return 0, 0, 0, fmt.Errorf("no trades to calculate prices from")
+	}
+
+	// Strategies skip unparseable prices silently, so they are reported here
+	for _, trade := range trades {
+		if _, err := strconv.ParseFloat(trade.Price, 64); err != nil {
+			g.logger.Warn("Failed to parse trade price", zap.String("price", trade.Price), zap.Error(err))
+		}
+	}
+
+	return g.strategy.Compute(trades)
+}
+
+// GetTradesRange fetches all trades of a market with IDs in the inclusive range [fromID, toID],
+// following the trades endpoint's from/to cursors page by page in ascending ID order
+func (g *GrinexService) GetTradesRange(ctx context.Context, market string, fromID, toID int64) ([]GrinexTrade, error) {
+	if market == "" {
+		return nil, fmt.Errorf("market is required")
+	}
+	if fromID > toID {
+		return nil, fmt.Errorf("invalid trade ID range: from %d is greater than to %d", fromID, toID)
+	}
+
+	trades := make([]GrinexTrade, 0)
+	cursor := fromID - 1 // The from cursor is exclusive
+	for {
+		previous := cursor
+
+		query := url.Values{}
+		query.Add("market", market)
+		query.Add("from", strconv.FormatInt(cursor, 10))
+		if toID < math.MaxInt64 {
+			query.Add("to", strconv.FormatInt(toID+1, 10)) // The to cursor is exclusive as well
+		}
+		query.Add("limit", strconv.Itoa(tradesPageLimit))
+		query.Add("order_by", "asc")
+
+		body, err := g.get(ctx, "/api/v2/trades", query)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []GrinexTrade
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+
+		for _, trade := range page {
+			if trade.ID >= fromID && trade.ID <= toID {
+				trades = append(trades, trade)
+			}
+			if trade.ID > cursor {
+				cursor = trade.ID
+			}
+		}
+
+		// Stop on the last page, and if the cursor didn't move to avoid looping forever
+		if len(page) < tradesPageLimit || cursor >= toID || cursor == previous {
+			break
+		}
+	}
+
+	g.logger.Info("Fetched trades range from Grinex",
+		zap.String("market", market),
+		zap.Int64("from_id", fromID),
+		zap.Int64("to_id", toID),
+		zap.Int("trades_count", len(trades)),
+	)
+
+	return trades, nil
+}
 
-	return askPrice, bidPrice, nil
+type fetchResult struct {
+	body []byte
+	err  error
+}
+
+// get performs a GET request against the Grinex API and returns the response body.
+// When a hedge delay is configured and the first attempt has not completed within it,
+// a second identical request is fired and whichever succeeds first wins; the other is cancelled.
+func (g *GrinexService) get(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	if g.config.HedgeDelay <= 0 {
+		return g.fetch(ctx, path, query)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel() // Cancels the losing attempt
+
+	results := make(chan fetchResult, 2)
+	attempt := func() {
+		body, err := g.fetch(ctx, path, query)
+		results <- fetchResult{body: body, err: err}
+	}
+
+	go attempt()
+	inflight := 1
+	hedged := false
+
+	timer := time.NewTimer(g.config.HedgeDelay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			g.logger.Debug("Hedging Grinex request", zap.String("path", path), zap.Duration("delay", g.config.HedgeDelay))
+			hedged = true
+			inflight++
+			go attempt()
+		case res := <-results:
+			inflight--
+			if res.err == nil {
+				return res.body, nil
+			}
+			// A failure before the hedge fired is returned as is, hedging is not a retry
+			if !hedged || inflight == 0 {
+				return nil, res.err
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// fetch performs a single GET request against the Grinex API and returns the response body,
+// recording the request duration and any failure
+func (g *GrinexService) fetch(ctx context.Context, path string, query url.Values) (body []byte, err error) {
+	start := time.Now()
+	defer func() { g.recordRequest(path, time.Since(start), err) }()
+
+	return g.doFetch(ctx, path, query)
+}
+
+// recordRequest records the duration of a Grinex request and counts it as a failure if err is
+// set. Cancelled requests, e.g. the losing attempt of a hedged request, are not failures.
+func (g *GrinexService) recordRequest(path string, elapsed time.Duration, err error) {
+	failed := err != nil && !errors.Is(err, context.Canceled)
+	attrs := otelmetric.WithAttributes(attribute.String("path", path), attribute.Bool("error", failed))
+	if g.requestDuration != nil {
+		g.requestDuration.Record(context.Background(), elapsed.Seconds(), attrs)
+	}
+	if failed && g.requestFailures != nil {
+		g.requestFailures.Add(context.Background(), 1, otelmetric.WithAttributes(attribute.String("path", path)))
+	}
+}
+
+func (g *GrinexService) doFetch(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	// Cancelling the request context is what aborts a slow body read
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.config.BaseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.URL.RawQuery = query.Encode()
+
+	req.Header.Set("User-Agent", g.config.UserAgent)
+	req.Header.Set("Accept", "application/json")
+
+	countAttempt(ctx)
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+	if g.config.CheckContentType {
+		if err := checkJSONContentType(resp); err != nil {
+			return nil, err
+		}
+	}
+
+	return g.readBody(resp.Body, path, cancel)
+}
+
+// checkJSONContentType fails with ErrUnexpectedContentType, quoting the start of the body, when
+// the response declares a media type other than application/json or a +json type. A missing
+// Content-Type is accepted.
+func checkJSONContentType(resp *http.Response) error {
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")) {
+		return nil
+	}
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, contentTypeSnippetSize))
+	return fmt.Errorf("%w %q: %q", ErrUnexpectedContentType, contentType, strings.TrimSpace(string(snippet)))
+}
+
+// readBody reads a response body, cancelling the request through cancel when reading takes
+// longer than BodyReadTimeout, and records the read duration
+func (g *GrinexService) readBody(body io.Reader, path string, cancel context.CancelFunc) ([]byte, error) {
+	var timedOut atomic.Bool
+	if g.config.BodyReadTimeout > 0 {
+		timer := time.AfterFunc(g.config.BodyReadTimeout, func() {
+			timedOut.Store(true)
+			cancel()
+		})
+		defer timer.Stop()
+	}
+
+	start := time.Now()
+	data, err := io.ReadAll(body)
+	elapsed := time.Since(start)
+
+	if g.bodyReadDuration != nil {
+		g.bodyReadDuration.Record(context.Background(), elapsed.Seconds(),
+			otelmetric.WithAttributes(attribute.String("path", path), attribute.Bool("timed_out", timedOut.Load())))
+	}
+
+	if err != nil {
+		if timedOut.Load() {
+			return nil, fmt.Errorf("%w after %s", ErrBodyReadTimeout, g.config.BodyReadTimeout)
+		}
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return data, nil
 }
 
 // HealthCheck performs a health check on the Grinex API
@@ -189,5 +713,24 @@ func (g *GrinexService) HealthCheck(ctx context.Context) error {
 		return fmt.Errorf("health check failed with status %d", resp.StatusCode)
 	}
 
+	if g.config.HealthMaxTradeAge > 0 {
+		return g.checkTradeRecency(ctx)
+	}
+
+	return nil
+}
+
+// checkTradeRecency fails when the newest USDT trade is older than HealthMaxTradeAge,
+// which catches a halted market whose API still responds
+func (g *GrinexService) checkTradeRecency(ctx context.Context) error {
+	latest, err := g.LatestTradeTime(ctx, USDTMarket)
+	if err != nil {
+		return fmt.Errorf("failed to check trade recency: %w", err)
+	}
+
+	if age := time.Since(latest); age > g.config.HealthMaxTradeAge {
+		return fmt.Errorf("newest %s trade is %s old, exceeding %s", USDTMarket, age.Round(time.Second), g.config.HealthMaxTradeAge)
+	}
+
 	return nil
 }
@@ -2,14 +2,27 @@ package service
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestNewGrinexService(t *testing.T) {
@@ -91,6 +104,7 @@ func TestGetUSDTRate_Success(t *testing.T) {
 	assert.Equal(t, "USDT/RUB", rate.TradingPair)
 	assert.Equal(t, 81.30, rate.AskPrice) // Highest price
 	assert.Equal(t, 81.20, rate.BidPrice) // Lowest price
+	assert.InDelta(t, (243993.99+1257000.0+81300.0)/(3003.003+15470.7692+1000.0), rate.VWAP, 1e-9)
 
 	// Check that timestamp is parsed correctly from the first trade
 	expectedTime, _ := time.Parse(time.RFC3339, "2025-07-28T21:22:14+03:00")
@@ -219,9 +233,52 @@ func TestHealthCheck_HTTPError(t *testing.T) {
 	assert.Contains(t, err.Error(), "health check failed with status 500")
 }
 
+// newHealthServer fakes Grinex with a healthy markets endpoint and a newest trade created at tradeTime
+func newHealthServer(t *testing.T, tradeTime time.Time) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/trades" {
+			assert.Equal(t, "1", r.URL.Query().Get("limit"))
+			fmt.Fprintf(w, `[{"id": 1, "price": "81.25", "market": "usdtrub", "created_at": %q}]`, tradeTime.Format(time.RFC3339))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestHealthCheck_RecentTrade(t *testing.T) {
+	server := newHealthServer(t, time.Now().Add(-time.Minute))
+	defer server.Close()
+
+	service := NewGrinexService(&GrinexConfig{BaseURL: server.URL, Timeout: 30 * time.Second, HealthMaxTradeAge: 10 * time.Minute}, zap.NewNop())
+
+	assert.NoError(t, service.HealthCheck(context.Background()))
+}
+
+func TestHealthCheck_StaleTrade(t *testing.T) {
+	server := newHealthServer(t, time.Now().Add(-2*time.Hour))
+	defer server.Close()
+
+	service := NewGrinexService(&GrinexConfig{BaseURL: server.URL, Timeout: 30 * time.Second, HealthMaxTradeAge: 10 * time.Minute}, zap.NewNop())
+
+	err := service.HealthCheck(context.Background())
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "newest usdtrub trade is 2h0m")
+	assert.Contains(t, err.Error(), "old, exceeding 10m0s")
+}
+
+func TestHealthCheck_TradeAgeDisabled(t *testing.T) {
+	server := newHealthServer(t, time.Now().Add(-2*time.Hour))
+	defer server.Close()
+
+	service := NewGrinexService(&GrinexConfig{BaseURL: server.URL, Timeout: 30 * time.Second}, zap.NewNop())
+
+	assert.NoError(t, service.HealthCheck(context.Background()))
+}
+
 func TestCalculatePricesFromTrades(t *testing.T) {
 	logger := zap.NewNop()
-	service := &GrinexService{logger: logger}
+	service := NewGrinexService(&GrinexConfig{}, logger)
 
 	trades := []GrinexTrade{
 		{Price: "81.25"},
@@ -230,7 +287,7 @@ func TestCalculatePricesFromTrades(t *testing.T) {
 		{Price: "81.15"},
 	}
 
-	askPrice, bidPrice, err := service.calculatePricesFromTrades(trades)
+	askPrice, bidPrice, _, err := service.calculatePricesFromTrades(trades)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 81.30, askPrice) // Highest price
@@ -239,13 +296,13 @@ func TestCalculatePricesFromTrades(t *testing.T) {
 
 func TestCalculatePricesFromTrades_SinglePrice(t *testing.T) {
 	logger := zap.NewNop()
-	service := &GrinexService{logger: logger}
+	service := NewGrinexService(&GrinexConfig{}, logger)
 
 	trades := []GrinexTrade{
 		{Price: "81.25"},
 	}
 
-	askPrice, bidPrice, err := service.calculatePricesFromTrades(trades)
+	askPrice, bidPrice, _, err := service.calculatePricesFromTrades(trades)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 81.25, askPrice)
@@ -253,29 +310,753 @@ func TestCalculatePricesFromTrades_SinglePrice(t *testing.T) {
 }
 
 func TestCalculatePricesFromTrades_InvalidPrice(t *testing.T) {
-	logger := zap.NewNop()
-	service := &GrinexService{logger: logger}
+	core, logs := observer.New(zap.WarnLevel)
+	service := NewGrinexService(&GrinexConfig{}, zap.New(core))
 
 	trades := []GrinexTrade{
 		{Price: "invalid"},
 		{Price: "81.25"},
 	}
 
-	askPrice, bidPrice, err := service.calculatePricesFromTrades(trades)
+	askPrice, bidPrice, _, err := service.calculatePricesFromTrades(trades)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 81.25, askPrice)
 	assert.Equal(t, 81.25, bidPrice)
+
+	warnings := logs.FilterMessage("Failed to parse trade price").All()
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "invalid", warnings[0].ContextMap()["price"])
 }
 
 func TestCalculatePricesFromTrades_EmptyTrades(t *testing.T) {
 	logger := zap.NewNop()
-	service := &GrinexService{logger: logger}
+	service := NewGrinexService(&GrinexConfig{}, logger)
 
 	trades := []GrinexTrade{}
 
-	_, _, err := service.calculatePricesFromTrades(trades)
+	_, _, _, err := service.calculatePricesFromTrades(trades)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "no trades to calculate prices from")
 }
+
+func TestGetUSDTRate_HedgedRequestWins(t *testing.T) {
+	var requests atomic.Int32
+	firstCancelled := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			// The first request stalls until the client gives up on it
+			select {
+			case <-r.Context().Done():
+				close(firstCancelled)
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"id": 1, "price": "81.25", "created_at": "2025-07-28T21:22:14+03:00"}]`))
+	}))
+	defer server.Close()
+
+	config := &GrinexConfig{
+		BaseURL:    server.URL,
+		Timeout:    30 * time.Second,
+		UserAgent:  "TestAgent/1.0",
+		HedgeDelay: 50 * time.Millisecond,
+	}
+
+	service := NewGrinexService(config, zap.NewNop())
+
+	start := time.Now()
+	rate, err := service.GetUSDTRate(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 81.25, rate.AskPrice)
+	assert.Less(t, time.Since(start), 2*time.Second)
+	assert.Equal(t, int32(2), requests.Load())
+
+	select {
+	case <-firstCancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("slow request was not cancelled")
+	}
+}
+
+func TestGetUSDTRate_HedgingDisabled(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(100 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"id": 1, "price": "81.25", "created_at": "2025-07-28T21:22:14+03:00"}]`))
+	}))
+	defer server.Close()
+
+	config := &GrinexConfig{
+		BaseURL:   server.URL,
+		Timeout:   30 * time.Second,
+		UserAgent: "TestAgent/1.0",
+	}
+
+	service := NewGrinexService(config, zap.NewNop())
+
+	_, err := service.GetUSDTRate(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+// newPagedTradesServer serves trades with IDs 1..total honoring the from/to/limit cursors
+func newPagedTradesServer(t *testing.T, total int64, pages *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages.Add(1)
+		assert.Equal(t, "usdtrub", r.URL.Query().Get("market"))
+		assert.Equal(t, "asc", r.URL.Query().Get("order_by"))
+
+		from, _ := strconv.ParseInt(r.URL.Query().Get("from"), 10, 64)
+		to := int64(math.MaxInt64)
+		if r.URL.Query().Has("to") {
+			to, _ = strconv.ParseInt(r.URL.Query().Get("to"), 10, 64)
+		}
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		trades := make([]GrinexTrade, 0)
+		for id := from + 1; id < to && id <= total && len(trades) < limit; id++ {
+			trades = append(trades, GrinexTrade{ID: id, Price: "81.25", Market: "usdtrub"})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(trades)
+	}))
+}
+
+func TestGetTradesRange_Paged(t *testing.T) {
+	var pages atomic.Int32
+	server := newPagedTradesServer(t, 5000, &pages)
+	defer server.Close()
+
+	service := NewGrinexService(&GrinexConfig{BaseURL: server.URL, Timeout: 30 * time.Second}, zap.NewNop())
+
+	trades, err := service.GetTradesRange(context.Background(), "usdtrub", 100, 2599)
+
+	require.NoError(t, err)
+	require.Len(t, trades, 2500)
+	assert.Equal(t, int64(100), trades[0].ID)
+	assert.Equal(t, int64(2599), trades[len(trades)-1].ID)
+	assert.Equal(t, int32(3), pages.Load())
+}
+
+func TestGetTradesRange_Empty(t *testing.T) {
+	var pages atomic.Int32
+	server := newPagedTradesServer(t, 10, &pages)
+	defer server.Close()
+
+	service := NewGrinexService(&GrinexConfig{BaseURL: server.URL, Timeout: 30 * time.Second}, zap.NewNop())
+
+	trades, err := service.GetTradesRange(context.Background(), "usdtrub", 100, 200)
+
+	require.NoError(t, err)
+	assert.Empty(t, trades)
+	assert.Equal(t, int32(1), pages.Load())
+}
+
+func TestGetTradesRange_UnboundedTo(t *testing.T) {
+	var pages atomic.Int32
+	server := newPagedTradesServer(t, 150, &pages)
+	defer server.Close()
+
+	service := NewGrinexService(&GrinexConfig{BaseURL: server.URL, Timeout: 30 * time.Second}, zap.NewNop())
+
+	trades, err := service.GetTradesRange(context.Background(), "usdtrub", 100, math.MaxInt64)
+
+	require.NoError(t, err)
+	require.Len(t, trades, 51)
+	assert.Equal(t, int64(100), trades[0].ID)
+	assert.Equal(t, int64(150), trades[len(trades)-1].ID)
+}
+
+func TestGetTradesRange_InvalidRange(t *testing.T) {
+	service := NewGrinexService(&GrinexConfig{BaseURL: "http://127.0.0.1:0"}, zap.NewNop())
+
+	_, err := service.GetTradesRange(context.Background(), "usdtrub", 200, 100)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid trade ID range")
+}
+
+func TestRateTimestamp_MedianIgnoresFutureOutlier(t *testing.T) {
+	service := NewGrinexService(&GrinexConfig{TimestampTrades: 5}, zap.NewNop())
+
+	trades := []GrinexTrade{
+		{CreatedAt: "2099-01-01T00:00:00+03:00"}, // Bogus future timestamp on the newest trade
+		{CreatedAt: "2025-07-28T21:22:14+03:00"},
+		{CreatedAt: "2025-07-28T21:21:00+03:00"},
+		{CreatedAt: "2025-07-28T21:20:00+03:00"},
+		{CreatedAt: "2025-07-28T21:19:53+03:00"},
+		{CreatedAt: "2025-07-28T20:00:00+03:00"}, // Beyond K, not considered
+	}
+
+	expected, _ := time.Parse(time.RFC3339, "2025-07-28T21:21:00+03:00")
+	assert.True(t, expected.Equal(service.rateTimestamp(trades)))
+}
+
+func TestRateTimestamp_EvenCountAndInvalidDates(t *testing.T) {
+	service := NewGrinexService(&GrinexConfig{TimestampTrades: 3}, zap.NewNop())
+
+	trades := []GrinexTrade{
+		{CreatedAt: "2025-07-28T21:22:00+03:00"},
+		{CreatedAt: "not a date"},
+		{CreatedAt: "2025-07-28T21:20:00+03:00"},
+	}
+
+	expected, _ := time.Parse(time.RFC3339, "2025-07-28T21:21:00+03:00")
+	assert.True(t, expected.Equal(service.rateTimestamp(trades)))
+}
+
+func TestRateTimestamp_DefaultUsesNewestTrade(t *testing.T) {
+	service := NewGrinexService(&GrinexConfig{}, zap.NewNop())
+
+	trades := []GrinexTrade{
+		{CreatedAt: "2025-07-28T21:22:14+03:00"},
+		{CreatedAt: "2025-07-28T21:19:53+03:00"},
+	}
+
+	expected, _ := time.Parse(time.RFC3339, "2025-07-28T21:22:14+03:00")
+	assert.True(t, expected.Equal(service.rateTimestamp(trades)))
+}
+
+func TestRateTimestamp_ShuffledTrades(t *testing.T) {
+	service := NewGrinexService(&GrinexConfig{}, zap.NewNop())
+
+	trades := []GrinexTrade{
+		{CreatedAt: "2025-07-28T21:19:53+03:00"},
+		{CreatedAt: "2025-07-28T21:20:30+03:00"},
+		{CreatedAt: "2025-07-28T21:22:14+03:00"}, // Newest, in the middle of the batch
+		{CreatedAt: "2025-07-28T21:18:00+03:00"},
+	}
+
+	expected, _ := time.Parse(time.RFC3339, "2025-07-28T21:22:14+03:00")
+	assert.True(t, expected.Equal(service.rateTimestamp(trades)))
+}
+
+func TestRateTimestamp_MalformedDateSkipped(t *testing.T) {
+	service := NewGrinexService(&GrinexConfig{TimestampTrades: 3}, zap.NewNop())
+
+	trades := []GrinexTrade{
+		{CreatedAt: "2025-07-28T21:20:00+03:00"},
+		{CreatedAt: "2025-07-28 21:25:00"}, // Not RFC3339
+		{CreatedAt: "2025-07-28T21:22:00+03:00"},
+		{CreatedAt: "2025-07-28T21:10:00+03:00"},
+		{CreatedAt: "2025-07-28T21:21:00+03:00"},
+	}
+
+	// The newest three valid times are 21:22, 21:21 and 21:20
+	expected, _ := time.Parse(time.RFC3339, "2025-07-28T21:21:00+03:00")
+	assert.True(t, expected.Equal(service.rateTimestamp(trades)))
+}
+
+func TestRateTimestamp_AllDatesMalformed(t *testing.T) {
+	service := NewGrinexService(&GrinexConfig{}, zap.NewNop())
+
+	trades := []GrinexTrade{{CreatedAt: "yesterday"}, {CreatedAt: ""}}
+
+	before := time.Now()
+	timestamp := service.rateTimestamp(trades)
+	assert.False(t, timestamp.Before(before))
+	assert.False(t, timestamp.After(time.Now()))
+}
+
+func TestGetRate_ShuffledTradesUseNewestTime(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"id": 1, "price": "81.20", "market": "usdtrub", "created_at": "2025-07-28T21:19:53+03:00"},
+			{"id": 3, "price": "81.30", "market": "usdtrub", "created_at": "not a date"},
+			{"id": 2, "price": "81.25", "market": "usdtrub", "created_at": "2025-07-28T21:22:14+03:00"}
+		]`))
+	}))
+	defer server.Close()
+
+	service := NewGrinexService(&GrinexConfig{BaseURL: server.URL, Timeout: 5 * time.Second}, zap.NewNop())
+
+	rate, err := service.GetUSDTRate(context.Background())
+
+	require.NoError(t, err)
+	expected, _ := time.Parse(time.RFC3339, "2025-07-28T21:22:14+03:00")
+	assert.True(t, expected.Equal(rate.Timestamp))
+}
+
+func TestWithBaseURL(t *testing.T) {
+	original := NewGrinexService(&GrinexConfig{BaseURL: "https://grinex.io", UserAgent: "TestAgent/1.0"}, zap.NewNop())
+
+	clone := original.WithBaseURL("http://staging.example.com/")
+
+	assert.Equal(t, "http://staging.example.com", clone.config.BaseURL)
+	assert.Equal(t, "TestAgent/1.0", clone.config.UserAgent)
+	assert.Equal(t, "https://grinex.io", original.config.BaseURL)
+}
+
+// newRecentTradesServer serves the newest trades of IDs 1..total in descending order honoring
+// the to/limit cursors, recording the requested limits
+func newRecentTradesServer(t *testing.T, total int64, limits *[]int) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		to, _ := strconv.ParseInt(r.URL.Query().Get("to"), 10, 64)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		assert.LessOrEqual(t, limit, tradesPageLimit)
+
+		mu.Lock()
+		*limits = append(*limits, limit)
+		mu.Unlock()
+
+		if to == 0 {
+			to = total + 1
+		}
+		trades := make([]GrinexTrade, 0)
+		for id := to - 1; id >= 1 && len(trades) < limit; id-- {
+			trades = append(trades, GrinexTrade{ID: id, Price: "81.25", Market: "usdtrub", CreatedAt: "2025-07-28T21:22:14+03:00"})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(trades)
+	}))
+}
+
+func TestTradesLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   GrinexConfig
+		expected int
+	}{
+		{"default", GrinexConfig{}, 100},
+		{"configured", GrinexConfig{TradesLimit: 300}, 300},
+		{"capped globally", GrinexConfig{TradesLimit: 100000, MaxTradesLimit: 5000}, 5000},
+		{"capped per market", GrinexConfig{TradesLimit: 100000, MaxTradesLimit: 5000, MarketMaxTradesLimits: map[string]int{"usdtrub": 2500}}, 2500},
+		{"other market cap ignored", GrinexConfig{TradesLimit: 100000, MaxTradesLimit: 5000, MarketMaxTradesLimits: map[string]int{"btcrub": 2500}}, 5000},
+		{"uncapped", GrinexConfig{TradesLimit: 100000}, 100000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewGrinexService(&tt.config, zap.NewNop())
+			assert.Equal(t, tt.expected, service.tradesLimit(USDTMarket))
+		})
+	}
+}
+
+func TestGetUSDTRate_ExcessiveLimitPaged(t *testing.T) {
+	var limits []int
+	server := newRecentTradesServer(t, 100000, &limits)
+	defer server.Close()
+
+	service := NewGrinexService(&GrinexConfig{
+		BaseURL:               server.URL,
+		Timeout:               30 * time.Second,
+		TradesLimit:           1000000,
+		MaxTradesLimit:        50000,
+		MarketMaxTradesLimits: map[string]int{"usdtrub": 2500},
+	}, zap.NewNop())
+
+	trades, err := service.recentTrades(context.Background(), USDTMarket, service.tradesLimit(USDTMarket))
+
+	require.NoError(t, err)
+	require.Len(t, trades, 2500)
+	assert.Equal(t, int64(100000), trades[0].ID)
+	assert.Equal(t, int64(97501), trades[len(trades)-1].ID)
+	assert.Equal(t, []int{1000, 1000, 500}, limits) // Stops once enough trades are collected
+
+	rate, err := service.GetUSDTRate(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 81.25, rate.AskPrice)
+}
+
+func TestRecentTrades_PagedStopsWhenTradesRunOut(t *testing.T) {
+	var limits []int
+	server := newRecentTradesServer(t, 1200, &limits)
+	defer server.Close()
+
+	service := NewGrinexService(&GrinexConfig{BaseURL: server.URL, Timeout: 30 * time.Second}, zap.NewNop())
+
+	trades, err := service.recentTrades(context.Background(), USDTMarket, 5000)
+
+	require.NoError(t, err)
+	assert.Len(t, trades, 1200)
+	assert.Equal(t, []int{1000, 1000}, limits)
+}
+
+func TestNewTransport(t *testing.T) {
+	auto := newTransport(HTTPVersionAuto)
+	assert.True(t, auto.ForceAttemptHTTP2)
+	assert.Nil(t, auto.Protocols)
+
+	http1 := newTransport(HTTPVersion1)
+	assert.False(t, http1.ForceAttemptHTTP2)
+	require.NotNil(t, http1.Protocols)
+	assert.True(t, http1.Protocols.HTTP1())
+	assert.False(t, http1.Protocols.HTTP2())
+
+	http2 := newTransport(HTTPVersion2)
+	require.NotNil(t, http2.Protocols)
+	assert.False(t, http2.Protocols.HTTP1())
+	assert.True(t, http2.Protocols.HTTP2())
+	assert.True(t, http2.Protocols.UnencryptedHTTP2())
+}
+
+func TestSetConnectTimeouts(t *testing.T) {
+	transport := newTransport(HTTPVersionAuto)
+	defaultHandshake := transport.TLSHandshakeTimeout
+
+	setConnectTimeouts(transport, 0, 0)
+	assert.Equal(t, defaultHandshake, transport.TLSHandshakeTimeout)
+
+	setConnectTimeouts(transport, time.Second, 3*time.Second)
+	assert.NotNil(t, transport.DialContext)
+	assert.Equal(t, 3*time.Second, transport.TLSHandshakeTimeout)
+}
+
+func TestGetUSDTRate_ConnectTimeout(t *testing.T) {
+	// 10.255.255.1 is not routed, so the dial hangs until the connect timeout unless the
+	// sandbox rejects it outright, which fails even faster
+	service := NewGrinexService(&GrinexConfig{
+		BaseURL:        "http://10.255.255.1",
+		Timeout:        30 * time.Second,
+		ConnectTimeout: 200 * time.Millisecond,
+	}, zap.NewNop())
+
+	start := time.Now()
+	_, err := service.GetUSDTRate(context.Background())
+
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second, "connect failure should not wait for the overall timeout")
+}
+
+func TestHTTPVersion_NegotiatedProtocol(t *testing.T) {
+	tests := []struct {
+		version       string
+		expectedMajor int
+	}{
+		{HTTPVersionAuto, 2},
+		{HTTPVersion1, 1},
+		{HTTPVersion2, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			var protoMajor atomic.Int32
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				protoMajor.Store(int32(r.ProtoMajor))
+				w.WriteHeader(http.StatusOK)
+			}))
+			server.EnableHTTP2 = true
+			server.StartTLS()
+			defer server.Close()
+
+			service := NewGrinexService(&GrinexConfig{BaseURL: server.URL, Timeout: 5 * time.Second, HTTPVersion: tt.version}, zap.NewNop())
+			roots := x509.NewCertPool()
+			roots.AddCert(server.Certificate())
+			service.client.Transport.(*http.Transport).TLSClientConfig = &tls.Config{RootCAs: roots}
+
+			require.NoError(t, service.HealthCheck(context.Background()))
+			assert.Equal(t, int32(tt.expectedMajor), protoMajor.Load())
+		})
+	}
+}
+
+// newSlowBodyServer sends the response headers right away and the body after delay
+func newSlowBodyServer(t *testing.T, delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(delay):
+		}
+		_, _ = w.Write([]byte(`[{"id":1,"price":"81.25","volume":"1","funds":"81.25","market":"usdtrub","created_at":"2025-07-28T21:22:14+03:00"}]`))
+	}))
+}
+
+func TestFetch_BodyReadTimeout(t *testing.T) {
+	server := newSlowBodyServer(t, 5*time.Second)
+	defer server.Close()
+
+	reader := sdkmetric.NewManualReader()
+	service := NewGrinexService(&GrinexConfig{
+		BaseURL:         server.URL,
+		Timeout:         30 * time.Second,
+		BodyReadTimeout: 50 * time.Millisecond,
+		Meter:           sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test"),
+	}, zap.NewNop())
+
+	start := time.Now()
+	_, err := service.GetUSDTRate(context.Background())
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrBodyReadTimeout)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	histogram := findMetric(t, rm, "grinex_body_read_duration").Data.(metricdata.Histogram[float64])
+	require.Len(t, histogram.DataPoints, 1)
+	assert.Equal(t, uint64(1), histogram.DataPoints[0].Count)
+	timedOut, _ := histogram.DataPoints[0].Attributes.Value("timed_out")
+	assert.True(t, timedOut.AsBool())
+}
+
+// findMetric returns the collected metric with the given name, failing the test if it is missing
+func findMetric(t *testing.T, rm metricdata.ResourceMetrics, name string) metricdata.Metrics {
+	t.Helper()
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+	}
+	require.Failf(t, "metric not collected", "missing metric %s", name)
+	return metricdata.Metrics{}
+}
+
+func TestFetch_RequestMetrics(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"id":1,"price":"81.25","volume":"1","funds":"81.25","market":"usdtrub","created_at":"2025-07-28T21:22:14+03:00"}]`))
+	}))
+	defer server.Close()
+
+	reader := sdkmetric.NewManualReader()
+	service := NewGrinexService(&GrinexConfig{
+		BaseURL: server.URL,
+		Timeout: 30 * time.Second,
+		Meter:   sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test"),
+	}, zap.NewNop())
+
+	_, err := service.GetUSDTRate(context.Background())
+	require.Error(t, err)
+	_, err = service.GetUSDTRate(context.Background())
+	require.NoError(t, err)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	histogram := findMetric(t, rm, "grinex_request_duration").Data.(metricdata.Histogram[float64])
+	require.Len(t, histogram.DataPoints, 2)
+	var total uint64
+	for _, point := range histogram.DataPoints {
+		total += point.Count
+	}
+	assert.Equal(t, uint64(2), total)
+
+	failures := findMetric(t, rm, "grinex_request_failures").Data.(metricdata.Sum[int64])
+	require.Len(t, failures.DataPoints, 1)
+	assert.Equal(t, int64(1), failures.DataPoints[0].Value)
+	path, _ := failures.DataPoints[0].Attributes.Value("path")
+	assert.Equal(t, "/api/v2/trades", path.AsString())
+}
+
+func TestFetch_SlowBodyWithinTimeout(t *testing.T) {
+	server := newSlowBodyServer(t, 20*time.Millisecond)
+	defer server.Close()
+
+	service := NewGrinexService(&GrinexConfig{
+		BaseURL:         server.URL,
+		Timeout:         30 * time.Second,
+		BodyReadTimeout: 2 * time.Second,
+	}, zap.NewNop())
+
+	rate, err := service.GetUSDTRate(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 81.25, rate.AskPrice)
+}
+
+func TestFetch_HTMLBodyWithStatusOK(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<html><head><title>Access denied</title></head><body>Please verify you are a human</body></html>"))
+	}))
+	defer server.Close()
+
+	service := NewGrinexService(&GrinexConfig{
+		BaseURL:          server.URL,
+		Timeout:          30 * time.Second,
+		CheckContentType: true,
+	}, zap.NewNop())
+
+	_, err := service.GetUSDTRate(context.Background())
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrUnexpectedContentType)
+	assert.Contains(t, err.Error(), "text/html")
+	assert.Contains(t, err.Error(), "<title>Access denied</title>")
+}
+
+func TestFetch_ContentTypeCheckDisabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><body>Access denied</body></html>"))
+	}))
+	defer server.Close()
+
+	service := NewGrinexService(&GrinexConfig{BaseURL: server.URL, Timeout: 30 * time.Second}, zap.NewNop())
+
+	_, err := service.GetUSDTRate(context.Background())
+
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrUnexpectedContentType)
+	assert.Contains(t, err.Error(), "failed to unmarshal response")
+}
+
+func TestCheckJSONContentType(t *testing.T) {
+	long := strings.Repeat("x", 2*contentTypeSnippetSize)
+
+	tests := []struct {
+		contentType string
+		wantErr     bool
+	}{
+		{"application/json", false},
+		{"application/json; charset=utf-8", false},
+		{"Application/JSON", false},
+		{"application/problem+json", false},
+		{"", false},
+		{"text/html; charset=utf-8", true},
+		{"text/plain", true},
+		{"invalid;;", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			resp := &http.Response{
+				Header: http.Header{},
+				Body:   io.NopCloser(strings.NewReader(long)),
+			}
+			if tt.contentType != "" {
+				resp.Header.Set("Content-Type", tt.contentType)
+			}
+
+			err := checkJSONContentType(resp)
+
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrUnexpectedContentType)
+			assert.Contains(t, err.Error(), strings.Repeat("x", contentTypeSnippetSize))
+			assert.NotContains(t, err.Error(), strings.Repeat("x", contentTypeSnippetSize+1))
+		})
+	}
+}
+
+func TestTruncateToBucket(t *testing.T) {
+	timestamp := time.Date(2025, 7, 28, 21, 22, 14, 678000000, time.FixedZone("MSK", 3*60*60))
+
+	tests := []struct {
+		bucket time.Duration
+		want   time.Time
+	}{
+		{0, timestamp},
+		{-time.Second, timestamp},
+		{time.Second, time.Date(2025, 7, 28, 21, 22, 14, 0, timestamp.Location())},
+		{15 * time.Second, time.Date(2025, 7, 28, 21, 22, 0, 0, timestamp.Location())},
+		{time.Minute, time.Date(2025, 7, 28, 21, 22, 0, 0, timestamp.Location())},
+		{5 * time.Minute, time.Date(2025, 7, 28, 21, 20, 0, 0, timestamp.Location())},
+		{time.Hour, time.Date(2025, 7, 28, 21, 0, 0, 0, timestamp.Location())},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.bucket.String(), func(t *testing.T) {
+			assert.True(t, tt.want.Equal(truncateToBucket(timestamp, tt.bucket)), "got %s", truncateToBucket(timestamp, tt.bucket))
+		})
+	}
+}
+
+func TestGetUSDTRate_TimestampBucket(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id": 1, "price": "81.25", "market": "usdtrub", "created_at": "2025-07-28T21:22:14Z"}]`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		keepRaw bool
+	}{
+		{"without raw timestamp", false},
+		{"with raw timestamp", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewGrinexService(&GrinexConfig{
+				BaseURL:          server.URL,
+				Timeout:          30 * time.Second,
+				TimestampBucket:  time.Minute,
+				KeepRawTimestamp: tt.keepRaw,
+			}, zap.NewNop())
+
+			rate, err := service.GetUSDTRate(context.Background())
+
+			require.NoError(t, err)
+			assert.True(t, rate.Timestamp.Equal(time.Date(2025, 7, 28, 21, 22, 0, 0, time.UTC)))
+			if tt.keepRaw {
+				assert.True(t, rate.RawTimestamp.Equal(time.Date(2025, 7, 28, 21, 22, 14, 0, time.UTC)))
+			} else {
+				assert.True(t, rate.RawTimestamp.IsZero())
+			}
+		})
+	}
+}
+
+func TestGetRate_MinTrades(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Two trades, one of them with an unparsable price
+		fmt.Fprint(w, `[
+			{"id": 2, "price": "81.25", "market": "usdtrub", "created_at": "2025-07-28T21:22:14+03:00"},
+			{"id": 1, "price": "n/a", "market": "usdtrub", "created_at": "2025-07-28T21:19:53+03:00"}
+		]`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		minTrades int
+		wantErr   bool
+	}{
+		{0, false},
+		{1, false},
+		{2, true}, // Only one trade has a valid price
+		{5, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.minTrades), func(t *testing.T) {
+			service := NewGrinexService(&GrinexConfig{
+				BaseURL:   server.URL,
+				Timeout:   30 * time.Second,
+				MinTrades: tt.minTrades,
+			}, zap.NewNop())
+
+			rate, err := service.GetRate(context.Background(), USDTMarket)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInsufficientTrades)
+				assert.Contains(t, err.Error(), "1 valid trades")
+				assert.Nil(t, rate)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, 81.25, rate.AskPrice)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// knownQuoteCurrencies lists quote currencies used to derive a pair label from a
+// Grinex market symbol. Longer symbols come first so that "usdt" wins over "usd".
+var knownQuoteCurrencies = []string{"usdt", "usdc", "a7a5", "rub", "usd", "eur", "btc", "eth"}
+
+// supportedMarkets is the registry of Grinex markets rates can be requested for. Markets with a
+// configured pair label are supported as well.
+var supportedMarkets = map[string]bool{
+	USDTMarket: true,
+	"usdtusd":  true,
+	"usdta7a5": true,
+	"a7a5rub":  true,
+	"btcrub":   true,
+	"btcusdt":  true,
+	"ethrub":   true,
+	"ethusdt":  true,
+}
+
+// ErrUnsupportedPair is returned for trading pairs whose market is not in the registry
+var ErrUnsupportedPair = errors.New("unsupported trading pair")
+
+// DerivePairLabel builds a human readable trading pair label from a Grinex market
+// symbol by splitting on a known quote currency (e.g. "usdtrub" -> "USDT/RUB").
+// Symbols without a known quote currency are returned upper-cased.
+func DerivePairLabel(market string) string {
+	market = strings.ToLower(strings.TrimSpace(market))
+	for _, quote := range knownQuoteCurrencies {
+		if len(market) > len(quote) && strings.HasSuffix(market, quote) {
+			base := strings.TrimSuffix(market, quote)
+			return strings.ToUpper(base) + "/" + strings.ToUpper(quote)
+		}
+	}
+	return strings.ToUpper(market)
+}
+
+// LoadPairLabels reads a market symbol to label mapping from a JSON file
+func LoadPairLabels(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pair labels file: %w", err)
+	}
+
+	var labels map[string]string
+	if err := json.Unmarshal(data, &labels); err != nil {
+		return nil, fmt.Errorf("failed to parse pair labels file: %w", err)
+	}
+
+	return labels, nil
+}
+
+// MergePairLabels combines labels loaded from a JSON file with inline ones, inline labels
+// taking precedence. An empty path skips the file.
+func MergePairLabels(path string, inline map[string]string) (map[string]string, error) {
+	labels := make(map[string]string)
+	if path != "" {
+		fileLabels, err := LoadPairLabels(path)
+		if err != nil {
+			return nil, err
+		}
+		for market, label := range fileLabels {
+			labels[strings.ToLower(market)] = label
+		}
+	}
+	for market, label := range inline {
+		labels[strings.ToLower(market)] = label
+	}
+	return labels, nil
+}
+
+// LookupPairLabel returns the label for a market from labels, falling back to a derived one
+func LookupPairLabel(labels map[string]string, market string) string {
+	if label, ok := labels[strings.ToLower(market)]; ok && label != "" {
+		return label
+	}
+	return DerivePairLabel(market)
+}
+
+// PairLabel returns the configured label for a market, falling back to a derived one
+func (g *GrinexService) PairLabel(market string) string {
+	return LookupPairLabel(g.config.PairLabels, market)
+}
+
+// MarketForPair resolves a trading pair given as a configured label, a derived label like
+// "USDT/RUB" or a market symbol like "usdtrub" to the Grinex market symbol
+func (g *GrinexService) MarketForPair(pair string) string {
+	pair = strings.TrimSpace(pair)
+	for market, label := range g.config.PairLabels {
+		if strings.EqualFold(label, pair) {
+			return market
+		}
+	}
+	return strings.ToLower(strings.ReplaceAll(pair, "/", ""))
+}
+
+// ResolvePair maps a trading pair like "USDT/RUB" or "usdtrub" to its Grinex market symbol,
+// failing with ErrUnsupportedPair unless the market is registered or has a configured label
+func (g *GrinexService) ResolvePair(pair string) (string, error) {
+	market := g.MarketForPair(pair)
+	if _, labelled := g.config.PairLabels[market]; market == "" || !supportedMarkets[market] && !labelled {
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedPair, pair)
+	}
+	return market, nil
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestDerivePairLabel(t *testing.T) {
+	tests := map[string]string{
+		"usdtrub": "USDT/RUB",
+		"btcusdt": "BTC/USDT",
+		"usdtusd": "USDT/USD",
+		"a7a5rub": "A7A5/RUB",
+		"ethbtc":  "ETH/BTC",
+		"USDTRUB": "USDT/RUB",
+		"foobar":  "FOOBAR",
+		"rub":     "RUB",
+	}
+
+	for market, expected := range tests {
+		assert.Equal(t, expected, DerivePairLabel(market), market)
+	}
+}
+
+func TestPairLabel_Mapped(t *testing.T) {
+	service := &GrinexService{
+		config: &GrinexConfig{
+			PairLabels: map[string]string{"usdtrub": "USDT-RUB"},
+		},
+		logger: zap.NewNop(),
+	}
+
+	assert.Equal(t, "USDT-RUB", service.PairLabel("usdtrub"))
+	assert.Equal(t, "BTC/RUB", service.PairLabel("btcrub")) // Derived when unmapped
+}
+
+func TestLoadPairLabels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "labels.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"usdtrub": "USDT-RUB", "btcrub": "BTC-RUB"}`), 0o600))
+
+	labels, err := LoadPairLabels(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"usdtrub": "USDT-RUB", "btcrub": "BTC-RUB"}, labels)
+}
+
+func TestLoadPairLabels_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "labels.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+
+	_, err := LoadPairLabels(path)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse pair labels file")
+}
+
+func TestMarketForPair(t *testing.T) {
+	service := NewGrinexService(&GrinexConfig{PairLabels: map[string]string{"a7a5rub": "A7A5 Ruble"}}, zap.NewNop())
+
+	tests := map[string]string{
+		"USDT/RUB":   "usdtrub",
+		"usdtrub":    "usdtrub",
+		" BTC/RUB ":  "btcrub",
+		"a7a5 ruble": "a7a5rub",
+	}
+
+	for pair, expected := range tests {
+		assert.Equal(t, expected, service.MarketForPair(pair), pair)
+	}
+}
+
+func TestResolvePair(t *testing.T) {
+	service := NewGrinexService(&GrinexConfig{PairLabels: map[string]string{"dogerub": "DOGE Ruble"}}, zap.NewNop())
+
+	valid := map[string]string{
+		"USDT/RUB":   "usdtrub",
+		"usdtusd":    "usdtusd",
+		"btc/rub":    "btcrub",
+		"DOGE Ruble": "dogerub",
+	}
+	for pair, expected := range valid {
+		market, err := service.ResolvePair(pair)
+		require.NoError(t, err, pair)
+		assert.Equal(t, expected, market, pair)
+	}
+
+	for _, pair := range []string{"DOGE/USD", "rub", " "} {
+		_, err := service.ResolvePair(pair)
+		assert.ErrorIs(t, err, ErrUnsupportedPair, pair)
+	}
+}
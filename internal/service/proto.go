@@ -0,0 +1,56 @@
+package service
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+// ToProto converts the rate to a GetRatesResp with local_time in UTC
+func (r *Rate) ToProto() *pb.GetRatesResp {
+	return r.ToProtoIn(time.UTC)
+}
+
+// ToProtoIn converts the rate to a GetRatesResp with local_time in loc. Only the rate itself is
+// set; fees, price formats and staleness are left to the caller.
+func (r *Rate) ToProtoIn(loc *time.Location) *pb.GetRatesResp {
+	timestamp := r.Timestamp.In(loc)
+	resp := &pb.GetRatesResp{
+		TradingPair: r.TradingPair,
+		AskPrice:    r.AskPrice,
+		BidPrice:    r.BidPrice,
+		MidPrice:    r.MidPrice,
+		Vwap:        r.VWAP,
+		Timestamp:   timestamppb.New(timestamp),
+		LocalTime:   timestamp.Format(time.RFC3339),
+	}
+	if !r.IngestedAt.IsZero() {
+		resp.IngestedAt = timestamppb.New(r.IngestedAt)
+	}
+	return resp
+}
+
+// RateFromProto converts a GetRatesResp back to a rate with the timestamp in UTC. The mid price
+// is derived from ask and bid when the response omits it, e.g. because of a field mask.
+// RawTimestamp and Source are not part of the response and stay empty.
+func RateFromProto(resp *pb.GetRatesResp) *Rate {
+	rate := &Rate{
+		TradingPair: resp.GetTradingPair(),
+		AskPrice:    resp.GetAskPrice(),
+		BidPrice:    resp.GetBidPrice(),
+		MidPrice:    resp.GetMidPrice(),
+		VWAP:        resp.GetVwap(),
+	}
+	if rate.MidPrice == 0 {
+		rate.MidPrice = (rate.AskPrice + rate.BidPrice) / 2
+	}
+	if resp.GetTimestamp() != nil {
+		rate.Timestamp = resp.GetTimestamp().AsTime()
+	}
+	if resp.GetIngestedAt() != nil {
+		rate.IngestedAt = resp.GetIngestedAt().AsTime()
+	}
+	return rate
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+func TestRateProtoRoundTrip(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+
+	rate := &Rate{
+		TradingPair: "USDT/RUB",
+		AskPrice:    81.25,
+		BidPrice:    81.20,
+		MidPrice:    81.225,
+		VWAP:        81.23,
+		Timestamp:   time.Date(2025, 7, 28, 21, 22, 14, 123456789, moscow),
+		IngestedAt:  time.Date(2025, 7, 28, 18, 22, 15, 0, time.UTC),
+	}
+
+	resp := rate.ToProto()
+	assert.Equal(t, "2025-07-28T18:22:14Z", resp.LocalTime)
+
+	got := RateFromProto(resp)
+	assert.Equal(t, rate.TradingPair, got.TradingPair)
+	assert.Equal(t, rate.AskPrice, got.AskPrice)
+	assert.Equal(t, rate.BidPrice, got.BidPrice)
+	assert.Equal(t, rate.MidPrice, got.MidPrice)
+	assert.Equal(t, rate.VWAP, got.VWAP)
+	assert.True(t, rate.Timestamp.Equal(got.Timestamp), "nanoseconds survive the round trip: %s != %s", rate.Timestamp, got.Timestamp)
+	assert.Equal(t, time.UTC, got.Timestamp.Location())
+	assert.Equal(t, rate.IngestedAt, got.IngestedAt)
+}
+
+func TestRate_ToProtoIn(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+	rate := &Rate{Timestamp: time.Date(2025, 7, 28, 18, 22, 14, 0, time.UTC)}
+
+	resp := rate.ToProtoIn(moscow)
+
+	assert.Equal(t, "2025-07-28T21:22:14+03:00", resp.LocalTime)
+	assert.True(t, rate.Timestamp.Equal(resp.Timestamp.AsTime()))
+}
+
+func TestRateFromProto_MissingFields(t *testing.T) {
+	rate := RateFromProto(&pb.GetRatesResp{AskPrice: 82, BidPrice: 80})
+
+	assert.Equal(t, 81.0, rate.MidPrice)
+	assert.True(t, rate.Timestamp.IsZero())
+}
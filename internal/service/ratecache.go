@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateCache keeps recently fetched rates in memory for a TTL, so clients polling GetRates
+// faster than the TTL don't each cause a Grinex request. Concurrent misses for the same key
+// share a single fetch.
+type RateCache struct {
+	ttl          time.Duration
+	fetchTimeout time.Duration
+
+	mu       sync.Mutex
+	entries  map[string]cachedRate
+	inflight map[string]*rateFetch
+}
+
+type cachedRate struct {
+	rate      *Rate
+	fetchedAt time.Time
+}
+
+// rateFetch is a fetch in progress that concurrent callers of the same key wait for. The caller
+// that started it owns the result unless it gave up first, in which case the first waiter to
+// receive the result takes it over.
+type rateFetch struct {
+	done chan struct{}
+	rate *Rate
+	err  error
+
+	leaderGone bool
+	claimed    bool
+}
+
+// NewRateCache returns a cache keeping rates for ttl, a non-positive ttl disabling caching.
+// Shared fetches outlive the caller that started them and are bounded by fetchTimeout instead,
+// a non-positive fetchTimeout leaving them unbounded.
+func NewRateCache(ttl, fetchTimeout time.Duration) *RateCache {
+	return &RateCache{
+		ttl:          ttl,
+		fetchTimeout: fetchTimeout,
+		entries:      make(map[string]cachedRate),
+		inflight:     make(map[string]*rateFetch),
+	}
+}
+
+// Get returns the rate cached under key while it is younger than the TTL, and otherwise calls
+// fetch, once for all concurrent callers of the key. fetched reports whether this caller owns
+// the new rate, normally the one that started the fetch, so only one of them acts on it, e.g.
+// by storing it. Errors are not cached. A nil or disabled cache always calls fetch.
+func (c *RateCache) Get(ctx context.Context, key string, fetch func(context.Context) (*Rate, error)) (rate *Rate, fetched bool, err error) {
+	if c == nil || c.ttl <= 0 {
+		rate, err := fetch(ctx)
+		return rate, true, err
+	}
+
+	c.mu.Lock()
+	if entry, ok := c.entries[key]; ok && time.Since(entry.fetchedAt) < c.ttl {
+		c.mu.Unlock()
+		return entry.rate, false, nil
+	}
+	c.mu.Unlock()
+
+	return c.load(ctx, key, fetch)
+}
+
+// Refresh calls fetch even when the rate cached under key is still fresh and caches the result.
+// A fetch of the key already in progress is waited for and shared instead of starting another,
+// in which case fetched is false. A nil or disabled cache always calls fetch.
+func (c *RateCache) Refresh(ctx context.Context, key string, fetch func(context.Context) (*Rate, error)) (rate *Rate, fetched bool, err error) {
+	if c == nil || c.ttl <= 0 {
+		rate, err := fetch(ctx)
+		return rate, true, err
+	}
+
+	return c.load(ctx, key, fetch)
+}
+
+// load runs fetch once for all concurrent callers of key and caches its result. The fetch runs
+// detached from ctx, so a caller going away doesn't fail it for the others waiting on it.
+func (c *RateCache) load(ctx context.Context, key string, fetch func(context.Context) (*Rate, error)) (*Rate, bool, error) {
+	c.mu.Lock()
+	call, joined := c.inflight[key]
+	if !joined {
+		call = &rateFetch{done: make(chan struct{})}
+		c.inflight[key] = call
+		go c.run(ctx, key, call, fetch)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		owner := !call.claimed && (!joined || call.leaderGone)
+		if owner {
+			call.claimed = true
+		}
+		return call.rate, owner, call.err
+	case <-ctx.Done():
+		if !joined {
+			c.mu.Lock()
+			call.leaderGone = true
+			c.mu.Unlock()
+		}
+		return nil, false, ctx.Err()
+	}
+}
+
+// run performs a shared fetch on a context that keeps the values but not the cancellation of
+// ctx, caches a successful result and wakes the callers waiting on it
+func (c *RateCache) run(ctx context.Context, key string, call *rateFetch, fetch func(context.Context) (*Rate, error)) {
+	fetchCtx := context.WithoutCancel(ctx)
+	if c.fetchTimeout > 0 {
+		var cancel context.CancelFunc
+		fetchCtx, cancel = context.WithTimeout(fetchCtx, c.fetchTimeout)
+		defer cancel()
+	}
+
+	rate, err := fetch(fetchCtx)
+
+	c.mu.Lock()
+	call.rate, call.err = rate, err
+	delete(c.inflight, key)
+	if err == nil {
+		c.entries[key] = cachedRate{rate: rate, fetchedAt: time.Now()}
+	}
+	c.mu.Unlock()
+	close(call.done)
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateCache_ConcurrentCallersShareOneFetch(t *testing.T) {
+	cache := NewRateCache(time.Minute, 0)
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	fetch := func(context.Context) (*Rate, error) {
+		fetches.Add(1)
+		<-release
+		return &Rate{TradingPair: "USDT/RUB", MidPrice: 81.225}, nil
+	}
+
+	const callers = 10
+	var fetchedCount atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rate, fetched, err := cache.Get(context.Background(), "usdtrub/trades", fetch)
+			assert.NoError(t, err)
+			assert.Equal(t, 81.225, rate.MidPrice)
+			if fetched {
+				fetchedCount.Add(1)
+			}
+		}()
+	}
+
+	// Let the callers pile up on the first fetch before it completes
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), fetches.Load())
+	assert.Equal(t, int32(1), fetchedCount.Load(), "exactly one caller owns the fetched rate")
+}
+
+func TestRateCache_Expiry(t *testing.T) {
+	cache := NewRateCache(20*time.Millisecond, 0)
+	var fetches atomic.Int32
+	fetch := func(context.Context) (*Rate, error) {
+		return &Rate{MidPrice: float64(fetches.Add(1))}, nil
+	}
+
+	rate, fetched, err := cache.Get(context.Background(), "usdtrub", fetch)
+	require.NoError(t, err)
+	assert.True(t, fetched)
+	assert.Equal(t, 1.0, rate.MidPrice)
+
+	rate, fetched, err = cache.Get(context.Background(), "usdtrub", fetch)
+	require.NoError(t, err)
+	assert.False(t, fetched)
+	assert.Equal(t, 1.0, rate.MidPrice)
+
+	// Keys are cached separately
+	rate, _, err = cache.Get(context.Background(), "btcrub", fetch)
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, rate.MidPrice)
+
+	time.Sleep(30 * time.Millisecond)
+	rate, fetched, err = cache.Get(context.Background(), "usdtrub", fetch)
+	require.NoError(t, err)
+	assert.True(t, fetched, "stale entries are fetched again")
+	assert.Equal(t, 3.0, rate.MidPrice)
+}
+
+func TestRateCache_ErrorsAreNotCached(t *testing.T) {
+	cache := NewRateCache(time.Minute, 0)
+	fetchErr := errors.New("grinex unavailable")
+
+	_, _, err := cache.Get(context.Background(), "usdtrub", func(context.Context) (*Rate, error) { return nil, fetchErr })
+	assert.ErrorIs(t, err, fetchErr)
+
+	rate, fetched, err := cache.Get(context.Background(), "usdtrub", func(context.Context) (*Rate, error) { return &Rate{MidPrice: 81}, nil })
+	require.NoError(t, err)
+	assert.True(t, fetched)
+	assert.Equal(t, 81.0, rate.MidPrice)
+}
+
+func TestRateCache_Disabled(t *testing.T) {
+	var fetches atomic.Int32
+	fetch := func(context.Context) (*Rate, error) {
+		fetches.Add(1)
+		return &Rate{}, nil
+	}
+
+	for _, cache := range []*RateCache{nil, NewRateCache(0, 0)} {
+		for i := 0; i < 2; i++ {
+			_, fetched, err := cache.Get(context.Background(), "usdtrub", fetch)
+			require.NoError(t, err)
+			assert.True(t, fetched)
+		}
+	}
+	assert.Equal(t, int32(4), fetches.Load())
+}
+
+func TestRateCache_Refresh(t *testing.T) {
+	cache := NewRateCache(time.Minute, 0)
+	var fetches atomic.Int32
+	fetch := func(context.Context) (*Rate, error) {
+		return &Rate{MidPrice: float64(fetches.Add(1))}, nil
+	}
+
+	_, _, err := cache.Get(context.Background(), "usdtrub", fetch)
+	require.NoError(t, err)
+
+	rate, fetched, err := cache.Refresh(context.Background(), "usdtrub", fetch)
+	require.NoError(t, err)
+	assert.True(t, fetched, "a fresh entry is fetched again")
+	assert.Equal(t, 2.0, rate.MidPrice)
+
+	rate, fetched, err = cache.Get(context.Background(), "usdtrub", fetch)
+	require.NoError(t, err)
+	assert.False(t, fetched)
+	assert.Equal(t, 2.0, rate.MidPrice, "the refreshed rate is cached")
+}
+
+func TestRateCache_RefreshJoinsInflightFetch(t *testing.T) {
+	cache := NewRateCache(time.Minute, 0)
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	fetch := func(context.Context) (*Rate, error) {
+		fetches.Add(1)
+		<-release
+		return &Rate{MidPrice: 81.225}, nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, fetched, err := cache.Get(context.Background(), "usdtrub", fetch)
+		assert.NoError(t, err)
+		assert.True(t, fetched)
+	}()
+
+	// Let the Get start its fetch before refreshing
+	time.Sleep(20 * time.Millisecond)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+
+	rate, fetched, err := cache.Refresh(context.Background(), "usdtrub", fetch)
+	require.NoError(t, err)
+	assert.False(t, fetched)
+	assert.Equal(t, 81.225, rate.MidPrice)
+	<-done
+	assert.Equal(t, int32(1), fetches.Load())
+}
+
+func TestRateCache_LeaderCancelDoesNotFailWaiters(t *testing.T) {
+	cache := NewRateCache(time.Minute, time.Second)
+	release := make(chan struct{})
+	fetch := func(ctx context.Context) (*Rate, error) {
+		select {
+		case <-release:
+			return &Rate{MidPrice: 81.225}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leaderDone := make(chan struct{})
+	go func() {
+		defer close(leaderDone)
+		_, fetched, err := cache.Get(leaderCtx, "usdtrub", fetch)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.False(t, fetched)
+	}()
+
+	// Let the leader start its fetch, then have a waiter join it before the leader gives up
+	time.Sleep(20 * time.Millisecond)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+		<-leaderDone
+		close(release)
+	}()
+
+	rate, fetched, err := cache.Get(context.Background(), "usdtrub", fetch)
+	require.NoError(t, err)
+	assert.True(t, fetched, "the waiter takes over the rate the leader gave up on")
+	assert.Equal(t, 81.225, rate.MidPrice)
+}
+
+func TestRateCache_FetchTimeout(t *testing.T) {
+	cache := NewRateCache(time.Minute, 20*time.Millisecond)
+	fetch := func(ctx context.Context) (*Rate, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	_, _, err := cache.Get(context.Background(), "usdtrub", fetch)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Names of the built-in price strategies
+const (
+	// StrategyExtremes is the name of the default price strategy
+	StrategyExtremes = "extremes"
+	// StrategyTrimmedMean is the name of TrimmedMeanStrategy registered with DefaultTrimFraction
+	StrategyTrimmedMean = "trimmed_mean"
+)
+
+// DefaultTrimFraction is the share of prices dropped from each end by the registered trimmed mean strategy
+const DefaultTrimFraction = 0.1
+
+// PriceStrategy computes ask, bid and mid prices from a set of trades
+type PriceStrategy interface {
+	Compute(trades []GrinexTrade) (ask, bid, mid float64, err error)
+}
+
+// PriceStrategyFunc adapts an ordinary function to the PriceStrategy interface
+type PriceStrategyFunc func(trades []GrinexTrade) (ask, bid, mid float64, err error)
+
+// Compute calls f(trades)
+func (f PriceStrategyFunc) Compute(trades []GrinexTrade) (ask, bid, mid float64, err error) {
+	return f(trades)
+}
+
+var (
+	strategiesMu sync.RWMutex
+	strategies   = make(map[string]PriceStrategy)
+)
+
+func init() {
+	RegisterPriceStrategy(StrategyExtremes, ExtremesStrategy{})
+	RegisterPriceStrategy(StrategyTrimmedMean, TrimmedMeanStrategy{Trim: DefaultTrimFraction})
+}
+
+// RegisterPriceStrategy makes a price strategy available by name, replacing any previous registration
+func RegisterPriceStrategy(name string, strategy PriceStrategy) {
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+
+	strategies[name] = strategy
+}
+
+// LookupPriceStrategy returns the price strategy registered under name
+func LookupPriceStrategy(name string) (PriceStrategy, error) {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+
+	strategy, ok := strategies[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown price strategy: %s", name)
+	}
+	return strategy, nil
+}
+
+// PriceStrategyNames returns the sorted names of all registered price strategies
+func PriceStrategyNames() []string {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ExtremesStrategy uses the highest recent trade price as ask and the lowest as bid
+type ExtremesStrategy struct{}
+
+// Compute implements PriceStrategy
+func (ExtremesStrategy) Compute(trades []GrinexTrade) (ask, bid, mid float64, err error) {
+	prices := parseTradePrices(trades)
+	if len(prices) == 0 {
+		return 0, 0, 0, fmt.Errorf("no valid prices found in trades")
+	}
+
+	// Sort prices in descending order
+	sort.Sort(sort.Reverse(sort.Float64Slice(prices)))
+
+	// Use the highest price as ask and lowest as bid
+	ask = prices[0]             // Highest price
+	bid = prices[len(prices)-1] // Lowest price
+
+	return ask, bid, (ask + bid) / 2, nil
+}
+
+// TrimmedMeanStrategy drops the Trim share of the highest and of the lowest prices and averages
+// the rest as the mid price, using the highest and lowest remaining prices as ask and bid. The
+// number of prices dropped from each end is rounded down and never leaves fewer than one price,
+// so small samples are trimmed less or not at all.
+type TrimmedMeanStrategy struct {
+	// Trim is the fraction of prices dropped from each end, in [0, 0.5)
+	Trim float64
+}
+
+// Compute implements PriceStrategy
+func (s TrimmedMeanStrategy) Compute(trades []GrinexTrade) (ask, bid, mid float64, err error) {
+	if s.Trim < 0 || s.Trim >= 0.5 {
+		return 0, 0, 0, fmt.Errorf("trim fraction must be in [0, 0.5), got %v", s.Trim)
+	}
+
+	prices := parseTradePrices(trades)
+	if len(prices) == 0 {
+		return 0, 0, 0, fmt.Errorf("no valid prices found in trades")
+	}
+	sort.Float64s(prices)
+
+	drop := int(float64(len(prices)) * s.Trim)
+	drop = min(drop, (len(prices)-1)/2)
+	kept := prices[drop : len(prices)-drop]
+
+	var sum float64
+	for _, price := range kept {
+		sum += price
+	}
+
+	return kept[len(kept)-1], kept[0], sum / float64(len(kept)), nil
+}
+
+// TradesVWAP returns the volume weighted average price of trades as their total funds over their
+// total volume. Trades with a zero or unparseable volume or unparseable funds are skipped, and
+// fallback is returned when none remain.
+func TradesVWAP(trades []GrinexTrade, fallback float64) float64 {
+	funds, volume := TradesFundsVolume(trades)
+	if volume <= 0 {
+		return fallback
+	}
+	return funds / volume
+}
+
+// TradesFundsVolume returns the total funds and volume of the trades TradesVWAP counts, so a
+// VWAP can be accumulated over several batches of trades
+func TradesFundsVolume(trades []GrinexTrade) (funds, volume float64) {
+	for _, trade := range trades {
+		tradeVolume, err := strconv.ParseFloat(trade.Volume, 64)
+		if err != nil || tradeVolume <= 0 {
+			continue
+		}
+		tradeFunds, err := strconv.ParseFloat(trade.Funds, 64)
+		if err != nil {
+			continue
+		}
+		funds += tradeFunds
+		volume += tradeVolume
+	}
+	return funds, volume
+}
+
+// TradesMedianPrice returns the median of the valid trade prices, averaging the two central
+// prices of an even number of them
+func TradesMedianPrice(trades []GrinexTrade) (float64, error) {
+	prices := parseTradePrices(trades)
+	if len(prices) == 0 {
+		return 0, fmt.Errorf("no valid prices found in trades")
+	}
+	sort.Float64s(prices)
+
+	middle := len(prices) / 2
+	if len(prices)%2 == 0 {
+		return (prices[middle-1] + prices[middle]) / 2, nil
+	}
+	return prices[middle], nil
+}
+
+// parseTradePrices returns the prices of all trades whose price can be parsed
+func parseTradePrices(trades []GrinexTrade) []float64 {
+	prices := make([]float64, 0, len(trades))
+	for _, trade := range trades {
+		price, err := strconv.ParseFloat(trade.Price, 64)
+		if err != nil {
+			continue
+		}
+		prices = append(prices, price)
+	}
+	return prices
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestExtremesStrategy(t *testing.T) {
+	trades := []GrinexTrade{
+		{Price: "81.25"},
+		{Price: "invalid"},
+		{Price: "81.10"},
+		{Price: "81.30"},
+	}
+
+	ask, bid, mid, err := ExtremesStrategy{}.Compute(trades)
+
+	require.NoError(t, err)
+	assert.Equal(t, 81.30, ask)
+	assert.Equal(t, 81.10, bid)
+	assert.InDelta(t, 81.20, mid, 1e-9)
+}
+
+func TestExtremesStrategy_NoValidPrices(t *testing.T) {
+	_, _, _, err := ExtremesStrategy{}.Compute([]GrinexTrade{{Price: "invalid"}})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no valid prices found in trades")
+}
+
+func TestTrimmedMeanStrategy_Outliers(t *testing.T) {
+	trades := []GrinexTrade{
+		{Price: "81.20"}, {Price: "81.25"}, {Price: "81.30"}, {Price: "81.15"}, {Price: "81.35"},
+		{Price: "81.22"}, {Price: "81.28"}, {Price: "81.24"}, {Price: "1.00"}, {Price: "900.00"},
+		{Price: "invalid"},
+	}
+
+	ask, bid, mid, err := TrimmedMeanStrategy{Trim: 0.1}.Compute(trades)
+
+	require.NoError(t, err)
+	assert.Equal(t, 81.35, ask)
+	assert.Equal(t, 81.15, bid)
+	assert.InDelta(t, 81.24875, mid, 1e-9)
+
+	_, _, extremesMid, err := ExtremesStrategy{}.Compute(trades)
+	require.NoError(t, err)
+	assert.Greater(t, extremesMid, 400.0)
+}
+
+func TestTrimmedMeanStrategy_SmallSamples(t *testing.T) {
+	ask, bid, mid, err := TrimmedMeanStrategy{Trim: 0.4}.Compute([]GrinexTrade{{Price: "81.25"}})
+	require.NoError(t, err)
+	assert.Equal(t, 81.25, ask)
+	assert.Equal(t, 81.25, bid)
+	assert.Equal(t, 81.25, mid)
+
+	ask, bid, mid, err = TrimmedMeanStrategy{Trim: 0.4}.Compute([]GrinexTrade{{Price: "81.30"}, {Price: "81.10"}})
+	require.NoError(t, err)
+	assert.Equal(t, 81.30, ask)
+	assert.Equal(t, 81.10, bid)
+	assert.InDelta(t, 81.20, mid, 1e-9)
+
+	ask, bid, mid, err = TrimmedMeanStrategy{Trim: 0.4}.Compute([]GrinexTrade{{Price: "81.30"}, {Price: "1"}, {Price: "81.20"}})
+	require.NoError(t, err)
+	assert.Equal(t, 81.20, ask)
+	assert.Equal(t, 81.20, bid)
+	assert.Equal(t, 81.20, mid)
+}
+
+func TestTrimmedMeanStrategy_Errors(t *testing.T) {
+	_, _, _, err := TrimmedMeanStrategy{Trim: 0.1}.Compute([]GrinexTrade{{Price: "invalid"}})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no valid prices found in trades")
+
+	_, _, _, err = TrimmedMeanStrategy{Trim: 0.5}.Compute([]GrinexTrade{{Price: "81.25"}})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "trim fraction must be in [0, 0.5)")
+}
+
+func TestTradesVWAP(t *testing.T) {
+	trades := []GrinexTrade{
+		{Price: "81.30", Volume: "10", Funds: "813.0"},
+		{Price: "81.20", Volume: "30", Funds: "2436.0"},
+		{Price: "81.10", Volume: "0", Funds: "0"},         // Zero volume
+		{Price: "81.00", Volume: "invalid", Funds: "810"}, // Unparseable volume
+		{Price: "80.90", Volume: "10", Funds: "invalid"},  // Unparseable funds
+		{Price: "80.80", Volume: "", Funds: ""},           // Missing volume and funds
+	}
+
+	// Only the first two trades count: (813 + 2436) / (10 + 30)
+	assert.InDelta(t, 81.225, TradesVWAP(trades, 81.30), 1e-9)
+}
+
+func TestTradesVWAP_NoValidTrades(t *testing.T) {
+	trades := []GrinexTrade{
+		{Price: "81.25", Volume: "0", Funds: "0"},
+		{Price: "81.20", Volume: "invalid", Funds: "812.0"},
+	}
+
+	assert.Equal(t, 81.25, TradesVWAP(trades, 81.25))
+}
+
+func TestTradesMedianPrice(t *testing.T) {
+	median, err := TradesMedianPrice([]GrinexTrade{{Price: "81.30"}, {Price: "81.10"}, {Price: "81.20"}})
+	require.NoError(t, err)
+	assert.Equal(t, 81.20, median)
+
+	median, err = TradesMedianPrice([]GrinexTrade{{Price: "81.30"}, {Price: "81.10"}, {Price: "81.20"}, {Price: "81.25"}})
+	require.NoError(t, err)
+	assert.InDelta(t, 81.225, median, 1e-9)
+
+	_, err = TradesMedianPrice([]GrinexTrade{{Price: "invalid"}})
+	assert.Error(t, err)
+}
+
+func TestLookupPriceStrategy(t *testing.T) {
+	strategy, err := LookupPriceStrategy(StrategyExtremes)
+	require.NoError(t, err)
+	assert.IsType(t, ExtremesStrategy{}, strategy)
+
+	strategy, err = LookupPriceStrategy(StrategyTrimmedMean)
+	require.NoError(t, err)
+	assert.Equal(t, TrimmedMeanStrategy{Trim: DefaultTrimFraction}, strategy)
+
+	_, err = LookupPriceStrategy("does-not-exist")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown price strategy")
+}
+
+func TestRegisterPriceStrategy_Custom(t *testing.T) {
+	RegisterPriceStrategy("test-constant", PriceStrategyFunc(func(trades []GrinexTrade) (ask, bid, mid float64, err error) {
+		return 2, 1, 1.5, nil
+	}))
+	defer func() {
+		strategiesMu.Lock()
+		delete(strategies, "test-constant")
+		strategiesMu.Unlock()
+	}()
+
+	assert.Contains(t, PriceStrategyNames(), "test-constant")
+
+	strategy, err := LookupPriceStrategy("test-constant")
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"id": 1, "price": "81.25", "created_at": "2025-07-28T21:22:14+03:00"}]`))
+	}))
+	defer server.Close()
+
+	service := NewGrinexService(&GrinexConfig{
+		BaseURL:       server.URL,
+		Timeout:       30 * time.Second,
+		PriceStrategy: strategy,
+	}, zap.NewNop())
+
+	rate, err := service.GetUSDTRate(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, rate.AskPrice)
+	assert.Equal(t, 1.0, rate.BidPrice)
+	assert.Equal(t, 1.5, rate.MidPrice)
+}
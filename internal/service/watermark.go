@@ -0,0 +1,48 @@
+package service
+
+import (
+	"strings"
+	"sync"
+)
+
+// TradeWatermark remembers the highest trade ID seen per market, so trades that reappear in
+// consecutive fetches are only counted once by features aggregating across fetches
+type TradeWatermark struct {
+	mu    sync.Mutex
+	maxID map[string]int64
+}
+
+// NewTradeWatermark returns an empty in-memory watermark
+func NewTradeWatermark() *TradeWatermark {
+	return &TradeWatermark{maxID: make(map[string]int64)}
+}
+
+// Filter returns the trades newer than the market's watermark and advances the watermark
+// to the highest ID among them
+func (w *TradeWatermark) Filter(market string, trades []GrinexTrade) []GrinexTrade {
+	market = strings.ToLower(market)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	last := w.maxID[market]
+	fresh := make([]GrinexTrade, 0, len(trades))
+	for _, trade := range trades {
+		if trade.ID <= last {
+			continue
+		}
+		fresh = append(fresh, trade)
+		if trade.ID > w.maxID[market] {
+			w.maxID[market] = trade.ID
+		}
+	}
+	return fresh
+}
+
+// Last returns the highest trade ID seen for a market, zero when none was seen
+func (w *TradeWatermark) Last(market string) int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.maxID[strings.ToLower(market)]
+}
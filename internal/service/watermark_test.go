@@ -0,0 +1,31 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func tradeIDs(trades []GrinexTrade) []int64 {
+	ids := make([]int64, 0, len(trades))
+	for _, trade := range trades {
+		ids = append(ids, trade.ID)
+	}
+	return ids
+}
+
+func TestTradeWatermark_Filter(t *testing.T) {
+	watermark := NewTradeWatermark()
+
+	first := watermark.Filter("usdtrub", []GrinexTrade{{ID: 3}, {ID: 2}, {ID: 1}})
+	assert.Equal(t, []int64{3, 2, 1}, tradeIDs(first))
+	assert.Equal(t, int64(3), watermark.Last("usdtrub"))
+
+	second := watermark.Filter("USDTRUB", []GrinexTrade{{ID: 5}, {ID: 4}, {ID: 3}, {ID: 2}})
+	assert.Equal(t, []int64{5, 4}, tradeIDs(second))
+	assert.Equal(t, int64(5), watermark.Last("usdtrub"))
+
+	// Markets are tracked separately
+	other := watermark.Filter("btcrub", []GrinexTrade{{ID: 2}})
+	assert.Equal(t, []int64{2}, tradeIDs(other))
+}
@@ -0,0 +1,2 @@
+-- Drop rate_levels table
+DROP TABLE IF EXISTS rate_levels;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS rate_levels (
+    id BIGSERIAL PRIMARY KEY,
+    rate_id BIGINT NOT NULL REFERENCES rates(id) ON DELETE CASCADE,
+    side VARCHAR(3) NOT NULL,
+    level INTEGER NOT NULL,
+    price DECIMAL(20, 8) NOT NULL,
+    volume DECIMAL(30, 8) NOT NULL
+);
+
+-- Index on rate_id for fetching the levels of a rate
+CREATE INDEX IF NOT EXISTS idx_rate_levels_rate_id ON rate_levels(rate_id);
@@ -0,0 +1,3 @@
+-- Drop strategy column
+DROP INDEX IF EXISTS idx_rates_trading_pair_strategy_created_at;
+ALTER TABLE rates DROP COLUMN IF EXISTS strategy;
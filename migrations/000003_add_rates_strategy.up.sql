@@ -0,0 +1,4 @@
+ALTER TABLE rates ADD COLUMN IF NOT EXISTS strategy VARCHAR(32) NOT NULL DEFAULT 'extremes';
+
+-- Index on trading_pair, strategy and created_at for filtering rates by strategy
+CREATE INDEX IF NOT EXISTS idx_rates_trading_pair_strategy_created_at ON rates(trading_pair, strategy, created_at DESC);
@@ -0,0 +1,2 @@
+-- Drop raw_timestamp column
+ALTER TABLE rates DROP COLUMN IF EXISTS raw_timestamp;
@@ -0,0 +1 @@
+ALTER TABLE rates ADD COLUMN IF NOT EXISTS raw_timestamp TIMESTAMP WITH TIME ZONE;
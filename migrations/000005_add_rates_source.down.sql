@@ -0,0 +1,3 @@
+-- Drop source column
+DROP INDEX IF EXISTS idx_rates_trading_pair_source_created_at;
+ALTER TABLE rates DROP COLUMN IF EXISTS source;
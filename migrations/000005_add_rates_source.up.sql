@@ -0,0 +1,4 @@
+ALTER TABLE rates ADD COLUMN IF NOT EXISTS source VARCHAR(32) NOT NULL DEFAULT 'trades';
+
+-- Index on trading_pair, source and created_at for filtering rates by source
+CREATE INDEX IF NOT EXISTS idx_rates_trading_pair_source_created_at ON rates(trading_pair, source, created_at DESC);
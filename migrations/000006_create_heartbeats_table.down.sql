@@ -0,0 +1,2 @@
+-- Drop heartbeats table
+DROP TABLE IF EXISTS heartbeats;
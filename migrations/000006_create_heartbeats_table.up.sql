@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS heartbeats (
+    id BIGSERIAL PRIMARY KEY,
+    instance VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Index on created_at for checking the latest heartbeat
+CREATE INDEX IF NOT EXISTS idx_heartbeats_created_at ON heartbeats(created_at DESC);
@@ -0,0 +1,2 @@
+-- Drop vwap column
+ALTER TABLE rates DROP COLUMN IF EXISTS vwap;
@@ -0,0 +1 @@
+ALTER TABLE rates ADD COLUMN IF NOT EXISTS vwap DECIMAL(20, 8);
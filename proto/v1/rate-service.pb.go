@@ -9,6 +9,8 @@ package pb
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
@@ -22,8 +24,164 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type PriceFormat int32
+
+const (
+	// Prices are only returned as floats
+	PriceFormat_PRICE_FORMAT_UNSPECIFIED PriceFormat = 0
+	// Prices are also returned as integer minor units, e.g. kopecks
+	PriceFormat_PRICE_FORMAT_MINOR_UNITS PriceFormat = 1
+)
+
+// Enum value maps for PriceFormat.
+var (
+	PriceFormat_name = map[int32]string{
+		0: "PRICE_FORMAT_UNSPECIFIED",
+		1: "PRICE_FORMAT_MINOR_UNITS",
+	}
+	PriceFormat_value = map[string]int32{
+		"PRICE_FORMAT_UNSPECIFIED": 0,
+		"PRICE_FORMAT_MINOR_UNITS": 1,
+	}
+)
+
+func (x PriceFormat) Enum() *PriceFormat {
+	p := new(PriceFormat)
+	*p = x
+	return p
+}
+
+func (x PriceFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PriceFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_v1_rate_service_proto_enumTypes[0].Descriptor()
+}
+
+func (PriceFormat) Type() protoreflect.EnumType {
+	return &file_proto_v1_rate_service_proto_enumTypes[0]
+}
+
+func (x PriceFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PriceFormat.Descriptor instead.
+func (PriceFormat) EnumDescriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{0}
+}
+
+type RateSource int32
+
+const (
+	// The source configured with GRINEX_RATE_SOURCE
+	RateSource_RATE_SOURCE_UNSPECIFIED RateSource = 0
+	// Highest and lowest prices of recent trades, per GRINEX_PRICE_STRATEGY
+	RateSource_RATE_SOURCE_TRADES RateSource = 1
+	// Best ask and bid of the order book, falling back to trades when a side is empty
+	RateSource_RATE_SOURCE_ORDER_BOOK RateSource = 2
+)
+
+// Enum value maps for RateSource.
+var (
+	RateSource_name = map[int32]string{
+		0: "RATE_SOURCE_UNSPECIFIED",
+		1: "RATE_SOURCE_TRADES",
+		2: "RATE_SOURCE_ORDER_BOOK",
+	}
+	RateSource_value = map[string]int32{
+		"RATE_SOURCE_UNSPECIFIED": 0,
+		"RATE_SOURCE_TRADES":      1,
+		"RATE_SOURCE_ORDER_BOOK":  2,
+	}
+)
+
+func (x RateSource) Enum() *RateSource {
+	p := new(RateSource)
+	*p = x
+	return p
+}
+
+func (x RateSource) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RateSource) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_v1_rate_service_proto_enumTypes[1].Descriptor()
+}
+
+func (RateSource) Type() protoreflect.EnumType {
+	return &file_proto_v1_rate_service_proto_enumTypes[1]
+}
+
+func (x RateSource) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RateSource.Descriptor instead.
+func (RateSource) EnumDescriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{1}
+}
+
+type AlertDirection int32
+
+const (
+	AlertDirection_ALERT_DIRECTION_UNSPECIFIED AlertDirection = 0
+	AlertDirection_ALERT_DIRECTION_ABOVE       AlertDirection = 1
+	AlertDirection_ALERT_DIRECTION_BELOW       AlertDirection = 2
+)
+
+// Enum value maps for AlertDirection.
+var (
+	AlertDirection_name = map[int32]string{
+		0: "ALERT_DIRECTION_UNSPECIFIED",
+		1: "ALERT_DIRECTION_ABOVE",
+		2: "ALERT_DIRECTION_BELOW",
+	}
+	AlertDirection_value = map[string]int32{
+		"ALERT_DIRECTION_UNSPECIFIED": 0,
+		"ALERT_DIRECTION_ABOVE":       1,
+		"ALERT_DIRECTION_BELOW":       2,
+	}
+)
+
+func (x AlertDirection) Enum() *AlertDirection {
+	p := new(AlertDirection)
+	*p = x
+	return p
+}
+
+func (x AlertDirection) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AlertDirection) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_v1_rate_service_proto_enumTypes[2].Descriptor()
+}
+
+func (AlertDirection) Type() protoreflect.EnumType {
+	return &file_proto_v1_rate_service_proto_enumTypes[2]
+}
+
+func (x AlertDirection) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AlertDirection.Descriptor instead.
+func (AlertDirection) EnumDescriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{2}
+}
+
 type GetRatesReq struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Timezone    string                 `protobuf:"bytes,1,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	PriceFormat PriceFormat            `protobuf:"varint,2,opt,name=price_format,json=priceFormat,proto3,enum=rateservice.v1.PriceFormat" json:"price_format,omitempty"`
+	// Response fields to return, e.g. "mid_price". Other fields are left unset. Empty returns all fields.
+	Fields *fieldmaskpb.FieldMask `protobuf:"bytes,3,opt,name=fields,proto3" json:"fields,omitempty"`
+	Source RateSource             `protobuf:"varint,4,opt,name=source,proto3,enum=rateservice.v1.RateSource" json:"source,omitempty"`
+	// Pair to return the rate of, e.g. "USDT/RUB" or "btcrub". Defaults to USDT/RUB.
+	TradingPair   string `protobuf:"bytes,5,opt,name=trading_pair,json=tradingPair,proto3" json:"trading_pair,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -58,12 +216,66 @@ func (*GetRatesReq) Descriptor() ([]byte, []int) {
 	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{0}
 }
 
+func (x *GetRatesReq) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *GetRatesReq) GetPriceFormat() PriceFormat {
+	if x != nil {
+		return x.PriceFormat
+	}
+	return PriceFormat_PRICE_FORMAT_UNSPECIFIED
+}
+
+func (x *GetRatesReq) GetFields() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *GetRatesReq) GetSource() RateSource {
+	if x != nil {
+		return x.Source
+	}
+	return RateSource_RATE_SOURCE_UNSPECIFIED
+}
+
+func (x *GetRatesReq) GetTradingPair() string {
+	if x != nil {
+		return x.TradingPair
+	}
+	return ""
+}
+
 type GetRatesResp struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	TradingPair   string                 `protobuf:"bytes,1,opt,name=trading_pair,json=tradingPair,proto3" json:"trading_pair,omitempty"`
-	AskPrice      float64                `protobuf:"fixed64,2,opt,name=ask_price,json=askPrice,proto3" json:"ask_price,omitempty"`
-	BidPrice      float64                `protobuf:"fixed64,3,opt,name=bid_price,json=bidPrice,proto3" json:"bid_price,omitempty"`
-	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	TradingPair string                 `protobuf:"bytes,1,opt,name=trading_pair,json=tradingPair,proto3" json:"trading_pair,omitempty"`
+	AskPrice    float64                `protobuf:"fixed64,2,opt,name=ask_price,json=askPrice,proto3" json:"ask_price,omitempty"`
+	BidPrice    float64                `protobuf:"fixed64,3,opt,name=bid_price,json=bidPrice,proto3" json:"bid_price,omitempty"`
+	Timestamp   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	LocalTime   string                 `protobuf:"bytes,5,opt,name=local_time,json=localTime,proto3" json:"local_time,omitempty"`
+	Stale       bool                   `protobuf:"varint,6,opt,name=stale,proto3" json:"stale,omitempty"`
+	// Prices scaled by 10^price_decimals and rounded, set with PRICE_FORMAT_MINOR_UNITS
+	AskMinor      int64   `protobuf:"varint,7,opt,name=ask_minor,json=askMinor,proto3" json:"ask_minor,omitempty"`
+	BidMinor      int64   `protobuf:"varint,8,opt,name=bid_minor,json=bidMinor,proto3" json:"bid_minor,omitempty"`
+	PriceDecimals int32   `protobuf:"varint,9,opt,name=price_decimals,json=priceDecimals,proto3" json:"price_decimals,omitempty"`
+	MidPrice      float64 `protobuf:"fixed64,10,opt,name=mid_price,json=midPrice,proto3" json:"mid_price,omitempty"`
+	// Prices quoted to end users with the GRINEX_FEE_BPS fee applied, equal to the raw prices without a fee
+	ClientAsk float64 `protobuf:"fixed64,11,opt,name=client_ask,json=clientAsk,proto3" json:"client_ask,omitempty"`
+	ClientBid float64 `protobuf:"fixed64,12,opt,name=client_bid,json=clientBid,proto3" json:"client_bid,omitempty"`
+	FeeBps    int32   `protobuf:"varint,13,opt,name=fee_bps,json=feeBps,proto3" json:"fee_bps,omitempty"`
+	// When the service fetched the rate from Grinex, stored as created_at, as opposed to the market time in timestamp
+	IngestedAt *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=ingested_at,json=ingestedAt,proto3" json:"ingested_at,omitempty"`
+	// Volume weighted average price of the trades the rate was computed from, sum(funds) / sum(volume), unset for order book rates
+	Vwap float64 `protobuf:"fixed64,15,opt,name=vwap,proto3" json:"vwap,omitempty"`
+	// Where the rate came from: grinex, cache or database, as in the x-data-source trailer
+	Source string `protobuf:"bytes,16,opt,name=source,proto3" json:"source,omitempty"`
+	// How long ago a stale rate was stored, set only when stale
+	Age           *durationpb.Duration `protobuf:"bytes,17,opt,name=age,proto3" json:"age,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -126,6 +338,97 @@ func (x *GetRatesResp) GetTimestamp() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *GetRatesResp) GetLocalTime() string {
+	if x != nil {
+		return x.LocalTime
+	}
+	return ""
+}
+
+func (x *GetRatesResp) GetStale() bool {
+	if x != nil {
+		return x.Stale
+	}
+	return false
+}
+
+func (x *GetRatesResp) GetAskMinor() int64 {
+	if x != nil {
+		return x.AskMinor
+	}
+	return 0
+}
+
+func (x *GetRatesResp) GetBidMinor() int64 {
+	if x != nil {
+		return x.BidMinor
+	}
+	return 0
+}
+
+func (x *GetRatesResp) GetPriceDecimals() int32 {
+	if x != nil {
+		return x.PriceDecimals
+	}
+	return 0
+}
+
+func (x *GetRatesResp) GetMidPrice() float64 {
+	if x != nil {
+		return x.MidPrice
+	}
+	return 0
+}
+
+func (x *GetRatesResp) GetClientAsk() float64 {
+	if x != nil {
+		return x.ClientAsk
+	}
+	return 0
+}
+
+func (x *GetRatesResp) GetClientBid() float64 {
+	if x != nil {
+		return x.ClientBid
+	}
+	return 0
+}
+
+func (x *GetRatesResp) GetFeeBps() int32 {
+	if x != nil {
+		return x.FeeBps
+	}
+	return 0
+}
+
+func (x *GetRatesResp) GetIngestedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.IngestedAt
+	}
+	return nil
+}
+
+func (x *GetRatesResp) GetVwap() float64 {
+	if x != nil {
+		return x.Vwap
+	}
+	return 0
+}
+
+func (x *GetRatesResp) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *GetRatesResp) GetAge() *durationpb.Duration {
+	if x != nil {
+		return x.Age
+	}
+	return nil
+}
+
 type HealthcheckReq struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -163,11 +466,18 @@ func (*HealthcheckReq) Descriptor() ([]byte, []int) {
 }
 
 type HealthcheckResp struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// healthy, degraded when Grinex fails or unhealthy when the database fails
+	Status  string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// Individual checks of the dependencies, grinex being skipped in db_only serve mode
+	Components []*ComponentHealth `protobuf:"bytes,3,rep,name=components,proto3" json:"components,omitempty"`
+	// Weighted health score from 0 to 100, zero when all HEALTH_WEIGHT_* are zero
+	Score float64 `protobuf:"fixed64,4,opt,name=score,proto3" json:"score,omitempty"`
+	// Inputs of the score, whose points add up to it
+	ScoreContributions []*ScoreContribution `protobuf:"bytes,5,rep,name=score_contributions,json=scoreContributions,proto3" json:"score_contributions,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *HealthcheckResp) Reset() {
@@ -214,24 +524,2956 @@ func (x *HealthcheckResp) GetMessage() string {
 	return ""
 }
 
-var File_proto_v1_rate_service_proto protoreflect.FileDescriptor
+func (x *HealthcheckResp) GetComponents() []*ComponentHealth {
+	if x != nil {
+		return x.Components
+	}
+	return nil
+}
 
-const file_proto_v1_rate_service_proto_rawDesc = "" +
-	"\n" +
-	"\x1bproto/v1/rate-service.proto\x12\x0erateservice.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\r\n" +
-	"\vGetRatesReq\"\xa5\x01\n" +
-	"\fGetRatesResp\x12!\n" +
-	"\ftrading_pair\x18\x01 \x01(\tR\vtradingPair\x12\x1b\n" +
-	"\task_price\x18\x02 \x01(\x01R\baskPrice\x12\x1b\n" +
-	"\tbid_price\x18\x03 \x01(\x01R\bbidPrice\x128\n" +
-	"\ttimestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\x10\n" +
-	"\x0eHealthcheckReq\"C\n" +
-	"\x0fHealthcheckResp\x12\x16\n" +
-	"\x06status\x18\x01 \x01(\tR\x06status\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage2\xa8\x01\n" +
+func (x *HealthcheckResp) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *HealthcheckResp) GetScoreContributions() []*ScoreContribution {
+	if x != nil {
+		return x.ScoreContributions
+	}
+	return nil
+}
+
+type ScoreContribution struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// database, grinex or freshness
+	Name   string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Weight int32  `protobuf:"varint,2,opt,name=weight,proto3" json:"weight,omitempty"`
+	// Score of the input alone from 0 to 100
+	Score float64 `protobuf:"fixed64,3,opt,name=score,proto3" json:"score,omitempty"`
+	// Share of the overall score, score weighted by the input's part of the total weight
+	Points        float64 `protobuf:"fixed64,4,opt,name=points,proto3" json:"points,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScoreContribution) Reset() {
+	*x = ScoreContribution{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScoreContribution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScoreContribution) ProtoMessage() {}
+
+func (x *ScoreContribution) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScoreContribution.ProtoReflect.Descriptor instead.
+func (*ScoreContribution) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ScoreContribution) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ScoreContribution) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *ScoreContribution) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *ScoreContribution) GetPoints() float64 {
+	if x != nil {
+		return x.Points
+	}
+	return 0
+}
+
+type ComponentHealth struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// database or grinex
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// healthy or unhealthy
+	Status    string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	LatencyMs int64  `protobuf:"varint,3,opt,name=latency_ms,json=latencyMs,proto3" json:"latency_ms,omitempty"`
+	// Why the check failed, empty when healthy
+	Error         string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComponentHealth) Reset() {
+	*x = ComponentHealth{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComponentHealth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComponentHealth) ProtoMessage() {}
+
+func (x *ComponentHealth) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComponentHealth.ProtoReflect.Descriptor instead.
+func (*ComponentHealth) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ComponentHealth) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ComponentHealth) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ComponentHealth) GetLatencyMs() int64 {
+	if x != nil {
+		return x.LatencyMs
+	}
+	return 0
+}
+
+func (x *ComponentHealth) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetVolatilityReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TradingPair   string                 `protobuf:"bytes,1,opt,name=trading_pair,json=tradingPair,proto3" json:"trading_pair,omitempty"`
+	Window        *durationpb.Duration   `protobuf:"bytes,2,opt,name=window,proto3" json:"window,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetVolatilityReq) Reset() {
+	*x = GetVolatilityReq{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetVolatilityReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetVolatilityReq) ProtoMessage() {}
+
+func (x *GetVolatilityReq) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetVolatilityReq.ProtoReflect.Descriptor instead.
+func (*GetVolatilityReq) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetVolatilityReq) GetTradingPair() string {
+	if x != nil {
+		return x.TradingPair
+	}
+	return ""
+}
+
+func (x *GetVolatilityReq) GetWindow() *durationpb.Duration {
+	if x != nil {
+		return x.Window
+	}
+	return nil
+}
+
+type GetVolatilityResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TradingPair   string                 `protobuf:"bytes,1,opt,name=trading_pair,json=tradingPair,proto3" json:"trading_pair,omitempty"`
+	Volatility    float64                `protobuf:"fixed64,2,opt,name=volatility,proto3" json:"volatility,omitempty"`
+	Window        *durationpb.Duration   `protobuf:"bytes,3,opt,name=window,proto3" json:"window,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetVolatilityResp) Reset() {
+	*x = GetVolatilityResp{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetVolatilityResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetVolatilityResp) ProtoMessage() {}
+
+func (x *GetVolatilityResp) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetVolatilityResp.ProtoReflect.Descriptor instead.
+func (*GetVolatilityResp) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetVolatilityResp) GetTradingPair() string {
+	if x != nil {
+		return x.TradingPair
+	}
+	return ""
+}
+
+func (x *GetVolatilityResp) GetVolatility() float64 {
+	if x != nil {
+		return x.Volatility
+	}
+	return 0
+}
+
+func (x *GetVolatilityResp) GetWindow() *durationpb.Duration {
+	if x != nil {
+		return x.Window
+	}
+	return nil
+}
+
+type ClockInfoReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClockInfoReq) Reset() {
+	*x = ClockInfoReq{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClockInfoReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClockInfoReq) ProtoMessage() {}
+
+func (x *ClockInfoReq) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClockInfoReq.ProtoReflect.Descriptor instead.
+func (*ClockInfoReq) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{8}
+}
+
+type ClockInfoResp struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	ServerTime *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=server_time,json=serverTime,proto3" json:"server_time,omitempty"`
+	GrinexTime *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=grinex_time,json=grinexTime,proto3" json:"grinex_time,omitempty"`
+	// Server time minus the time of the latest Grinex trade
+	Drift         *durationpb.Duration `protobuf:"bytes,3,opt,name=drift,proto3" json:"drift,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClockInfoResp) Reset() {
+	*x = ClockInfoResp{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClockInfoResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClockInfoResp) ProtoMessage() {}
+
+func (x *ClockInfoResp) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClockInfoResp.ProtoReflect.Descriptor instead.
+func (*ClockInfoResp) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ClockInfoResp) GetServerTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ServerTime
+	}
+	return nil
+}
+
+func (x *ClockInfoResp) GetGrinexTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.GrinexTime
+	}
+	return nil
+}
+
+func (x *ClockInfoResp) GetDrift() *durationpb.Duration {
+	if x != nil {
+		return x.Drift
+	}
+	return nil
+}
+
+type AlertReq struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	TradingPair string                 `protobuf:"bytes,1,opt,name=trading_pair,json=tradingPair,proto3" json:"trading_pair,omitempty"`
+	Threshold   float64                `protobuf:"fixed64,2,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	Direction   AlertDirection         `protobuf:"varint,3,opt,name=direction,proto3,enum=rateservice.v1.AlertDirection" json:"direction,omitempty"`
+	// Keep the stream open after the first alert instead of completing it
+	Continuous    bool `protobuf:"varint,4,opt,name=continuous,proto3" json:"continuous,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AlertReq) Reset() {
+	*x = AlertReq{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AlertReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AlertReq) ProtoMessage() {}
+
+func (x *AlertReq) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AlertReq.ProtoReflect.Descriptor instead.
+func (*AlertReq) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *AlertReq) GetTradingPair() string {
+	if x != nil {
+		return x.TradingPair
+	}
+	return ""
+}
+
+func (x *AlertReq) GetThreshold() float64 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *AlertReq) GetDirection() AlertDirection {
+	if x != nil {
+		return x.Direction
+	}
+	return AlertDirection_ALERT_DIRECTION_UNSPECIFIED
+}
+
+func (x *AlertReq) GetContinuous() bool {
+	if x != nil {
+		return x.Continuous
+	}
+	return false
+}
+
+type AlertResp struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	TradingPair string                 `protobuf:"bytes,1,opt,name=trading_pair,json=tradingPair,proto3" json:"trading_pair,omitempty"`
+	Threshold   float64                `protobuf:"fixed64,2,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	Direction   AlertDirection         `protobuf:"varint,3,opt,name=direction,proto3,enum=rateservice.v1.AlertDirection" json:"direction,omitempty"`
+	// Mid price that crossed the threshold
+	Price         float64                `protobuf:"fixed64,4,opt,name=price,proto3" json:"price,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AlertResp) Reset() {
+	*x = AlertResp{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AlertResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AlertResp) ProtoMessage() {}
+
+func (x *AlertResp) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AlertResp.ProtoReflect.Descriptor instead.
+func (*AlertResp) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *AlertResp) GetTradingPair() string {
+	if x != nil {
+		return x.TradingPair
+	}
+	return ""
+}
+
+func (x *AlertResp) GetThreshold() float64 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *AlertResp) GetDirection() AlertDirection {
+	if x != nil {
+		return x.Direction
+	}
+	return AlertDirection_ALERT_DIRECTION_UNSPECIFIED
+}
+
+func (x *AlertResp) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *AlertResp) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+type ReplayReq struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	TradingPair string                 `protobuf:"bytes,1,opt,name=trading_pair,json=tradingPair,proto3" json:"trading_pair,omitempty"`
+	Start       *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
+	End         *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`
+	// Playback speed multiplier, e.g. 60 replays an hour of rates in a minute. Defaults to 1.
+	Speed         float64 `protobuf:"fixed64,4,opt,name=speed,proto3" json:"speed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayReq) Reset() {
+	*x = ReplayReq{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayReq) ProtoMessage() {}
+
+func (x *ReplayReq) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayReq.ProtoReflect.Descriptor instead.
+func (*ReplayReq) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ReplayReq) GetTradingPair() string {
+	if x != nil {
+		return x.TradingPair
+	}
+	return ""
+}
+
+func (x *ReplayReq) GetStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *ReplayReq) GetEnd() *timestamppb.Timestamp {
+	if x != nil {
+		return x.End
+	}
+	return nil
+}
+
+func (x *ReplayReq) GetSpeed() float64 {
+	if x != nil {
+		return x.Speed
+	}
+	return 0
+}
+
+type ReplayResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TradingPair   string                 `protobuf:"bytes,1,opt,name=trading_pair,json=tradingPair,proto3" json:"trading_pair,omitempty"`
+	AskPrice      float64                `protobuf:"fixed64,2,opt,name=ask_price,json=askPrice,proto3" json:"ask_price,omitempty"`
+	BidPrice      float64                `protobuf:"fixed64,3,opt,name=bid_price,json=bidPrice,proto3" json:"bid_price,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayResp) Reset() {
+	*x = ReplayResp{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayResp) ProtoMessage() {}
+
+func (x *ReplayResp) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayResp.ProtoReflect.Descriptor instead.
+func (*ReplayResp) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ReplayResp) GetTradingPair() string {
+	if x != nil {
+		return x.TradingPair
+	}
+	return ""
+}
+
+func (x *ReplayResp) GetAskPrice() float64 {
+	if x != nil {
+		return x.AskPrice
+	}
+	return 0
+}
+
+func (x *ReplayResp) GetBidPrice() float64 {
+	if x != nil {
+		return x.BidPrice
+	}
+	return 0
+}
+
+func (x *ReplayResp) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *ReplayResp) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type SetMaintenanceReq struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Enabled bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// Returned to clients of data RPCs while maintenance is on
+	Message       string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetMaintenanceReq) Reset() {
+	*x = SetMaintenanceReq{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetMaintenanceReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetMaintenanceReq) ProtoMessage() {}
+
+func (x *SetMaintenanceReq) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetMaintenanceReq.ProtoReflect.Descriptor instead.
+func (*SetMaintenanceReq) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SetMaintenanceReq) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *SetMaintenanceReq) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type SetMaintenanceResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Enabled       bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetMaintenanceResp) Reset() {
+	*x = SetMaintenanceResp{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetMaintenanceResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetMaintenanceResp) ProtoMessage() {}
+
+func (x *SetMaintenanceResp) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetMaintenanceResp.ProtoReflect.Descriptor instead.
+func (*SetMaintenanceResp) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SetMaintenanceResp) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *SetMaintenanceResp) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type GetTWAPReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TradingPair   string                 `protobuf:"bytes,1,opt,name=trading_pair,json=tradingPair,proto3" json:"trading_pair,omitempty"`
+	Start         *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
+	End           *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTWAPReq) Reset() {
+	*x = GetTWAPReq{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTWAPReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTWAPReq) ProtoMessage() {}
+
+func (x *GetTWAPReq) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTWAPReq.ProtoReflect.Descriptor instead.
+func (*GetTWAPReq) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetTWAPReq) GetTradingPair() string {
+	if x != nil {
+		return x.TradingPair
+	}
+	return ""
+}
+
+func (x *GetTWAPReq) GetStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *GetTWAPReq) GetEnd() *timestamppb.Timestamp {
+	if x != nil {
+		return x.End
+	}
+	return nil
+}
+
+type GetTWAPResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TradingPair   string                 `protobuf:"bytes,1,opt,name=trading_pair,json=tradingPair,proto3" json:"trading_pair,omitempty"`
+	Twap          float64                `protobuf:"fixed64,2,opt,name=twap,proto3" json:"twap,omitempty"`
+	Start         *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=start,proto3" json:"start,omitempty"`
+	End           *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=end,proto3" json:"end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTWAPResp) Reset() {
+	*x = GetTWAPResp{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTWAPResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTWAPResp) ProtoMessage() {}
+
+func (x *GetTWAPResp) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTWAPResp.ProtoReflect.Descriptor instead.
+func (*GetTWAPResp) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetTWAPResp) GetTradingPair() string {
+	if x != nil {
+		return x.TradingPair
+	}
+	return ""
+}
+
+func (x *GetTWAPResp) GetTwap() float64 {
+	if x != nil {
+		return x.Twap
+	}
+	return 0
+}
+
+func (x *GetTWAPResp) GetStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *GetTWAPResp) GetEnd() *timestamppb.Timestamp {
+	if x != nil {
+		return x.End
+	}
+	return nil
+}
+
+type CompositeWeight struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TradingPair   string                 `protobuf:"bytes,1,opt,name=trading_pair,json=tradingPair,proto3" json:"trading_pair,omitempty"`
+	Weight        float64                `protobuf:"fixed64,2,opt,name=weight,proto3" json:"weight,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompositeWeight) Reset() {
+	*x = CompositeWeight{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompositeWeight) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompositeWeight) ProtoMessage() {}
+
+func (x *CompositeWeight) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompositeWeight.ProtoReflect.Descriptor instead.
+func (*CompositeWeight) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *CompositeWeight) GetTradingPair() string {
+	if x != nil {
+		return x.TradingPair
+	}
+	return ""
+}
+
+func (x *CompositeWeight) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+type CompositeReq struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Pairs []*CompositeWeight     `protobuf:"bytes,1,rep,name=pairs,proto3" json:"pairs,omitempty"`
+	// Leave out pairs without a live rate and renormalize the remaining weights instead of failing
+	ExcludeMissing bool `protobuf:"varint,2,opt,name=exclude_missing,json=excludeMissing,proto3" json:"exclude_missing,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CompositeReq) Reset() {
+	*x = CompositeReq{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompositeReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompositeReq) ProtoMessage() {}
+
+func (x *CompositeReq) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompositeReq.ProtoReflect.Descriptor instead.
+func (*CompositeReq) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *CompositeReq) GetPairs() []*CompositeWeight {
+	if x != nil {
+		return x.Pairs
+	}
+	return nil
+}
+
+func (x *CompositeReq) GetExcludeMissing() bool {
+	if x != nil {
+		return x.ExcludeMissing
+	}
+	return false
+}
+
+type CompositeComponent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TradingPair   string                 `protobuf:"bytes,1,opt,name=trading_pair,json=tradingPair,proto3" json:"trading_pair,omitempty"`
+	Weight        float64                `protobuf:"fixed64,2,opt,name=weight,proto3" json:"weight,omitempty"`
+	MidPrice      float64                `protobuf:"fixed64,3,opt,name=mid_price,json=midPrice,proto3" json:"mid_price,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompositeComponent) Reset() {
+	*x = CompositeComponent{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompositeComponent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompositeComponent) ProtoMessage() {}
+
+func (x *CompositeComponent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompositeComponent.ProtoReflect.Descriptor instead.
+func (*CompositeComponent) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *CompositeComponent) GetTradingPair() string {
+	if x != nil {
+		return x.TradingPair
+	}
+	return ""
+}
+
+func (x *CompositeComponent) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *CompositeComponent) GetMidPrice() float64 {
+	if x != nil {
+		return x.MidPrice
+	}
+	return 0
+}
+
+func (x *CompositeComponent) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+type CompositeResp struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Weighted average of the component mid prices
+	MidPrice   float64               `protobuf:"fixed64,1,opt,name=mid_price,json=midPrice,proto3" json:"mid_price,omitempty"`
+	Components []*CompositeComponent `protobuf:"bytes,2,rep,name=components,proto3" json:"components,omitempty"`
+	// Pairs left out with exclude_missing
+	Excluded []string `protobuf:"bytes,3,rep,name=excluded,proto3" json:"excluded,omitempty"`
+	// Time of the oldest component rate
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompositeResp) Reset() {
+	*x = CompositeResp{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompositeResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompositeResp) ProtoMessage() {}
+
+func (x *CompositeResp) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompositeResp.ProtoReflect.Descriptor instead.
+func (*CompositeResp) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *CompositeResp) GetMidPrice() float64 {
+	if x != nil {
+		return x.MidPrice
+	}
+	return 0
+}
+
+func (x *CompositeResp) GetComponents() []*CompositeComponent {
+	if x != nil {
+		return x.Components
+	}
+	return nil
+}
+
+func (x *CompositeResp) GetExcluded() []string {
+	if x != nil {
+		return x.Excluded
+	}
+	return nil
+}
+
+func (x *CompositeResp) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+type FindGapsReq struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	TradingPair string                 `protobuf:"bytes,1,opt,name=trading_pair,json=tradingPair,proto3" json:"trading_pair,omitempty"`
+	Start       *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
+	End         *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`
+	// Gaps between consecutive stored rates longer than this are reported
+	MaxGap        *durationpb.Duration `protobuf:"bytes,4,opt,name=max_gap,json=maxGap,proto3" json:"max_gap,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindGapsReq) Reset() {
+	*x = FindGapsReq{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindGapsReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindGapsReq) ProtoMessage() {}
+
+func (x *FindGapsReq) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindGapsReq.ProtoReflect.Descriptor instead.
+func (*FindGapsReq) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *FindGapsReq) GetTradingPair() string {
+	if x != nil {
+		return x.TradingPair
+	}
+	return ""
+}
+
+func (x *FindGapsReq) GetStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *FindGapsReq) GetEnd() *timestamppb.Timestamp {
+	if x != nil {
+		return x.End
+	}
+	return nil
+}
+
+func (x *FindGapsReq) GetMaxGap() *durationpb.Duration {
+	if x != nil {
+		return x.MaxGap
+	}
+	return nil
+}
+
+type Gap struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// created_at of the stored rates before and after the gap
+	Start         *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	End           *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=end,proto3" json:"end,omitempty"`
+	Duration      *durationpb.Duration   `protobuf:"bytes,3,opt,name=duration,proto3" json:"duration,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Gap) Reset() {
+	*x = Gap{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Gap) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Gap) ProtoMessage() {}
+
+func (x *Gap) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Gap.ProtoReflect.Descriptor instead.
+func (*Gap) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *Gap) GetStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *Gap) GetEnd() *timestamppb.Timestamp {
+	if x != nil {
+		return x.End
+	}
+	return nil
+}
+
+func (x *Gap) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+type FindGapsResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TradingPair   string                 `protobuf:"bytes,1,opt,name=trading_pair,json=tradingPair,proto3" json:"trading_pair,omitempty"`
+	Gaps          []*Gap                 `protobuf:"bytes,2,rep,name=gaps,proto3" json:"gaps,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindGapsResp) Reset() {
+	*x = FindGapsResp{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindGapsResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindGapsResp) ProtoMessage() {}
+
+func (x *FindGapsResp) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindGapsResp.ProtoReflect.Descriptor instead.
+func (*FindGapsResp) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *FindGapsResp) GetTradingPair() string {
+	if x != nil {
+		return x.TradingPair
+	}
+	return ""
+}
+
+func (x *FindGapsResp) GetGaps() []*Gap {
+	if x != nil {
+		return x.Gaps
+	}
+	return nil
+}
+
+type GetDepthReq struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	TradingPair string                 `protobuf:"bytes,1,opt,name=trading_pair,json=tradingPair,proto3" json:"trading_pair,omitempty"`
+	// Levels per side, defaults to GRINEX_DEPTH_LIMIT
+	Limit         int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDepthReq) Reset() {
+	*x = GetDepthReq{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDepthReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDepthReq) ProtoMessage() {}
+
+func (x *GetDepthReq) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDepthReq.ProtoReflect.Descriptor instead.
+func (*GetDepthReq) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *GetDepthReq) GetTradingPair() string {
+	if x != nil {
+		return x.TradingPair
+	}
+	return ""
+}
+
+func (x *GetDepthReq) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type DepthLevel struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Price         float64                `protobuf:"fixed64,1,opt,name=price,proto3" json:"price,omitempty"`
+	Volume        float64                `protobuf:"fixed64,2,opt,name=volume,proto3" json:"volume,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DepthLevel) Reset() {
+	*x = DepthLevel{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DepthLevel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DepthLevel) ProtoMessage() {}
+
+func (x *DepthLevel) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DepthLevel.ProtoReflect.Descriptor instead.
+func (*DepthLevel) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *DepthLevel) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *DepthLevel) GetVolume() float64 {
+	if x != nil {
+		return x.Volume
+	}
+	return 0
+}
+
+type GetDepthResp struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	TradingPair string                 `protobuf:"bytes,1,opt,name=trading_pair,json=tradingPair,proto3" json:"trading_pair,omitempty"`
+	// Asks ascending and bids descending by price, best level first
+	Asks []*DepthLevel `protobuf:"bytes,2,rep,name=asks,proto3" json:"asks,omitempty"`
+	Bids []*DepthLevel `protobuf:"bytes,3,rep,name=bids,proto3" json:"bids,omitempty"`
+	// Time of the snapshot reported by Grinex
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDepthResp) Reset() {
+	*x = GetDepthResp{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDepthResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDepthResp) ProtoMessage() {}
+
+func (x *GetDepthResp) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDepthResp.ProtoReflect.Descriptor instead.
+func (*GetDepthResp) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *GetDepthResp) GetTradingPair() string {
+	if x != nil {
+		return x.TradingPair
+	}
+	return ""
+}
+
+func (x *GetDepthResp) GetAsks() []*DepthLevel {
+	if x != nil {
+		return x.Asks
+	}
+	return nil
+}
+
+func (x *GetDepthResp) GetBids() []*DepthLevel {
+	if x != nil {
+		return x.Bids
+	}
+	return nil
+}
+
+func (x *GetDepthResp) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+type StreamRatesReq struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Pair to stream the rate of, e.g. "USDT/RUB" or "btcrub". Defaults to USDT/RUB.
+	TradingPair string `protobuf:"bytes,1,opt,name=trading_pair,json=tradingPair,proto3" json:"trading_pair,omitempty"`
+	// Time between fetches, defaults to 1s and is raised to at least 500ms
+	Interval      *durationpb.Duration `protobuf:"bytes,2,opt,name=interval,proto3" json:"interval,omitempty"`
+	Timezone      string               `protobuf:"bytes,3,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	Source        RateSource           `protobuf:"varint,4,opt,name=source,proto3,enum=rateservice.v1.RateSource" json:"source,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamRatesReq) Reset() {
+	*x = StreamRatesReq{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamRatesReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamRatesReq) ProtoMessage() {}
+
+func (x *StreamRatesReq) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamRatesReq.ProtoReflect.Descriptor instead.
+func (*StreamRatesReq) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *StreamRatesReq) GetTradingPair() string {
+	if x != nil {
+		return x.TradingPair
+	}
+	return ""
+}
+
+func (x *StreamRatesReq) GetInterval() *durationpb.Duration {
+	if x != nil {
+		return x.Interval
+	}
+	return nil
+}
+
+func (x *StreamRatesReq) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *StreamRatesReq) GetSource() RateSource {
+	if x != nil {
+		return x.Source
+	}
+	return RateSource_RATE_SOURCE_UNSPECIFIED
+}
+
+type GetHistoricalRatesReq struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	TradingPair string                 `protobuf:"bytes,1,opt,name=trading_pair,json=tradingPair,proto3" json:"trading_pair,omitempty"`
+	Start       *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
+	End         *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`
+	// Maximum number of rates to return, newest first. Zero returns all rates in the range.
+	Limit         int32 `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHistoricalRatesReq) Reset() {
+	*x = GetHistoricalRatesReq{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHistoricalRatesReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHistoricalRatesReq) ProtoMessage() {}
+
+func (x *GetHistoricalRatesReq) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHistoricalRatesReq.ProtoReflect.Descriptor instead.
+func (*GetHistoricalRatesReq) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *GetHistoricalRatesReq) GetTradingPair() string {
+	if x != nil {
+		return x.TradingPair
+	}
+	return ""
+}
+
+func (x *GetHistoricalRatesReq) GetStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *GetHistoricalRatesReq) GetEnd() *timestamppb.Timestamp {
+	if x != nil {
+		return x.End
+	}
+	return nil
+}
+
+func (x *GetHistoricalRatesReq) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type HistoricalRate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TradingPair   string                 `protobuf:"bytes,1,opt,name=trading_pair,json=tradingPair,proto3" json:"trading_pair,omitempty"`
+	AskPrice      float64                `protobuf:"fixed64,2,opt,name=ask_price,json=askPrice,proto3" json:"ask_price,omitempty"`
+	BidPrice      float64                `protobuf:"fixed64,3,opt,name=bid_price,json=bidPrice,proto3" json:"bid_price,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HistoricalRate) Reset() {
+	*x = HistoricalRate{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistoricalRate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistoricalRate) ProtoMessage() {}
+
+func (x *HistoricalRate) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistoricalRate.ProtoReflect.Descriptor instead.
+func (*HistoricalRate) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *HistoricalRate) GetTradingPair() string {
+	if x != nil {
+		return x.TradingPair
+	}
+	return ""
+}
+
+func (x *HistoricalRate) GetAskPrice() float64 {
+	if x != nil {
+		return x.AskPrice
+	}
+	return 0
+}
+
+func (x *HistoricalRate) GetBidPrice() float64 {
+	if x != nil {
+		return x.BidPrice
+	}
+	return 0
+}
+
+func (x *HistoricalRate) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+type GetHistoricalRatesResp struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Stored rates newest first by created_at
+	Rates         []*HistoricalRate `protobuf:"bytes,1,rep,name=rates,proto3" json:"rates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHistoricalRatesResp) Reset() {
+	*x = GetHistoricalRatesResp{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHistoricalRatesResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHistoricalRatesResp) ProtoMessage() {}
+
+func (x *GetHistoricalRatesResp) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHistoricalRatesResp.ProtoReflect.Descriptor instead.
+func (*GetHistoricalRatesResp) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *GetHistoricalRatesResp) GetRates() []*HistoricalRate {
+	if x != nil {
+		return x.Rates
+	}
+	return nil
+}
+
+type ComputeTrade struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Price         float64                `protobuf:"fixed64,1,opt,name=price,proto3" json:"price,omitempty"`
+	Volume        float64                `protobuf:"fixed64,2,opt,name=volume,proto3" json:"volume,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComputeTrade) Reset() {
+	*x = ComputeTrade{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComputeTrade) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComputeTrade) ProtoMessage() {}
+
+func (x *ComputeTrade) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComputeTrade.ProtoReflect.Descriptor instead.
+func (*ComputeTrade) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ComputeTrade) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *ComputeTrade) GetVolume() float64 {
+	if x != nil {
+		return x.Volume
+	}
+	return 0
+}
+
+type ComputeRateReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Trades        []*ComputeTrade        `protobuf:"bytes,1,rep,name=trades,proto3" json:"trades,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComputeRateReq) Reset() {
+	*x = ComputeRateReq{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComputeRateReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComputeRateReq) ProtoMessage() {}
+
+func (x *ComputeRateReq) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComputeRateReq.ProtoReflect.Descriptor instead.
+func (*ComputeRateReq) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ComputeRateReq) GetTrades() []*ComputeTrade {
+	if x != nil {
+		return x.Trades
+	}
+	return nil
+}
+
+type StrategyRate struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Strategy string                 `protobuf:"bytes,1,opt,name=strategy,proto3" json:"strategy,omitempty"`
+	AskPrice float64                `protobuf:"fixed64,2,opt,name=ask_price,json=askPrice,proto3" json:"ask_price,omitempty"`
+	BidPrice float64                `protobuf:"fixed64,3,opt,name=bid_price,json=bidPrice,proto3" json:"bid_price,omitempty"`
+	MidPrice float64                `protobuf:"fixed64,4,opt,name=mid_price,json=midPrice,proto3" json:"mid_price,omitempty"`
+	// Why the strategy could not compute a rate from the trades, prices are unset then
+	Error         string `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StrategyRate) Reset() {
+	*x = StrategyRate{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StrategyRate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StrategyRate) ProtoMessage() {}
+
+func (x *StrategyRate) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StrategyRate.ProtoReflect.Descriptor instead.
+func (*StrategyRate) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *StrategyRate) GetStrategy() string {
+	if x != nil {
+		return x.Strategy
+	}
+	return ""
+}
+
+func (x *StrategyRate) GetAskPrice() float64 {
+	if x != nil {
+		return x.AskPrice
+	}
+	return 0
+}
+
+func (x *StrategyRate) GetBidPrice() float64 {
+	if x != nil {
+		return x.BidPrice
+	}
+	return 0
+}
+
+func (x *StrategyRate) GetMidPrice() float64 {
+	if x != nil {
+		return x.MidPrice
+	}
+	return 0
+}
+
+func (x *StrategyRate) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ComputeRateResp struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One rate per registered price strategy, sorted by strategy name
+	Rates []*StrategyRate `protobuf:"bytes,1,rep,name=rates,proto3" json:"rates,omitempty"`
+	// Volume weighted average price, zero when the trades have no volume
+	Vwap          float64 `protobuf:"fixed64,2,opt,name=vwap,proto3" json:"vwap,omitempty"`
+	MedianPrice   float64 `protobuf:"fixed64,3,opt,name=median_price,json=medianPrice,proto3" json:"median_price,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComputeRateResp) Reset() {
+	*x = ComputeRateResp{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComputeRateResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComputeRateResp) ProtoMessage() {}
+
+func (x *ComputeRateResp) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComputeRateResp.ProtoReflect.Descriptor instead.
+func (*ComputeRateResp) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ComputeRateResp) GetRates() []*StrategyRate {
+	if x != nil {
+		return x.Rates
+	}
+	return nil
+}
+
+func (x *ComputeRateResp) GetVwap() float64 {
+	if x != nil {
+		return x.Vwap
+	}
+	return 0
+}
+
+func (x *ComputeRateResp) GetMedianPrice() float64 {
+	if x != nil {
+		return x.MedianPrice
+	}
+	return 0
+}
+
+type PollNowReq struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Pair to poll, e.g. "USDT/RUB" or "btcrub"
+	TradingPair   string     `protobuf:"bytes,1,opt,name=trading_pair,json=tradingPair,proto3" json:"trading_pair,omitempty"`
+	Source        RateSource `protobuf:"varint,2,opt,name=source,proto3,enum=rateservice.v1.RateSource" json:"source,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PollNowReq) Reset() {
+	*x = PollNowReq{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PollNowReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PollNowReq) ProtoMessage() {}
+
+func (x *PollNowReq) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PollNowReq.ProtoReflect.Descriptor instead.
+func (*PollNowReq) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *PollNowReq) GetTradingPair() string {
+	if x != nil {
+		return x.TradingPair
+	}
+	return ""
+}
+
+func (x *PollNowReq) GetSource() RateSource {
+	if x != nil {
+		return x.Source
+	}
+	return RateSource_RATE_SOURCE_UNSPECIFIED
+}
+
+type PollNowResp struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Rate  *GetRatesResp          `protobuf:"bytes,1,opt,name=rate,proto3" json:"rate,omitempty"`
+	// False when the call joined a fetch of the pair already in progress, which stored the rate
+	Fetched       bool `protobuf:"varint,2,opt,name=fetched,proto3" json:"fetched,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PollNowResp) Reset() {
+	*x = PollNowResp{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PollNowResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PollNowResp) ProtoMessage() {}
+
+func (x *PollNowResp) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PollNowResp.ProtoReflect.Descriptor instead.
+func (*PollNowResp) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *PollNowResp) GetRate() *GetRatesResp {
+	if x != nil {
+		return x.Rate
+	}
+	return nil
+}
+
+func (x *PollNowResp) GetFetched() bool {
+	if x != nil {
+		return x.Fetched
+	}
+	return false
+}
+
+type CapabilitiesReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CapabilitiesReq) Reset() {
+	*x = CapabilitiesReq{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CapabilitiesReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CapabilitiesReq) ProtoMessage() {}
+
+func (x *CapabilitiesReq) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CapabilitiesReq.ProtoReflect.Descriptor instead.
+func (*CapabilitiesReq) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{38}
+}
+
+type CapabilitiesResp struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// live or db_only, per SERVE_MODE
+	ServeMode string `protobuf:"bytes,1,opt,name=serve_mode,json=serveMode,proto3" json:"serve_mode,omitempty"`
+	// Sources live rates can be computed from, empty in db_only serve mode
+	Sources []string `protobuf:"bytes,2,rep,name=sources,proto3" json:"sources,omitempty"`
+	// Source used when a request leaves it unspecified, per GRINEX_RATE_SOURCE
+	DefaultSource string `protobuf:"bytes,3,opt,name=default_source,json=defaultSource,proto3" json:"default_source,omitempty"`
+	// Registered price strategies, of which price_strategy computes live rates
+	Strategies    []string `protobuf:"bytes,4,rep,name=strategies,proto3" json:"strategies,omitempty"`
+	PriceStrategy string   `protobuf:"bytes,5,opt,name=price_strategy,json=priceStrategy,proto3" json:"price_strategy,omitempty"`
+	// Server streaming RPCs callable on this instance
+	Streaming []string `protobuf:"bytes,6,rep,name=streaming,proto3" json:"streaming,omitempty"`
+	// All RPCs callable on this instance, streaming ones included
+	Endpoints []string `protobuf:"bytes,7,rep,name=endpoints,proto3" json:"endpoints,omitempty"`
+	// State of the background poller: disabled, running or paused
+	Poller        string `protobuf:"bytes,8,opt,name=poller,proto3" json:"poller,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CapabilitiesResp) Reset() {
+	*x = CapabilitiesResp{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CapabilitiesResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CapabilitiesResp) ProtoMessage() {}
+
+func (x *CapabilitiesResp) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CapabilitiesResp.ProtoReflect.Descriptor instead.
+func (*CapabilitiesResp) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *CapabilitiesResp) GetServeMode() string {
+	if x != nil {
+		return x.ServeMode
+	}
+	return ""
+}
+
+func (x *CapabilitiesResp) GetSources() []string {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+func (x *CapabilitiesResp) GetDefaultSource() string {
+	if x != nil {
+		return x.DefaultSource
+	}
+	return ""
+}
+
+func (x *CapabilitiesResp) GetStrategies() []string {
+	if x != nil {
+		return x.Strategies
+	}
+	return nil
+}
+
+func (x *CapabilitiesResp) GetPriceStrategy() string {
+	if x != nil {
+		return x.PriceStrategy
+	}
+	return ""
+}
+
+func (x *CapabilitiesResp) GetStreaming() []string {
+	if x != nil {
+		return x.Streaming
+	}
+	return nil
+}
+
+func (x *CapabilitiesResp) GetEndpoints() []string {
+	if x != nil {
+		return x.Endpoints
+	}
+	return nil
+}
+
+func (x *CapabilitiesResp) GetPoller() string {
+	if x != nil {
+		return x.Poller
+	}
+	return ""
+}
+
+type PausePollerReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PausePollerReq) Reset() {
+	*x = PausePollerReq{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PausePollerReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PausePollerReq) ProtoMessage() {}
+
+func (x *PausePollerReq) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PausePollerReq.ProtoReflect.Descriptor instead.
+func (*PausePollerReq) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{40}
+}
+
+type PausePollerResp struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// False when the poller was already paused
+	Changed       bool `protobuf:"varint,1,opt,name=changed,proto3" json:"changed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PausePollerResp) Reset() {
+	*x = PausePollerResp{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PausePollerResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PausePollerResp) ProtoMessage() {}
+
+func (x *PausePollerResp) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PausePollerResp.ProtoReflect.Descriptor instead.
+func (*PausePollerResp) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *PausePollerResp) GetChanged() bool {
+	if x != nil {
+		return x.Changed
+	}
+	return false
+}
+
+type ResumePollerReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumePollerReq) Reset() {
+	*x = ResumePollerReq{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumePollerReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumePollerReq) ProtoMessage() {}
+
+func (x *ResumePollerReq) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumePollerReq.ProtoReflect.Descriptor instead.
+func (*ResumePollerReq) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{42}
+}
+
+type ResumePollerResp struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// False when the poller was not paused
+	Changed       bool `protobuf:"varint,1,opt,name=changed,proto3" json:"changed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumePollerResp) Reset() {
+	*x = ResumePollerResp{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumePollerResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumePollerResp) ProtoMessage() {}
+
+func (x *ResumePollerResp) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumePollerResp.ProtoReflect.Descriptor instead.
+func (*ResumePollerResp) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *ResumePollerResp) GetChanged() bool {
+	if x != nil {
+		return x.Changed
+	}
+	return false
+}
+
+type AllLatestReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AllLatestReq) Reset() {
+	*x = AllLatestReq{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AllLatestReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AllLatestReq) ProtoMessage() {}
+
+func (x *AllLatestReq) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AllLatestReq.ProtoReflect.Descriptor instead.
+func (*AllLatestReq) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{44}
+}
+
+type AllLatestResp struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Latest stored rate of every trading pair, sorted by pair
+	Rates         []*HistoricalRate `protobuf:"bytes,1,rep,name=rates,proto3" json:"rates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AllLatestResp) Reset() {
+	*x = AllLatestResp{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AllLatestResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AllLatestResp) ProtoMessage() {}
+
+func (x *AllLatestResp) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AllLatestResp.ProtoReflect.Descriptor instead.
+func (*AllLatestResp) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *AllLatestResp) GetRates() []*HistoricalRate {
+	if x != nil {
+		return x.Rates
+	}
+	return nil
+}
+
+type MarketStatusReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MarketStatusReq) Reset() {
+	*x = MarketStatusReq{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MarketStatusReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarketStatusReq) ProtoMessage() {}
+
+func (x *MarketStatusReq) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarketStatusReq.ProtoReflect.Descriptor instead.
+func (*MarketStatusReq) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{46}
+}
+
+type MarketStatus struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Grinex market symbol, e.g. usdtrub
+	Market string `protobuf:"bytes,1,opt,name=market,proto3" json:"market,omitempty"`
+	// Trading pair label, e.g. USDT/RUB
+	TradingPair string `protobuf:"bytes,2,opt,name=trading_pair,json=tradingPair,proto3" json:"trading_pair,omitempty"`
+	// When a poll last got a rate of the market, unset before the first one
+	LastFetch *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=last_fetch,json=lastFetch,proto3" json:"last_fetch,omitempty"`
+	// Mid price of that rate
+	LastPrice float64 `protobuf:"fixed64,4,opt,name=last_price,json=lastPrice,proto3" json:"last_price,omitempty"`
+	// Polls failed since the last successful one
+	ConsecutiveFailures int32 `protobuf:"varint,5,opt,name=consecutive_failures,json=consecutiveFailures,proto3" json:"consecutive_failures,omitempty"`
+	// Time the market is skipped for after its last failed poll, zero when it is retried on the next tick
+	Backoff *durationpb.Duration `protobuf:"bytes,6,opt,name=backoff,proto3" json:"backoff,omitempty"`
+	// Error of the last poll, empty when it succeeded
+	LastError string `protobuf:"bytes,7,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	// Distinct trades the polled rates were computed from since the poller started, a trade
+	// reappearing in a later poll being counted once
+	TradesSeen int64 `protobuf:"varint,8,opt,name=trades_seen,json=tradesSeen,proto3" json:"trades_seen,omitempty"`
+	// Volume weighted average price of those trades, zero before the first
+	SessionVwap   float64 `protobuf:"fixed64,9,opt,name=session_vwap,json=sessionVwap,proto3" json:"session_vwap,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MarketStatus) Reset() {
+	*x = MarketStatus{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MarketStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarketStatus) ProtoMessage() {}
+
+func (x *MarketStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarketStatus.ProtoReflect.Descriptor instead.
+func (*MarketStatus) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *MarketStatus) GetMarket() string {
+	if x != nil {
+		return x.Market
+	}
+	return ""
+}
+
+func (x *MarketStatus) GetTradingPair() string {
+	if x != nil {
+		return x.TradingPair
+	}
+	return ""
+}
+
+func (x *MarketStatus) GetLastFetch() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastFetch
+	}
+	return nil
+}
+
+func (x *MarketStatus) GetLastPrice() float64 {
+	if x != nil {
+		return x.LastPrice
+	}
+	return 0
+}
+
+func (x *MarketStatus) GetConsecutiveFailures() int32 {
+	if x != nil {
+		return x.ConsecutiveFailures
+	}
+	return 0
+}
+
+func (x *MarketStatus) GetBackoff() *durationpb.Duration {
+	if x != nil {
+		return x.Backoff
+	}
+	return nil
+}
+
+func (x *MarketStatus) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *MarketStatus) GetTradesSeen() int64 {
+	if x != nil {
+		return x.TradesSeen
+	}
+	return 0
+}
+
+func (x *MarketStatus) GetSessionVwap() float64 {
+	if x != nil {
+		return x.SessionVwap
+	}
+	return 0
+}
+
+type MarketStatusResp struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Polled markets in POLL_PAIRS order
+	Markets []*MarketStatus `protobuf:"bytes,1,rep,name=markets,proto3" json:"markets,omitempty"`
+	// True while the poller is paused by PausePoller, the markets keep the state of their last poll
+	Paused        bool `protobuf:"varint,2,opt,name=paused,proto3" json:"paused,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MarketStatusResp) Reset() {
+	*x = MarketStatusResp{}
+	mi := &file_proto_v1_rate_service_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MarketStatusResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarketStatusResp) ProtoMessage() {}
+
+func (x *MarketStatusResp) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_rate_service_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarketStatusResp.ProtoReflect.Descriptor instead.
+func (*MarketStatusResp) Descriptor() ([]byte, []int) {
+	return file_proto_v1_rate_service_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *MarketStatusResp) GetMarkets() []*MarketStatus {
+	if x != nil {
+		return x.Markets
+	}
+	return nil
+}
+
+func (x *MarketStatusResp) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+var File_proto_v1_rate_service_proto protoreflect.FileDescriptor
+
+const file_proto_v1_rate_service_proto_rawDesc = "" +
+	"\n" +
+	"\x1bproto/v1/rate-service.proto\x12\x0erateservice.v1\x1a\x1egoogle/protobuf/duration.proto\x1a google/protobuf/field_mask.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xf4\x01\n" +
+	"\vGetRatesReq\x12\x1a\n" +
+	"\btimezone\x18\x01 \x01(\tR\btimezone\x12>\n" +
+	"\fprice_format\x18\x02 \x01(\x0e2\x1b.rateservice.v1.PriceFormatR\vpriceFormat\x122\n" +
+	"\x06fields\x18\x03 \x01(\v2\x1a.google.protobuf.FieldMaskR\x06fields\x122\n" +
+	"\x06source\x18\x04 \x01(\x0e2\x1a.rateservice.v1.RateSourceR\x06source\x12!\n" +
+	"\ftrading_pair\x18\x05 \x01(\tR\vtradingPair\"\xc5\x04\n" +
+	"\fGetRatesResp\x12!\n" +
+	"\ftrading_pair\x18\x01 \x01(\tR\vtradingPair\x12\x1b\n" +
+	"\task_price\x18\x02 \x01(\x01R\baskPrice\x12\x1b\n" +
+	"\tbid_price\x18\x03 \x01(\x01R\bbidPrice\x128\n" +
+	"\ttimestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x1d\n" +
+	"\n" +
+	"local_time\x18\x05 \x01(\tR\tlocalTime\x12\x14\n" +
+	"\x05stale\x18\x06 \x01(\bR\x05stale\x12\x1b\n" +
+	"\task_minor\x18\a \x01(\x03R\baskMinor\x12\x1b\n" +
+	"\tbid_minor\x18\b \x01(\x03R\bbidMinor\x12%\n" +
+	"\x0eprice_decimals\x18\t \x01(\x05R\rpriceDecimals\x12\x1b\n" +
+	"\tmid_price\x18\n" +
+	" \x01(\x01R\bmidPrice\x12\x1d\n" +
+	"\n" +
+	"client_ask\x18\v \x01(\x01R\tclientAsk\x12\x1d\n" +
+	"\n" +
+	"client_bid\x18\f \x01(\x01R\tclientBid\x12\x17\n" +
+	"\afee_bps\x18\r \x01(\x05R\x06feeBps\x12;\n" +
+	"\vingested_at\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"ingestedAt\x12\x12\n" +
+	"\x04vwap\x18\x0f \x01(\x01R\x04vwap\x12\x16\n" +
+	"\x06source\x18\x10 \x01(\tR\x06source\x12+\n" +
+	"\x03age\x18\x11 \x01(\v2\x19.google.protobuf.DurationR\x03age\"\x10\n" +
+	"\x0eHealthcheckReq\"\xee\x01\n" +
+	"\x0fHealthcheckResp\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12?\n" +
+	"\n" +
+	"components\x18\x03 \x03(\v2\x1f.rateservice.v1.ComponentHealthR\n" +
+	"components\x12\x14\n" +
+	"\x05score\x18\x04 \x01(\x01R\x05score\x12R\n" +
+	"\x13score_contributions\x18\x05 \x03(\v2!.rateservice.v1.ScoreContributionR\x12scoreContributions\"m\n" +
+	"\x11ScoreContribution\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06weight\x18\x02 \x01(\x05R\x06weight\x12\x14\n" +
+	"\x05score\x18\x03 \x01(\x01R\x05score\x12\x16\n" +
+	"\x06points\x18\x04 \x01(\x01R\x06points\"r\n" +
+	"\x0fComponentHealth\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x1d\n" +
+	"\n" +
+	"latency_ms\x18\x03 \x01(\x03R\tlatencyMs\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"h\n" +
+	"\x10GetVolatilityReq\x12!\n" +
+	"\ftrading_pair\x18\x01 \x01(\tR\vtradingPair\x121\n" +
+	"\x06window\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\x06window\"\x89\x01\n" +
+	"\x11GetVolatilityResp\x12!\n" +
+	"\ftrading_pair\x18\x01 \x01(\tR\vtradingPair\x12\x1e\n" +
+	"\n" +
+	"volatility\x18\x02 \x01(\x01R\n" +
+	"volatility\x121\n" +
+	"\x06window\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\x06window\"\x0e\n" +
+	"\fClockInfoReq\"\xba\x01\n" +
+	"\rClockInfoResp\x12;\n" +
+	"\vserver_time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"serverTime\x12;\n" +
+	"\vgrinex_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"grinexTime\x12/\n" +
+	"\x05drift\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\x05drift\"\xa9\x01\n" +
+	"\bAlertReq\x12!\n" +
+	"\ftrading_pair\x18\x01 \x01(\tR\vtradingPair\x12\x1c\n" +
+	"\tthreshold\x18\x02 \x01(\x01R\tthreshold\x12<\n" +
+	"\tdirection\x18\x03 \x01(\x0e2\x1e.rateservice.v1.AlertDirectionR\tdirection\x12\x1e\n" +
+	"\n" +
+	"continuous\x18\x04 \x01(\bR\n" +
+	"continuous\"\xda\x01\n" +
+	"\tAlertResp\x12!\n" +
+	"\ftrading_pair\x18\x01 \x01(\tR\vtradingPair\x12\x1c\n" +
+	"\tthreshold\x18\x02 \x01(\x01R\tthreshold\x12<\n" +
+	"\tdirection\x18\x03 \x01(\x0e2\x1e.rateservice.v1.AlertDirectionR\tdirection\x12\x14\n" +
+	"\x05price\x18\x04 \x01(\x01R\x05price\x128\n" +
+	"\ttimestamp\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\xa4\x01\n" +
+	"\tReplayReq\x12!\n" +
+	"\ftrading_pair\x18\x01 \x01(\tR\vtradingPair\x120\n" +
+	"\x05start\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x05start\x12,\n" +
+	"\x03end\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x03end\x12\x14\n" +
+	"\x05speed\x18\x04 \x01(\x01R\x05speed\"\xde\x01\n" +
+	"\n" +
+	"ReplayResp\x12!\n" +
+	"\ftrading_pair\x18\x01 \x01(\tR\vtradingPair\x12\x1b\n" +
+	"\task_price\x18\x02 \x01(\x01R\baskPrice\x12\x1b\n" +
+	"\tbid_price\x18\x03 \x01(\x01R\bbidPrice\x128\n" +
+	"\ttimestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"G\n" +
+	"\x11SetMaintenanceReq\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"H\n" +
+	"\x12SetMaintenanceResp\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x8f\x01\n" +
+	"\n" +
+	"GetTWAPReq\x12!\n" +
+	"\ftrading_pair\x18\x01 \x01(\tR\vtradingPair\x120\n" +
+	"\x05start\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x05start\x12,\n" +
+	"\x03end\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x03end\"\xa4\x01\n" +
+	"\vGetTWAPResp\x12!\n" +
+	"\ftrading_pair\x18\x01 \x01(\tR\vtradingPair\x12\x12\n" +
+	"\x04twap\x18\x02 \x01(\x01R\x04twap\x120\n" +
+	"\x05start\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x05start\x12,\n" +
+	"\x03end\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x03end\"L\n" +
+	"\x0fCompositeWeight\x12!\n" +
+	"\ftrading_pair\x18\x01 \x01(\tR\vtradingPair\x12\x16\n" +
+	"\x06weight\x18\x02 \x01(\x01R\x06weight\"n\n" +
+	"\fCompositeReq\x125\n" +
+	"\x05pairs\x18\x01 \x03(\v2\x1f.rateservice.v1.CompositeWeightR\x05pairs\x12'\n" +
+	"\x0fexclude_missing\x18\x02 \x01(\bR\x0eexcludeMissing\"\xa6\x01\n" +
+	"\x12CompositeComponent\x12!\n" +
+	"\ftrading_pair\x18\x01 \x01(\tR\vtradingPair\x12\x16\n" +
+	"\x06weight\x18\x02 \x01(\x01R\x06weight\x12\x1b\n" +
+	"\tmid_price\x18\x03 \x01(\x01R\bmidPrice\x128\n" +
+	"\ttimestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\xc6\x01\n" +
+	"\rCompositeResp\x12\x1b\n" +
+	"\tmid_price\x18\x01 \x01(\x01R\bmidPrice\x12B\n" +
+	"\n" +
+	"components\x18\x02 \x03(\v2\".rateservice.v1.CompositeComponentR\n" +
+	"components\x12\x1a\n" +
+	"\bexcluded\x18\x03 \x03(\tR\bexcluded\x128\n" +
+	"\ttimestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\xc4\x01\n" +
+	"\vFindGapsReq\x12!\n" +
+	"\ftrading_pair\x18\x01 \x01(\tR\vtradingPair\x120\n" +
+	"\x05start\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x05start\x12,\n" +
+	"\x03end\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x03end\x122\n" +
+	"\amax_gap\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\x06maxGap\"\x9c\x01\n" +
+	"\x03Gap\x120\n" +
+	"\x05start\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x05start\x12,\n" +
+	"\x03end\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x03end\x125\n" +
+	"\bduration\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\bduration\"Z\n" +
+	"\fFindGapsResp\x12!\n" +
+	"\ftrading_pair\x18\x01 \x01(\tR\vtradingPair\x12'\n" +
+	"\x04gaps\x18\x02 \x03(\v2\x13.rateservice.v1.GapR\x04gaps\"F\n" +
+	"\vGetDepthReq\x12!\n" +
+	"\ftrading_pair\x18\x01 \x01(\tR\vtradingPair\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\":\n" +
+	"\n" +
+	"DepthLevel\x12\x14\n" +
+	"\x05price\x18\x01 \x01(\x01R\x05price\x12\x16\n" +
+	"\x06volume\x18\x02 \x01(\x01R\x06volume\"\xcb\x01\n" +
+	"\fGetDepthResp\x12!\n" +
+	"\ftrading_pair\x18\x01 \x01(\tR\vtradingPair\x12.\n" +
+	"\x04asks\x18\x02 \x03(\v2\x1a.rateservice.v1.DepthLevelR\x04asks\x12.\n" +
+	"\x04bids\x18\x03 \x03(\v2\x1a.rateservice.v1.DepthLevelR\x04bids\x128\n" +
+	"\ttimestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\xba\x01\n" +
+	"\x0eStreamRatesReq\x12!\n" +
+	"\ftrading_pair\x18\x01 \x01(\tR\vtradingPair\x125\n" +
+	"\binterval\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\binterval\x12\x1a\n" +
+	"\btimezone\x18\x03 \x01(\tR\btimezone\x122\n" +
+	"\x06source\x18\x04 \x01(\x0e2\x1a.rateservice.v1.RateSourceR\x06source\"\xb0\x01\n" +
+	"\x15GetHistoricalRatesReq\x12!\n" +
+	"\ftrading_pair\x18\x01 \x01(\tR\vtradingPair\x120\n" +
+	"\x05start\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x05start\x12,\n" +
+	"\x03end\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x03end\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\"\xa7\x01\n" +
+	"\x0eHistoricalRate\x12!\n" +
+	"\ftrading_pair\x18\x01 \x01(\tR\vtradingPair\x12\x1b\n" +
+	"\task_price\x18\x02 \x01(\x01R\baskPrice\x12\x1b\n" +
+	"\tbid_price\x18\x03 \x01(\x01R\bbidPrice\x128\n" +
+	"\ttimestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"N\n" +
+	"\x16GetHistoricalRatesResp\x124\n" +
+	"\x05rates\x18\x01 \x03(\v2\x1e.rateservice.v1.HistoricalRateR\x05rates\"<\n" +
+	"\fComputeTrade\x12\x14\n" +
+	"\x05price\x18\x01 \x01(\x01R\x05price\x12\x16\n" +
+	"\x06volume\x18\x02 \x01(\x01R\x06volume\"F\n" +
+	"\x0eComputeRateReq\x124\n" +
+	"\x06trades\x18\x01 \x03(\v2\x1c.rateservice.v1.ComputeTradeR\x06trades\"\x97\x01\n" +
+	"\fStrategyRate\x12\x1a\n" +
+	"\bstrategy\x18\x01 \x01(\tR\bstrategy\x12\x1b\n" +
+	"\task_price\x18\x02 \x01(\x01R\baskPrice\x12\x1b\n" +
+	"\tbid_price\x18\x03 \x01(\x01R\bbidPrice\x12\x1b\n" +
+	"\tmid_price\x18\x04 \x01(\x01R\bmidPrice\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"|\n" +
+	"\x0fComputeRateResp\x122\n" +
+	"\x05rates\x18\x01 \x03(\v2\x1c.rateservice.v1.StrategyRateR\x05rates\x12\x12\n" +
+	"\x04vwap\x18\x02 \x01(\x01R\x04vwap\x12!\n" +
+	"\fmedian_price\x18\x03 \x01(\x01R\vmedianPrice\"c\n" +
+	"\n" +
+	"PollNowReq\x12!\n" +
+	"\ftrading_pair\x18\x01 \x01(\tR\vtradingPair\x122\n" +
+	"\x06source\x18\x02 \x01(\x0e2\x1a.rateservice.v1.RateSourceR\x06source\"Y\n" +
+	"\vPollNowResp\x120\n" +
+	"\x04rate\x18\x01 \x01(\v2\x1c.rateservice.v1.GetRatesRespR\x04rate\x12\x18\n" +
+	"\afetched\x18\x02 \x01(\bR\afetched\"\x11\n" +
+	"\x0fCapabilitiesReq\"\x8d\x02\n" +
+	"\x10CapabilitiesResp\x12\x1d\n" +
+	"\n" +
+	"serve_mode\x18\x01 \x01(\tR\tserveMode\x12\x18\n" +
+	"\asources\x18\x02 \x03(\tR\asources\x12%\n" +
+	"\x0edefault_source\x18\x03 \x01(\tR\rdefaultSource\x12\x1e\n" +
+	"\n" +
+	"strategies\x18\x04 \x03(\tR\n" +
+	"strategies\x12%\n" +
+	"\x0eprice_strategy\x18\x05 \x01(\tR\rpriceStrategy\x12\x1c\n" +
+	"\tstreaming\x18\x06 \x03(\tR\tstreaming\x12\x1c\n" +
+	"\tendpoints\x18\a \x03(\tR\tendpoints\x12\x16\n" +
+	"\x06poller\x18\b \x01(\tR\x06poller\"\x10\n" +
+	"\x0ePausePollerReq\"+\n" +
+	"\x0fPausePollerResp\x12\x18\n" +
+	"\achanged\x18\x01 \x01(\bR\achanged\"\x11\n" +
+	"\x0fResumePollerReq\",\n" +
+	"\x10ResumePollerResp\x12\x18\n" +
+	"\achanged\x18\x01 \x01(\bR\achanged\"\x0e\n" +
+	"\fAllLatestReq\"E\n" +
+	"\rAllLatestResp\x124\n" +
+	"\x05rates\x18\x01 \x03(\v2\x1e.rateservice.v1.HistoricalRateR\x05rates\"\x11\n" +
+	"\x0fMarketStatusReq\"\xee\x02\n" +
+	"\fMarketStatus\x12\x16\n" +
+	"\x06market\x18\x01 \x01(\tR\x06market\x12!\n" +
+	"\ftrading_pair\x18\x02 \x01(\tR\vtradingPair\x129\n" +
+	"\n" +
+	"last_fetch\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tlastFetch\x12\x1d\n" +
+	"\n" +
+	"last_price\x18\x04 \x01(\x01R\tlastPrice\x121\n" +
+	"\x14consecutive_failures\x18\x05 \x01(\x05R\x13consecutiveFailures\x123\n" +
+	"\abackoff\x18\x06 \x01(\v2\x19.google.protobuf.DurationR\abackoff\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\a \x01(\tR\tlastError\x12\x1f\n" +
+	"\vtrades_seen\x18\b \x01(\x03R\n" +
+	"tradesSeen\x12!\n" +
+	"\fsession_vwap\x18\t \x01(\x01R\vsessionVwap\"b\n" +
+	"\x10MarketStatusResp\x126\n" +
+	"\amarkets\x18\x01 \x03(\v2\x1c.rateservice.v1.MarketStatusR\amarkets\x12\x16\n" +
+	"\x06paused\x18\x02 \x01(\bR\x06paused*I\n" +
+	"\vPriceFormat\x12\x1c\n" +
+	"\x18PRICE_FORMAT_UNSPECIFIED\x10\x00\x12\x1c\n" +
+	"\x18PRICE_FORMAT_MINOR_UNITS\x10\x01*]\n" +
+	"\n" +
+	"RateSource\x12\x1b\n" +
+	"\x17RATE_SOURCE_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12RATE_SOURCE_TRADES\x10\x01\x12\x1a\n" +
+	"\x16RATE_SOURCE_ORDER_BOOK\x10\x02*g\n" +
+	"\x0eAlertDirection\x12\x1f\n" +
+	"\x1bALERT_DIRECTION_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15ALERT_DIRECTION_ABOVE\x10\x01\x12\x19\n" +
+	"\x15ALERT_DIRECTION_BELOW\x10\x022\xdc\f\n" +
 	"\vRateService\x12G\n" +
 	"\bGetRates\x12\x1b.rateservice.v1.GetRatesReq\x1a\x1c.rateservice.v1.GetRatesResp\"\x00\x12P\n" +
-	"\vHealthcheck\x12\x1e.rateservice.v1.HealthcheckReq\x1a\x1f.rateservice.v1.HealthcheckResp\"\x00B+Z)github.com/atadzan/grinex-rate-service/pbb\x06proto3"
+	"\vHealthcheck\x12\x1e.rateservice.v1.HealthcheckReq\x1a\x1f.rateservice.v1.HealthcheckResp\"\x00\x12V\n" +
+	"\rGetVolatility\x12 .rateservice.v1.GetVolatilityReq\x1a!.rateservice.v1.GetVolatilityResp\"\x00\x12M\n" +
+	"\fGetClockInfo\x12\x1c.rateservice.v1.ClockInfoReq\x1a\x1d.rateservice.v1.ClockInfoResp\"\x00\x12I\n" +
+	"\x0eSubscribeAlert\x12\x18.rateservice.v1.AlertReq\x1a\x19.rateservice.v1.AlertResp\"\x000\x01\x12H\n" +
+	"\vReplayRates\x12\x19.rateservice.v1.ReplayReq\x1a\x1a.rateservice.v1.ReplayResp\"\x000\x01\x12Y\n" +
+	"\x0eSetMaintenance\x12!.rateservice.v1.SetMaintenanceReq\x1a\".rateservice.v1.SetMaintenanceResp\"\x00\x12D\n" +
+	"\aGetTWAP\x12\x1a.rateservice.v1.GetTWAPReq\x1a\x1b.rateservice.v1.GetTWAPResp\"\x00\x12M\n" +
+	"\fGetComposite\x12\x1c.rateservice.v1.CompositeReq\x1a\x1d.rateservice.v1.CompositeResp\"\x00\x12G\n" +
+	"\bFindGaps\x12\x1b.rateservice.v1.FindGapsReq\x1a\x1c.rateservice.v1.FindGapsResp\"\x00\x12G\n" +
+	"\bGetDepth\x12\x1b.rateservice.v1.GetDepthReq\x1a\x1c.rateservice.v1.GetDepthResp\"\x00\x12O\n" +
+	"\vStreamRates\x12\x1e.rateservice.v1.StreamRatesReq\x1a\x1c.rateservice.v1.GetRatesResp\"\x000\x01\x12e\n" +
+	"\x12GetHistoricalRates\x12%.rateservice.v1.GetHistoricalRatesReq\x1a&.rateservice.v1.GetHistoricalRatesResp\"\x00\x12P\n" +
+	"\vComputeRate\x12\x1e.rateservice.v1.ComputeRateReq\x1a\x1f.rateservice.v1.ComputeRateResp\"\x00\x12D\n" +
+	"\aPollNow\x12\x1a.rateservice.v1.PollNowReq\x1a\x1b.rateservice.v1.PollNowResp\"\x00\x12V\n" +
+	"\x0fGetCapabilities\x12\x1f.rateservice.v1.CapabilitiesReq\x1a .rateservice.v1.CapabilitiesResp\"\x00\x12P\n" +
+	"\vPausePoller\x12\x1e.rateservice.v1.PausePollerReq\x1a\x1f.rateservice.v1.PausePollerResp\"\x00\x12S\n" +
+	"\fResumePoller\x12\x1f.rateservice.v1.ResumePollerReq\x1a .rateservice.v1.ResumePollerResp\"\x00\x12M\n" +
+	"\fGetAllLatest\x12\x1c.rateservice.v1.AllLatestReq\x1a\x1d.rateservice.v1.AllLatestResp\"\x00\x12V\n" +
+	"\x0fGetMarketStatus\x12\x1f.rateservice.v1.MarketStatusReq\x1a .rateservice.v1.MarketStatusResp\"\x00B+Z)github.com/atadzan/grinex-rate-service/pbb\x06proto3"
 
 var (
 	file_proto_v1_rate_service_proto_rawDescOnce sync.Once
@@ -245,25 +3487,163 @@ func file_proto_v1_rate_service_proto_rawDescGZIP() []byte {
 	return file_proto_v1_rate_service_proto_rawDescData
 }
 
-var file_proto_v1_rate_service_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_proto_v1_rate_service_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_proto_v1_rate_service_proto_msgTypes = make([]protoimpl.MessageInfo, 49)
 var file_proto_v1_rate_service_proto_goTypes = []any{
-	(*GetRatesReq)(nil),           // 0: rateservice.v1.GetRatesReq
-	(*GetRatesResp)(nil),          // 1: rateservice.v1.GetRatesResp
-	(*HealthcheckReq)(nil),        // 2: rateservice.v1.HealthcheckReq
-	(*HealthcheckResp)(nil),       // 3: rateservice.v1.HealthcheckResp
-	(*timestamppb.Timestamp)(nil), // 4: google.protobuf.Timestamp
+	(PriceFormat)(0),               // 0: rateservice.v1.PriceFormat
+	(RateSource)(0),                // 1: rateservice.v1.RateSource
+	(AlertDirection)(0),            // 2: rateservice.v1.AlertDirection
+	(*GetRatesReq)(nil),            // 3: rateservice.v1.GetRatesReq
+	(*GetRatesResp)(nil),           // 4: rateservice.v1.GetRatesResp
+	(*HealthcheckReq)(nil),         // 5: rateservice.v1.HealthcheckReq
+	(*HealthcheckResp)(nil),        // 6: rateservice.v1.HealthcheckResp
+	(*ScoreContribution)(nil),      // 7: rateservice.v1.ScoreContribution
+	(*ComponentHealth)(nil),        // 8: rateservice.v1.ComponentHealth
+	(*GetVolatilityReq)(nil),       // 9: rateservice.v1.GetVolatilityReq
+	(*GetVolatilityResp)(nil),      // 10: rateservice.v1.GetVolatilityResp
+	(*ClockInfoReq)(nil),           // 11: rateservice.v1.ClockInfoReq
+	(*ClockInfoResp)(nil),          // 12: rateservice.v1.ClockInfoResp
+	(*AlertReq)(nil),               // 13: rateservice.v1.AlertReq
+	(*AlertResp)(nil),              // 14: rateservice.v1.AlertResp
+	(*ReplayReq)(nil),              // 15: rateservice.v1.ReplayReq
+	(*ReplayResp)(nil),             // 16: rateservice.v1.ReplayResp
+	(*SetMaintenanceReq)(nil),      // 17: rateservice.v1.SetMaintenanceReq
+	(*SetMaintenanceResp)(nil),     // 18: rateservice.v1.SetMaintenanceResp
+	(*GetTWAPReq)(nil),             // 19: rateservice.v1.GetTWAPReq
+	(*GetTWAPResp)(nil),            // 20: rateservice.v1.GetTWAPResp
+	(*CompositeWeight)(nil),        // 21: rateservice.v1.CompositeWeight
+	(*CompositeReq)(nil),           // 22: rateservice.v1.CompositeReq
+	(*CompositeComponent)(nil),     // 23: rateservice.v1.CompositeComponent
+	(*CompositeResp)(nil),          // 24: rateservice.v1.CompositeResp
+	(*FindGapsReq)(nil),            // 25: rateservice.v1.FindGapsReq
+	(*Gap)(nil),                    // 26: rateservice.v1.Gap
+	(*FindGapsResp)(nil),           // 27: rateservice.v1.FindGapsResp
+	(*GetDepthReq)(nil),            // 28: rateservice.v1.GetDepthReq
+	(*DepthLevel)(nil),             // 29: rateservice.v1.DepthLevel
+	(*GetDepthResp)(nil),           // 30: rateservice.v1.GetDepthResp
+	(*StreamRatesReq)(nil),         // 31: rateservice.v1.StreamRatesReq
+	(*GetHistoricalRatesReq)(nil),  // 32: rateservice.v1.GetHistoricalRatesReq
+	(*HistoricalRate)(nil),         // 33: rateservice.v1.HistoricalRate
+	(*GetHistoricalRatesResp)(nil), // 34: rateservice.v1.GetHistoricalRatesResp
+	(*ComputeTrade)(nil),           // 35: rateservice.v1.ComputeTrade
+	(*ComputeRateReq)(nil),         // 36: rateservice.v1.ComputeRateReq
+	(*StrategyRate)(nil),           // 37: rateservice.v1.StrategyRate
+	(*ComputeRateResp)(nil),        // 38: rateservice.v1.ComputeRateResp
+	(*PollNowReq)(nil),             // 39: rateservice.v1.PollNowReq
+	(*PollNowResp)(nil),            // 40: rateservice.v1.PollNowResp
+	(*CapabilitiesReq)(nil),        // 41: rateservice.v1.CapabilitiesReq
+	(*CapabilitiesResp)(nil),       // 42: rateservice.v1.CapabilitiesResp
+	(*PausePollerReq)(nil),         // 43: rateservice.v1.PausePollerReq
+	(*PausePollerResp)(nil),        // 44: rateservice.v1.PausePollerResp
+	(*ResumePollerReq)(nil),        // 45: rateservice.v1.ResumePollerReq
+	(*ResumePollerResp)(nil),       // 46: rateservice.v1.ResumePollerResp
+	(*AllLatestReq)(nil),           // 47: rateservice.v1.AllLatestReq
+	(*AllLatestResp)(nil),          // 48: rateservice.v1.AllLatestResp
+	(*MarketStatusReq)(nil),        // 49: rateservice.v1.MarketStatusReq
+	(*MarketStatus)(nil),           // 50: rateservice.v1.MarketStatus
+	(*MarketStatusResp)(nil),       // 51: rateservice.v1.MarketStatusResp
+	(*fieldmaskpb.FieldMask)(nil),  // 52: google.protobuf.FieldMask
+	(*timestamppb.Timestamp)(nil),  // 53: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),    // 54: google.protobuf.Duration
 }
 var file_proto_v1_rate_service_proto_depIdxs = []int32{
-	4, // 0: rateservice.v1.GetRatesResp.timestamp:type_name -> google.protobuf.Timestamp
-	0, // 1: rateservice.v1.RateService.GetRates:input_type -> rateservice.v1.GetRatesReq
-	2, // 2: rateservice.v1.RateService.Healthcheck:input_type -> rateservice.v1.HealthcheckReq
-	1, // 3: rateservice.v1.RateService.GetRates:output_type -> rateservice.v1.GetRatesResp
-	3, // 4: rateservice.v1.RateService.Healthcheck:output_type -> rateservice.v1.HealthcheckResp
-	3, // [3:5] is the sub-list for method output_type
-	1, // [1:3] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	0,  // 0: rateservice.v1.GetRatesReq.price_format:type_name -> rateservice.v1.PriceFormat
+	52, // 1: rateservice.v1.GetRatesReq.fields:type_name -> google.protobuf.FieldMask
+	1,  // 2: rateservice.v1.GetRatesReq.source:type_name -> rateservice.v1.RateSource
+	53, // 3: rateservice.v1.GetRatesResp.timestamp:type_name -> google.protobuf.Timestamp
+	53, // 4: rateservice.v1.GetRatesResp.ingested_at:type_name -> google.protobuf.Timestamp
+	54, // 5: rateservice.v1.GetRatesResp.age:type_name -> google.protobuf.Duration
+	8,  // 6: rateservice.v1.HealthcheckResp.components:type_name -> rateservice.v1.ComponentHealth
+	7,  // 7: rateservice.v1.HealthcheckResp.score_contributions:type_name -> rateservice.v1.ScoreContribution
+	54, // 8: rateservice.v1.GetVolatilityReq.window:type_name -> google.protobuf.Duration
+	54, // 9: rateservice.v1.GetVolatilityResp.window:type_name -> google.protobuf.Duration
+	53, // 10: rateservice.v1.ClockInfoResp.server_time:type_name -> google.protobuf.Timestamp
+	53, // 11: rateservice.v1.ClockInfoResp.grinex_time:type_name -> google.protobuf.Timestamp
+	54, // 12: rateservice.v1.ClockInfoResp.drift:type_name -> google.protobuf.Duration
+	2,  // 13: rateservice.v1.AlertReq.direction:type_name -> rateservice.v1.AlertDirection
+	2,  // 14: rateservice.v1.AlertResp.direction:type_name -> rateservice.v1.AlertDirection
+	53, // 15: rateservice.v1.AlertResp.timestamp:type_name -> google.protobuf.Timestamp
+	53, // 16: rateservice.v1.ReplayReq.start:type_name -> google.protobuf.Timestamp
+	53, // 17: rateservice.v1.ReplayReq.end:type_name -> google.protobuf.Timestamp
+	53, // 18: rateservice.v1.ReplayResp.timestamp:type_name -> google.protobuf.Timestamp
+	53, // 19: rateservice.v1.ReplayResp.created_at:type_name -> google.protobuf.Timestamp
+	53, // 20: rateservice.v1.GetTWAPReq.start:type_name -> google.protobuf.Timestamp
+	53, // 21: rateservice.v1.GetTWAPReq.end:type_name -> google.protobuf.Timestamp
+	53, // 22: rateservice.v1.GetTWAPResp.start:type_name -> google.protobuf.Timestamp
+	53, // 23: rateservice.v1.GetTWAPResp.end:type_name -> google.protobuf.Timestamp
+	21, // 24: rateservice.v1.CompositeReq.pairs:type_name -> rateservice.v1.CompositeWeight
+	53, // 25: rateservice.v1.CompositeComponent.timestamp:type_name -> google.protobuf.Timestamp
+	23, // 26: rateservice.v1.CompositeResp.components:type_name -> rateservice.v1.CompositeComponent
+	53, // 27: rateservice.v1.CompositeResp.timestamp:type_name -> google.protobuf.Timestamp
+	53, // 28: rateservice.v1.FindGapsReq.start:type_name -> google.protobuf.Timestamp
+	53, // 29: rateservice.v1.FindGapsReq.end:type_name -> google.protobuf.Timestamp
+	54, // 30: rateservice.v1.FindGapsReq.max_gap:type_name -> google.protobuf.Duration
+	53, // 31: rateservice.v1.Gap.start:type_name -> google.protobuf.Timestamp
+	53, // 32: rateservice.v1.Gap.end:type_name -> google.protobuf.Timestamp
+	54, // 33: rateservice.v1.Gap.duration:type_name -> google.protobuf.Duration
+	26, // 34: rateservice.v1.FindGapsResp.gaps:type_name -> rateservice.v1.Gap
+	29, // 35: rateservice.v1.GetDepthResp.asks:type_name -> rateservice.v1.DepthLevel
+	29, // 36: rateservice.v1.GetDepthResp.bids:type_name -> rateservice.v1.DepthLevel
+	53, // 37: rateservice.v1.GetDepthResp.timestamp:type_name -> google.protobuf.Timestamp
+	54, // 38: rateservice.v1.StreamRatesReq.interval:type_name -> google.protobuf.Duration
+	1,  // 39: rateservice.v1.StreamRatesReq.source:type_name -> rateservice.v1.RateSource
+	53, // 40: rateservice.v1.GetHistoricalRatesReq.start:type_name -> google.protobuf.Timestamp
+	53, // 41: rateservice.v1.GetHistoricalRatesReq.end:type_name -> google.protobuf.Timestamp
+	53, // 42: rateservice.v1.HistoricalRate.timestamp:type_name -> google.protobuf.Timestamp
+	33, // 43: rateservice.v1.GetHistoricalRatesResp.rates:type_name -> rateservice.v1.HistoricalRate
+	35, // 44: rateservice.v1.ComputeRateReq.trades:type_name -> rateservice.v1.ComputeTrade
+	37, // 45: rateservice.v1.ComputeRateResp.rates:type_name -> rateservice.v1.StrategyRate
+	1,  // 46: rateservice.v1.PollNowReq.source:type_name -> rateservice.v1.RateSource
+	4,  // 47: rateservice.v1.PollNowResp.rate:type_name -> rateservice.v1.GetRatesResp
+	33, // 48: rateservice.v1.AllLatestResp.rates:type_name -> rateservice.v1.HistoricalRate
+	53, // 49: rateservice.v1.MarketStatus.last_fetch:type_name -> google.protobuf.Timestamp
+	54, // 50: rateservice.v1.MarketStatus.backoff:type_name -> google.protobuf.Duration
+	50, // 51: rateservice.v1.MarketStatusResp.markets:type_name -> rateservice.v1.MarketStatus
+	3,  // 52: rateservice.v1.RateService.GetRates:input_type -> rateservice.v1.GetRatesReq
+	5,  // 53: rateservice.v1.RateService.Healthcheck:input_type -> rateservice.v1.HealthcheckReq
+	9,  // 54: rateservice.v1.RateService.GetVolatility:input_type -> rateservice.v1.GetVolatilityReq
+	11, // 55: rateservice.v1.RateService.GetClockInfo:input_type -> rateservice.v1.ClockInfoReq
+	13, // 56: rateservice.v1.RateService.SubscribeAlert:input_type -> rateservice.v1.AlertReq
+	15, // 57: rateservice.v1.RateService.ReplayRates:input_type -> rateservice.v1.ReplayReq
+	17, // 58: rateservice.v1.RateService.SetMaintenance:input_type -> rateservice.v1.SetMaintenanceReq
+	19, // 59: rateservice.v1.RateService.GetTWAP:input_type -> rateservice.v1.GetTWAPReq
+	22, // 60: rateservice.v1.RateService.GetComposite:input_type -> rateservice.v1.CompositeReq
+	25, // 61: rateservice.v1.RateService.FindGaps:input_type -> rateservice.v1.FindGapsReq
+	28, // 62: rateservice.v1.RateService.GetDepth:input_type -> rateservice.v1.GetDepthReq
+	31, // 63: rateservice.v1.RateService.StreamRates:input_type -> rateservice.v1.StreamRatesReq
+	32, // 64: rateservice.v1.RateService.GetHistoricalRates:input_type -> rateservice.v1.GetHistoricalRatesReq
+	36, // 65: rateservice.v1.RateService.ComputeRate:input_type -> rateservice.v1.ComputeRateReq
+	39, // 66: rateservice.v1.RateService.PollNow:input_type -> rateservice.v1.PollNowReq
+	41, // 67: rateservice.v1.RateService.GetCapabilities:input_type -> rateservice.v1.CapabilitiesReq
+	43, // 68: rateservice.v1.RateService.PausePoller:input_type -> rateservice.v1.PausePollerReq
+	45, // 69: rateservice.v1.RateService.ResumePoller:input_type -> rateservice.v1.ResumePollerReq
+	47, // 70: rateservice.v1.RateService.GetAllLatest:input_type -> rateservice.v1.AllLatestReq
+	49, // 71: rateservice.v1.RateService.GetMarketStatus:input_type -> rateservice.v1.MarketStatusReq
+	4,  // 72: rateservice.v1.RateService.GetRates:output_type -> rateservice.v1.GetRatesResp
+	6,  // 73: rateservice.v1.RateService.Healthcheck:output_type -> rateservice.v1.HealthcheckResp
+	10, // 74: rateservice.v1.RateService.GetVolatility:output_type -> rateservice.v1.GetVolatilityResp
+	12, // 75: rateservice.v1.RateService.GetClockInfo:output_type -> rateservice.v1.ClockInfoResp
+	14, // 76: rateservice.v1.RateService.SubscribeAlert:output_type -> rateservice.v1.AlertResp
+	16, // 77: rateservice.v1.RateService.ReplayRates:output_type -> rateservice.v1.ReplayResp
+	18, // 78: rateservice.v1.RateService.SetMaintenance:output_type -> rateservice.v1.SetMaintenanceResp
+	20, // 79: rateservice.v1.RateService.GetTWAP:output_type -> rateservice.v1.GetTWAPResp
+	24, // 80: rateservice.v1.RateService.GetComposite:output_type -> rateservice.v1.CompositeResp
+	27, // 81: rateservice.v1.RateService.FindGaps:output_type -> rateservice.v1.FindGapsResp
+	30, // 82: rateservice.v1.RateService.GetDepth:output_type -> rateservice.v1.GetDepthResp
+	4,  // 83: rateservice.v1.RateService.StreamRates:output_type -> rateservice.v1.GetRatesResp
+	34, // 84: rateservice.v1.RateService.GetHistoricalRates:output_type -> rateservice.v1.GetHistoricalRatesResp
+	38, // 85: rateservice.v1.RateService.ComputeRate:output_type -> rateservice.v1.ComputeRateResp
+	40, // 86: rateservice.v1.RateService.PollNow:output_type -> rateservice.v1.PollNowResp
+	42, // 87: rateservice.v1.RateService.GetCapabilities:output_type -> rateservice.v1.CapabilitiesResp
+	44, // 88: rateservice.v1.RateService.PausePoller:output_type -> rateservice.v1.PausePollerResp
+	46, // 89: rateservice.v1.RateService.ResumePoller:output_type -> rateservice.v1.ResumePollerResp
+	48, // 90: rateservice.v1.RateService.GetAllLatest:output_type -> rateservice.v1.AllLatestResp
+	51, // 91: rateservice.v1.RateService.GetMarketStatus:output_type -> rateservice.v1.MarketStatusResp
+	72, // [72:92] is the sub-list for method output_type
+	52, // [52:72] is the sub-list for method input_type
+	52, // [52:52] is the sub-list for extension type_name
+	52, // [52:52] is the sub-list for extension extendee
+	0,  // [0:52] is the sub-list for field type_name
 }
 
 func init() { file_proto_v1_rate_service_proto_init() }
@@ -276,13 +3656,14 @@ func file_proto_v1_rate_service_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_v1_rate_service_proto_rawDesc), len(file_proto_v1_rate_service_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   4,
+			NumEnums:      3,
+			NumMessages:   49,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_proto_v1_rate_service_proto_goTypes,
 		DependencyIndexes: file_proto_v1_rate_service_proto_depIdxs,
+		EnumInfos:         file_proto_v1_rate_service_proto_enumTypes,
 		MessageInfos:      file_proto_v1_rate_service_proto_msgTypes,
 	}.Build()
 	File_proto_v1_rate_service_proto = out.File
@@ -19,8 +19,26 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	RateService_GetRates_FullMethodName    = "/rateservice.v1.RateService/GetRates"
-	RateService_Healthcheck_FullMethodName = "/rateservice.v1.RateService/Healthcheck"
+	RateService_GetRates_FullMethodName           = "/rateservice.v1.RateService/GetRates"
+	RateService_Healthcheck_FullMethodName        = "/rateservice.v1.RateService/Healthcheck"
+	RateService_GetVolatility_FullMethodName      = "/rateservice.v1.RateService/GetVolatility"
+	RateService_GetClockInfo_FullMethodName       = "/rateservice.v1.RateService/GetClockInfo"
+	RateService_SubscribeAlert_FullMethodName     = "/rateservice.v1.RateService/SubscribeAlert"
+	RateService_ReplayRates_FullMethodName        = "/rateservice.v1.RateService/ReplayRates"
+	RateService_SetMaintenance_FullMethodName     = "/rateservice.v1.RateService/SetMaintenance"
+	RateService_GetTWAP_FullMethodName            = "/rateservice.v1.RateService/GetTWAP"
+	RateService_GetComposite_FullMethodName       = "/rateservice.v1.RateService/GetComposite"
+	RateService_FindGaps_FullMethodName           = "/rateservice.v1.RateService/FindGaps"
+	RateService_GetDepth_FullMethodName           = "/rateservice.v1.RateService/GetDepth"
+	RateService_StreamRates_FullMethodName        = "/rateservice.v1.RateService/StreamRates"
+	RateService_GetHistoricalRates_FullMethodName = "/rateservice.v1.RateService/GetHistoricalRates"
+	RateService_ComputeRate_FullMethodName        = "/rateservice.v1.RateService/ComputeRate"
+	RateService_PollNow_FullMethodName            = "/rateservice.v1.RateService/PollNow"
+	RateService_GetCapabilities_FullMethodName    = "/rateservice.v1.RateService/GetCapabilities"
+	RateService_PausePoller_FullMethodName        = "/rateservice.v1.RateService/PausePoller"
+	RateService_ResumePoller_FullMethodName       = "/rateservice.v1.RateService/ResumePoller"
+	RateService_GetAllLatest_FullMethodName       = "/rateservice.v1.RateService/GetAllLatest"
+	RateService_GetMarketStatus_FullMethodName    = "/rateservice.v1.RateService/GetMarketStatus"
 )
 
 // RateServiceClient is the client API for RateService service.
@@ -29,6 +47,24 @@ const (
 type RateServiceClient interface {
 	GetRates(ctx context.Context, in *GetRatesReq, opts ...grpc.CallOption) (*GetRatesResp, error)
 	Healthcheck(ctx context.Context, in *HealthcheckReq, opts ...grpc.CallOption) (*HealthcheckResp, error)
+	GetVolatility(ctx context.Context, in *GetVolatilityReq, opts ...grpc.CallOption) (*GetVolatilityResp, error)
+	GetClockInfo(ctx context.Context, in *ClockInfoReq, opts ...grpc.CallOption) (*ClockInfoResp, error)
+	SubscribeAlert(ctx context.Context, in *AlertReq, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AlertResp], error)
+	ReplayRates(ctx context.Context, in *ReplayReq, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ReplayResp], error)
+	SetMaintenance(ctx context.Context, in *SetMaintenanceReq, opts ...grpc.CallOption) (*SetMaintenanceResp, error)
+	GetTWAP(ctx context.Context, in *GetTWAPReq, opts ...grpc.CallOption) (*GetTWAPResp, error)
+	GetComposite(ctx context.Context, in *CompositeReq, opts ...grpc.CallOption) (*CompositeResp, error)
+	FindGaps(ctx context.Context, in *FindGapsReq, opts ...grpc.CallOption) (*FindGapsResp, error)
+	GetDepth(ctx context.Context, in *GetDepthReq, opts ...grpc.CallOption) (*GetDepthResp, error)
+	StreamRates(ctx context.Context, in *StreamRatesReq, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GetRatesResp], error)
+	GetHistoricalRates(ctx context.Context, in *GetHistoricalRatesReq, opts ...grpc.CallOption) (*GetHistoricalRatesResp, error)
+	ComputeRate(ctx context.Context, in *ComputeRateReq, opts ...grpc.CallOption) (*ComputeRateResp, error)
+	PollNow(ctx context.Context, in *PollNowReq, opts ...grpc.CallOption) (*PollNowResp, error)
+	GetCapabilities(ctx context.Context, in *CapabilitiesReq, opts ...grpc.CallOption) (*CapabilitiesResp, error)
+	PausePoller(ctx context.Context, in *PausePollerReq, opts ...grpc.CallOption) (*PausePollerResp, error)
+	ResumePoller(ctx context.Context, in *ResumePollerReq, opts ...grpc.CallOption) (*ResumePollerResp, error)
+	GetAllLatest(ctx context.Context, in *AllLatestReq, opts ...grpc.CallOption) (*AllLatestResp, error)
+	GetMarketStatus(ctx context.Context, in *MarketStatusReq, opts ...grpc.CallOption) (*MarketStatusResp, error)
 }
 
 type rateServiceClient struct {
@@ -59,12 +95,237 @@ func (c *rateServiceClient) Healthcheck(ctx context.Context, in *HealthcheckReq,
 	return out, nil
 }
 
+func (c *rateServiceClient) GetVolatility(ctx context.Context, in *GetVolatilityReq, opts ...grpc.CallOption) (*GetVolatilityResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetVolatilityResp)
+	err := c.cc.Invoke(ctx, RateService_GetVolatility_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateServiceClient) GetClockInfo(ctx context.Context, in *ClockInfoReq, opts ...grpc.CallOption) (*ClockInfoResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ClockInfoResp)
+	err := c.cc.Invoke(ctx, RateService_GetClockInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateServiceClient) SubscribeAlert(ctx context.Context, in *AlertReq, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AlertResp], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RateService_ServiceDesc.Streams[0], RateService_SubscribeAlert_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[AlertReq, AlertResp]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RateService_SubscribeAlertClient = grpc.ServerStreamingClient[AlertResp]
+
+func (c *rateServiceClient) ReplayRates(ctx context.Context, in *ReplayReq, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ReplayResp], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RateService_ServiceDesc.Streams[1], RateService_ReplayRates_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ReplayReq, ReplayResp]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RateService_ReplayRatesClient = grpc.ServerStreamingClient[ReplayResp]
+
+func (c *rateServiceClient) SetMaintenance(ctx context.Context, in *SetMaintenanceReq, opts ...grpc.CallOption) (*SetMaintenanceResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetMaintenanceResp)
+	err := c.cc.Invoke(ctx, RateService_SetMaintenance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateServiceClient) GetTWAP(ctx context.Context, in *GetTWAPReq, opts ...grpc.CallOption) (*GetTWAPResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTWAPResp)
+	err := c.cc.Invoke(ctx, RateService_GetTWAP_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateServiceClient) GetComposite(ctx context.Context, in *CompositeReq, opts ...grpc.CallOption) (*CompositeResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CompositeResp)
+	err := c.cc.Invoke(ctx, RateService_GetComposite_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateServiceClient) FindGaps(ctx context.Context, in *FindGapsReq, opts ...grpc.CallOption) (*FindGapsResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FindGapsResp)
+	err := c.cc.Invoke(ctx, RateService_FindGaps_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateServiceClient) GetDepth(ctx context.Context, in *GetDepthReq, opts ...grpc.CallOption) (*GetDepthResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetDepthResp)
+	err := c.cc.Invoke(ctx, RateService_GetDepth_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateServiceClient) StreamRates(ctx context.Context, in *StreamRatesReq, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GetRatesResp], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RateService_ServiceDesc.Streams[2], RateService_StreamRates_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamRatesReq, GetRatesResp]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RateService_StreamRatesClient = grpc.ServerStreamingClient[GetRatesResp]
+
+func (c *rateServiceClient) GetHistoricalRates(ctx context.Context, in *GetHistoricalRatesReq, opts ...grpc.CallOption) (*GetHistoricalRatesResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetHistoricalRatesResp)
+	err := c.cc.Invoke(ctx, RateService_GetHistoricalRates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateServiceClient) ComputeRate(ctx context.Context, in *ComputeRateReq, opts ...grpc.CallOption) (*ComputeRateResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ComputeRateResp)
+	err := c.cc.Invoke(ctx, RateService_ComputeRate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateServiceClient) PollNow(ctx context.Context, in *PollNowReq, opts ...grpc.CallOption) (*PollNowResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PollNowResp)
+	err := c.cc.Invoke(ctx, RateService_PollNow_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateServiceClient) GetCapabilities(ctx context.Context, in *CapabilitiesReq, opts ...grpc.CallOption) (*CapabilitiesResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CapabilitiesResp)
+	err := c.cc.Invoke(ctx, RateService_GetCapabilities_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateServiceClient) PausePoller(ctx context.Context, in *PausePollerReq, opts ...grpc.CallOption) (*PausePollerResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PausePollerResp)
+	err := c.cc.Invoke(ctx, RateService_PausePoller_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateServiceClient) ResumePoller(ctx context.Context, in *ResumePollerReq, opts ...grpc.CallOption) (*ResumePollerResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResumePollerResp)
+	err := c.cc.Invoke(ctx, RateService_ResumePoller_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateServiceClient) GetAllLatest(ctx context.Context, in *AllLatestReq, opts ...grpc.CallOption) (*AllLatestResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AllLatestResp)
+	err := c.cc.Invoke(ctx, RateService_GetAllLatest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateServiceClient) GetMarketStatus(ctx context.Context, in *MarketStatusReq, opts ...grpc.CallOption) (*MarketStatusResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MarketStatusResp)
+	err := c.cc.Invoke(ctx, RateService_GetMarketStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RateServiceServer is the server API for RateService service.
 // All implementations must embed UnimplementedRateServiceServer
 // for forward compatibility.
 type RateServiceServer interface {
 	GetRates(context.Context, *GetRatesReq) (*GetRatesResp, error)
 	Healthcheck(context.Context, *HealthcheckReq) (*HealthcheckResp, error)
+	GetVolatility(context.Context, *GetVolatilityReq) (*GetVolatilityResp, error)
+	GetClockInfo(context.Context, *ClockInfoReq) (*ClockInfoResp, error)
+	SubscribeAlert(*AlertReq, grpc.ServerStreamingServer[AlertResp]) error
+	ReplayRates(*ReplayReq, grpc.ServerStreamingServer[ReplayResp]) error
+	SetMaintenance(context.Context, *SetMaintenanceReq) (*SetMaintenanceResp, error)
+	GetTWAP(context.Context, *GetTWAPReq) (*GetTWAPResp, error)
+	GetComposite(context.Context, *CompositeReq) (*CompositeResp, error)
+	FindGaps(context.Context, *FindGapsReq) (*FindGapsResp, error)
+	GetDepth(context.Context, *GetDepthReq) (*GetDepthResp, error)
+	StreamRates(*StreamRatesReq, grpc.ServerStreamingServer[GetRatesResp]) error
+	GetHistoricalRates(context.Context, *GetHistoricalRatesReq) (*GetHistoricalRatesResp, error)
+	ComputeRate(context.Context, *ComputeRateReq) (*ComputeRateResp, error)
+	PollNow(context.Context, *PollNowReq) (*PollNowResp, error)
+	GetCapabilities(context.Context, *CapabilitiesReq) (*CapabilitiesResp, error)
+	PausePoller(context.Context, *PausePollerReq) (*PausePollerResp, error)
+	ResumePoller(context.Context, *ResumePollerReq) (*ResumePollerResp, error)
+	GetAllLatest(context.Context, *AllLatestReq) (*AllLatestResp, error)
+	GetMarketStatus(context.Context, *MarketStatusReq) (*MarketStatusResp, error)
 	mustEmbedUnimplementedRateServiceServer()
 }
 
@@ -81,6 +342,60 @@ func (UnimplementedRateServiceServer) GetRates(context.Context, *GetRatesReq) (*
 func (UnimplementedRateServiceServer) Healthcheck(context.Context, *HealthcheckReq) (*HealthcheckResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Healthcheck not implemented")
 }
+func (UnimplementedRateServiceServer) GetVolatility(context.Context, *GetVolatilityReq) (*GetVolatilityResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVolatility not implemented")
+}
+func (UnimplementedRateServiceServer) GetClockInfo(context.Context, *ClockInfoReq) (*ClockInfoResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetClockInfo not implemented")
+}
+func (UnimplementedRateServiceServer) SubscribeAlert(*AlertReq, grpc.ServerStreamingServer[AlertResp]) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeAlert not implemented")
+}
+func (UnimplementedRateServiceServer) ReplayRates(*ReplayReq, grpc.ServerStreamingServer[ReplayResp]) error {
+	return status.Errorf(codes.Unimplemented, "method ReplayRates not implemented")
+}
+func (UnimplementedRateServiceServer) SetMaintenance(context.Context, *SetMaintenanceReq) (*SetMaintenanceResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetMaintenance not implemented")
+}
+func (UnimplementedRateServiceServer) GetTWAP(context.Context, *GetTWAPReq) (*GetTWAPResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTWAP not implemented")
+}
+func (UnimplementedRateServiceServer) GetComposite(context.Context, *CompositeReq) (*CompositeResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetComposite not implemented")
+}
+func (UnimplementedRateServiceServer) FindGaps(context.Context, *FindGapsReq) (*FindGapsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindGaps not implemented")
+}
+func (UnimplementedRateServiceServer) GetDepth(context.Context, *GetDepthReq) (*GetDepthResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDepth not implemented")
+}
+func (UnimplementedRateServiceServer) StreamRates(*StreamRatesReq, grpc.ServerStreamingServer[GetRatesResp]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamRates not implemented")
+}
+func (UnimplementedRateServiceServer) GetHistoricalRates(context.Context, *GetHistoricalRatesReq) (*GetHistoricalRatesResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHistoricalRates not implemented")
+}
+func (UnimplementedRateServiceServer) ComputeRate(context.Context, *ComputeRateReq) (*ComputeRateResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ComputeRate not implemented")
+}
+func (UnimplementedRateServiceServer) PollNow(context.Context, *PollNowReq) (*PollNowResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PollNow not implemented")
+}
+func (UnimplementedRateServiceServer) GetCapabilities(context.Context, *CapabilitiesReq) (*CapabilitiesResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCapabilities not implemented")
+}
+func (UnimplementedRateServiceServer) PausePoller(context.Context, *PausePollerReq) (*PausePollerResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PausePoller not implemented")
+}
+func (UnimplementedRateServiceServer) ResumePoller(context.Context, *ResumePollerReq) (*ResumePollerResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumePoller not implemented")
+}
+func (UnimplementedRateServiceServer) GetAllLatest(context.Context, *AllLatestReq) (*AllLatestResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAllLatest not implemented")
+}
+func (UnimplementedRateServiceServer) GetMarketStatus(context.Context, *MarketStatusReq) (*MarketStatusResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMarketStatus not implemented")
+}
 func (UnimplementedRateServiceServer) mustEmbedUnimplementedRateServiceServer() {}
 func (UnimplementedRateServiceServer) testEmbeddedByValue()                     {}
 
@@ -138,6 +453,309 @@ func _RateService_Healthcheck_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RateService_GetVolatility_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVolatilityReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateServiceServer).GetVolatility(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateService_GetVolatility_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateServiceServer).GetVolatility(ctx, req.(*GetVolatilityReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateService_GetClockInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClockInfoReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateServiceServer).GetClockInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateService_GetClockInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateServiceServer).GetClockInfo(ctx, req.(*ClockInfoReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateService_SubscribeAlert_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AlertReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RateServiceServer).SubscribeAlert(m, &grpc.GenericServerStream[AlertReq, AlertResp]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RateService_SubscribeAlertServer = grpc.ServerStreamingServer[AlertResp]
+
+func _RateService_ReplayRates_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReplayReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RateServiceServer).ReplayRates(m, &grpc.GenericServerStream[ReplayReq, ReplayResp]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RateService_ReplayRatesServer = grpc.ServerStreamingServer[ReplayResp]
+
+func _RateService_SetMaintenance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetMaintenanceReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateServiceServer).SetMaintenance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateService_SetMaintenance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateServiceServer).SetMaintenance(ctx, req.(*SetMaintenanceReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateService_GetTWAP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTWAPReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateServiceServer).GetTWAP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateService_GetTWAP_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateServiceServer).GetTWAP(ctx, req.(*GetTWAPReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateService_GetComposite_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompositeReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateServiceServer).GetComposite(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateService_GetComposite_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateServiceServer).GetComposite(ctx, req.(*CompositeReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateService_FindGaps_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindGapsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateServiceServer).FindGaps(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateService_FindGaps_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateServiceServer).FindGaps(ctx, req.(*FindGapsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateService_GetDepth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDepthReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateServiceServer).GetDepth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateService_GetDepth_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateServiceServer).GetDepth(ctx, req.(*GetDepthReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateService_StreamRates_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamRatesReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RateServiceServer).StreamRates(m, &grpc.GenericServerStream[StreamRatesReq, GetRatesResp]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RateService_StreamRatesServer = grpc.ServerStreamingServer[GetRatesResp]
+
+func _RateService_GetHistoricalRates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHistoricalRatesReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateServiceServer).GetHistoricalRates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateService_GetHistoricalRates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateServiceServer).GetHistoricalRates(ctx, req.(*GetHistoricalRatesReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateService_ComputeRate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ComputeRateReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateServiceServer).ComputeRate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateService_ComputeRate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateServiceServer).ComputeRate(ctx, req.(*ComputeRateReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateService_PollNow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PollNowReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateServiceServer).PollNow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateService_PollNow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateServiceServer).PollNow(ctx, req.(*PollNowReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateService_GetCapabilities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CapabilitiesReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateServiceServer).GetCapabilities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateService_GetCapabilities_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateServiceServer).GetCapabilities(ctx, req.(*CapabilitiesReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateService_PausePoller_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PausePollerReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateServiceServer).PausePoller(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateService_PausePoller_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateServiceServer).PausePoller(ctx, req.(*PausePollerReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateService_ResumePoller_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumePollerReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateServiceServer).ResumePoller(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateService_ResumePoller_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateServiceServer).ResumePoller(ctx, req.(*ResumePollerReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateService_GetAllLatest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AllLatestReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateServiceServer).GetAllLatest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateService_GetAllLatest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateServiceServer).GetAllLatest(ctx, req.(*AllLatestReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateService_GetMarketStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MarketStatusReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateServiceServer).GetMarketStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateService_GetMarketStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateServiceServer).GetMarketStatus(ctx, req.(*MarketStatusReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // RateService_ServiceDesc is the grpc.ServiceDesc for RateService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -153,7 +771,83 @@ var RateService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Healthcheck",
 			Handler:    _RateService_Healthcheck_Handler,
 		},
+		{
+			MethodName: "GetVolatility",
+			Handler:    _RateService_GetVolatility_Handler,
+		},
+		{
+			MethodName: "GetClockInfo",
+			Handler:    _RateService_GetClockInfo_Handler,
+		},
+		{
+			MethodName: "SetMaintenance",
+			Handler:    _RateService_SetMaintenance_Handler,
+		},
+		{
+			MethodName: "GetTWAP",
+			Handler:    _RateService_GetTWAP_Handler,
+		},
+		{
+			MethodName: "GetComposite",
+			Handler:    _RateService_GetComposite_Handler,
+		},
+		{
+			MethodName: "FindGaps",
+			Handler:    _RateService_FindGaps_Handler,
+		},
+		{
+			MethodName: "GetDepth",
+			Handler:    _RateService_GetDepth_Handler,
+		},
+		{
+			MethodName: "GetHistoricalRates",
+			Handler:    _RateService_GetHistoricalRates_Handler,
+		},
+		{
+			MethodName: "ComputeRate",
+			Handler:    _RateService_ComputeRate_Handler,
+		},
+		{
+			MethodName: "PollNow",
+			Handler:    _RateService_PollNow_Handler,
+		},
+		{
+			MethodName: "GetCapabilities",
+			Handler:    _RateService_GetCapabilities_Handler,
+		},
+		{
+			MethodName: "PausePoller",
+			Handler:    _RateService_PausePoller_Handler,
+		},
+		{
+			MethodName: "ResumePoller",
+			Handler:    _RateService_ResumePoller_Handler,
+		},
+		{
+			MethodName: "GetAllLatest",
+			Handler:    _RateService_GetAllLatest_Handler,
+		},
+		{
+			MethodName: "GetMarketStatus",
+			Handler:    _RateService_GetMarketStatus_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeAlert",
+			Handler:       _RateService_SubscribeAlert_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ReplayRates",
+			Handler:       _RateService_ReplayRates_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamRates",
+			Handler:       _RateService_StreamRates_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/v1/rate-service.proto",
 }
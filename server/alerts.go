@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/atadzan/grinex-rate-service/internal/service"
+)
+
+// defaultAlertPollInterval is used when ALERT_POLL_INTERVAL is not positive
+const defaultAlertPollInterval = 5 * time.Second
+
+// maxAlertPollJitter keeps jittered poll intervals positive
+const maxAlertPollJitter = 99
+
+// SubscribeAlert polls Grinex and pushes a message whenever the mid price crosses the requested
+// threshold in the requested direction. The stream completes after the first alert unless
+// continuous is set.
+func (s *RateServiceServer) SubscribeAlert(req *pb.AlertReq, stream grpc.ServerStreamingServer[pb.AlertResp]) error {
+	ctx, span := otel.Tracer("grinex-rate-service").Start(stream.Context(), "SubscribeAlert")
+	defer span.End()
+
+	s.logger.Info("SubscribeAlert called",
+		zap.String("trading_pair", req.GetTradingPair()),
+		zap.Float64("threshold", req.GetThreshold()),
+		zap.String("direction", req.GetDirection().String()),
+	)
+
+	pair := s.grinexSvc.PairLabel(service.USDTMarket)
+	switch {
+	case req.GetTradingPair() == "":
+		return status.Error(codes.InvalidArgument, "trading_pair is required")
+	case !strings.EqualFold(req.GetTradingPair(), pair):
+		return status.Errorf(codes.InvalidArgument, "unsupported trading_pair: %q, only %s is available", req.GetTradingPair(), pair)
+	case req.GetThreshold() <= 0:
+		return status.Error(codes.InvalidArgument, "threshold must be positive")
+	case req.GetDirection() != pb.AlertDirection_ALERT_DIRECTION_ABOVE && req.GetDirection() != pb.AlertDirection_ALERT_DIRECTION_BELOW:
+		return status.Error(codes.InvalidArgument, "direction must be above or below")
+	}
+	if err := s.checkMarketAllowed(service.USDTMarket); err != nil {
+		return err
+	}
+
+	interval := s.config.Server.AlertPollInterval
+	if interval <= 0 {
+		interval = defaultAlertPollInterval
+	}
+	jitter := s.config.Server.AlertPollJitter
+	timer := time.NewTimer(jitterInterval(interval, jitter))
+	defer timer.Stop()
+
+	watcher := &alertWatcher{
+		threshold: req.GetThreshold(),
+		direction: req.GetDirection(),
+		debounce:  s.config.Server.AlertDebounce,
+	}
+
+	for {
+		rate, err := s.alertRate(ctx)
+		if err != nil {
+			s.logger.Warn("Failed to get rate for alert", zap.Error(err))
+		} else if watcher.observe(rate.MidPrice, time.Now()) {
+			err := stream.Send(&pb.AlertResp{
+				TradingPair: rate.TradingPair,
+				Threshold:   req.GetThreshold(),
+				Direction:   req.GetDirection(),
+				Price:       rate.MidPrice,
+				Timestamp:   timestamppb.New(rate.Timestamp),
+			})
+			if err != nil {
+				return err
+			}
+			if !req.GetContinuous() {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			timer.Reset(jitterInterval(interval, jitter))
+		}
+	}
+}
+
+// jitterInterval returns interval moved by a uniformly random amount of up to percent of it
+// either way, so the average stays at interval while instances polling together drift apart
+func jitterInterval(interval time.Duration, percent int) time.Duration {
+	if percent <= 0 {
+		return interval
+	}
+	spread := float64(interval) * float64(percent) / 100
+	return interval + time.Duration(spread*(2*rand.Float64()-1))
+}
+
+// alertWatcher detects threshold crossings in a sequence of prices. The first price only
+// establishes which side of the threshold the rate is on; an alert fires when a later price
+// moves past the threshold in the watched direction, at most once per debounce period.
+type alertWatcher struct {
+	threshold float64
+	direction pb.AlertDirection
+	debounce  time.Duration
+
+	observed  bool
+	beyond    bool
+	lastFired time.Time
+}
+
+// observe records a price and reports whether it triggers an alert
+func (w *alertWatcher) observe(price float64, now time.Time) bool {
+	beyond := price > w.threshold
+	if w.direction == pb.AlertDirection_ALERT_DIRECTION_BELOW {
+		beyond = price < w.threshold
+	}
+
+	crossed := w.observed && !w.beyond && beyond
+	w.observed = true
+	w.beyond = beyond
+
+	if !crossed {
+		return false
+	}
+	if !w.lastFired.IsZero() && now.Sub(w.lastFired) < w.debounce {
+		return false
+	}
+	w.lastFired = now
+	return true
+}
+
+// alertRate returns the rate alerts are checked against: the stored one in db_only serve mode
+func (s *RateServiceServer) alertRate(ctx context.Context) (*service.Rate, error) {
+	if s.dbOnly() {
+		return s.storedRate(service.USDTMarket)
+	}
+	return s.grinexSvc.GetUSDTRate(ctx)
+}
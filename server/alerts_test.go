@@ -0,0 +1,151 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+// priceSequenceHandler fakes Grinex returning a single trade per request with the next price
+// of the sequence, repeating the last one once the sequence is exhausted
+func priceSequenceHandler(prices ...float64) http.HandlerFunc {
+	var requests atomic.Int32
+	return func(w http.ResponseWriter, _ *http.Request) {
+		i := int(requests.Add(1)) - 1
+		if i >= len(prices) {
+			i = len(prices) - 1
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `[{"id": %d, "price": "%.2f", "market": "usdtrub", "created_at": "2025-07-28T21:22:14+03:00"}]`, i+1, prices[i])
+	}
+}
+
+func TestSubscribeAlert_Crossing(t *testing.T) {
+	srv, _ := newTestServer(t, priceSequenceHandler(80.50, 80.90, 81.40))
+	srv.config.Server.AlertPollInterval = 10 * time.Millisecond
+	client := newTestClient(t, srv)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.SubscribeAlert(ctx, &pb.AlertReq{
+		TradingPair: "USDT/RUB",
+		Threshold:   81,
+		Direction:   pb.AlertDirection_ALERT_DIRECTION_ABOVE,
+	})
+	require.NoError(t, err)
+
+	alert, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "USDT/RUB", alert.TradingPair)
+	assert.Equal(t, 81.40, alert.Price)
+	assert.Equal(t, 81.0, alert.Threshold)
+	assert.Equal(t, pb.AlertDirection_ALERT_DIRECTION_ABOVE, alert.Direction)
+
+	// The stream completes after the first alert unless continuous is set
+	_, err = stream.Recv()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestSubscribeAlert_Continuous(t *testing.T) {
+	srv, _ := newTestServer(t, priceSequenceHandler(81.50, 80.50, 80.90, 81.50, 80.50))
+	srv.config.Server.AlertPollInterval = 10 * time.Millisecond
+	client := newTestClient(t, srv)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.SubscribeAlert(ctx, &pb.AlertReq{
+		TradingPair: "USDT/RUB",
+		Threshold:   81,
+		Direction:   pb.AlertDirection_ALERT_DIRECTION_BELOW,
+		Continuous:  true,
+	})
+	require.NoError(t, err)
+
+	first, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, 80.50, first.Price)
+
+	second, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, 80.50, second.Price)
+
+	cancel()
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Canceled, status.Code(err))
+}
+
+func TestSubscribeAlert_InvalidArguments(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	client := newTestClient(t, srv)
+
+	tests := []struct {
+		name string
+		req  *pb.AlertReq
+	}{
+		{"missing pair", &pb.AlertReq{Threshold: 81, Direction: pb.AlertDirection_ALERT_DIRECTION_ABOVE}},
+		{"unknown pair", &pb.AlertReq{TradingPair: "BTC/RUB", Threshold: 81, Direction: pb.AlertDirection_ALERT_DIRECTION_ABOVE}},
+		{"missing threshold", &pb.AlertReq{TradingPair: "USDT/RUB", Direction: pb.AlertDirection_ALERT_DIRECTION_ABOVE}},
+		{"missing direction", &pb.AlertReq{TradingPair: "USDT/RUB", Threshold: 81}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream, err := client.SubscribeAlert(context.Background(), tt.req)
+			require.NoError(t, err)
+
+			_, err = stream.Recv()
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestAlertWatcher_Debounce(t *testing.T) {
+	watcher := &alertWatcher{
+		threshold: 81,
+		direction: pb.AlertDirection_ALERT_DIRECTION_ABOVE,
+		debounce:  time.Minute,
+	}
+	now := time.Now()
+
+	assert.False(t, watcher.observe(81.50, now), "the first price only sets the baseline")
+	assert.False(t, watcher.observe(80.50, now.Add(time.Second)))
+	assert.True(t, watcher.observe(81.50, now.Add(2*time.Second)))
+	assert.False(t, watcher.observe(81.60, now.Add(3*time.Second)), "staying above is not a crossing")
+	assert.False(t, watcher.observe(80.50, now.Add(4*time.Second)))
+	assert.False(t, watcher.observe(81.50, now.Add(5*time.Second)), "crossing again within the debounce period")
+	assert.False(t, watcher.observe(80.50, now.Add(2*time.Minute)))
+	assert.True(t, watcher.observe(81.50, now.Add(3*time.Minute)))
+}
+
+func TestJitterInterval(t *testing.T) {
+	const interval = time.Second
+	assert.Equal(t, interval, jitterInterval(interval, 0))
+
+	low, high := 800*time.Millisecond, 1200*time.Millisecond
+	seen := make(map[time.Duration]bool)
+	var total time.Duration
+	const samples = 10000
+	for i := 0; i < samples; i++ {
+		next := jitterInterval(interval, 20)
+		require.GreaterOrEqual(t, next, low)
+		require.LessOrEqual(t, next, high)
+		seen[next] = true
+		total += next
+	}
+
+	assert.Greater(t, len(seen), samples/2, "successive intervals should vary")
+	assert.InDelta(t, float64(interval), float64(total/samples), float64(10*time.Millisecond), "the average should stay at the interval")
+}
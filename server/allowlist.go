@@ -0,0 +1,29 @@
+package server
+
+import (
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// checkMarketAllowed rejects markets missing from ALLOWED_MARKETS with PermissionDenied, so the
+// service can't be used to proxy arbitrary Grinex markets. An empty allowlist allows every market.
+func (s *RateServiceServer) checkMarketAllowed(market string) error {
+	allowed := s.config.Server.AllowedMarkets
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	for _, m := range allowed {
+		if strings.EqualFold(m, market) {
+			return nil
+		}
+	}
+	return status.Errorf(codes.PermissionDenied, "market is not allowed: %s", market)
+}
+
+// checkPairAllowed checks the market of a trading pair label like USDT/RUB against the allowlist
+func (s *RateServiceServer) checkPairAllowed(pair string) error {
+	return s.checkMarketAllowed(s.grinexSvc.MarketForPair(pair))
+}
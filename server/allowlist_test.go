@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+func TestAllowedMarkets_Allowed(t *testing.T) {
+	var requests atomic.Int32
+	srv, _ := newTestServer(t, depthHandler(&requests))
+	srv.config.Server.AllowedMarkets = []string{"btcrub", "USDTRUB"}
+	srv.config.Grinex.DepthLimit = 20
+	client := newTestClient(t, srv)
+
+	_, err := client.GetDepth(context.Background(), &pb.GetDepthReq{TradingPair: "USDT/RUB"})
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestAllowedMarkets_Denied(t *testing.T) {
+	var requests atomic.Int32
+	srv, mock := newTestServer(t, depthHandler(&requests))
+	srv.config.Server.AllowedMarkets = []string{"btcrub"}
+	srv.config.Grinex.DepthLimit = 20
+	client := newTestClient(t, srv)
+	ctx := context.Background()
+	start := time.Date(2025, 7, 28, 18, 0, 0, 0, time.UTC)
+
+	calls := map[string]func() error{
+		"GetRates": func() error {
+			_, err := client.GetRates(ctx, &pb.GetRatesReq{})
+			return err
+		},
+		"GetDepth": func() error {
+			_, err := client.GetDepth(ctx, &pb.GetDepthReq{TradingPair: "USDT/RUB"})
+			return err
+		},
+		"GetComposite": func() error {
+			_, err := client.GetComposite(ctx, &pb.CompositeReq{Pairs: []*pb.CompositeWeight{
+				{TradingPair: "BTC/RUB", Weight: 1},
+				{TradingPair: "ETH/RUB", Weight: 1},
+			}})
+			return err
+		},
+		"GetVolatility": func() error {
+			_, err := client.GetVolatility(ctx, &pb.GetVolatilityReq{TradingPair: "USDT/RUB", Window: durationpb.New(time.Hour)})
+			return err
+		},
+		"GetTWAP": func() error {
+			_, err := client.GetTWAP(ctx, &pb.GetTWAPReq{TradingPair: "usdtrub", Start: timestamppb.New(start), End: timestamppb.New(start.Add(time.Hour))})
+			return err
+		},
+		"SubscribeAlert": func() error {
+			stream, err := client.SubscribeAlert(ctx, &pb.AlertReq{TradingPair: "USDT/RUB", Threshold: 81, Direction: pb.AlertDirection_ALERT_DIRECTION_ABOVE})
+			if err != nil {
+				return err
+			}
+			_, err = stream.Recv()
+			return err
+		},
+	}
+
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			err := call()
+			assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		})
+	}
+
+	assert.Zero(t, requests.Load(), "denied markets must not reach Grinex")
+	assert.NoError(t, mock.ExpectationsWereMet(), "denied markets must not reach the database")
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+	"go.opentelemetry.io/otel"
+
+	"github.com/atadzan/grinex-rate-service/internal/config"
+	"github.com/atadzan/grinex-rate-service/internal/service"
+)
+
+// grinexMethods need Grinex and fail with FailedPrecondition in db_only serve mode
+var grinexMethods = map[string]bool{
+	pb.RateService_GetClockInfo_FullMethodName: true,
+	pb.RateService_GetComposite_FullMethodName: true,
+	pb.RateService_GetDepth_FullMethodName:     true,
+	pb.RateService_PollNow_FullMethodName:      true,
+}
+
+// tokenMethods need the ADMIN_TOKEN and are disabled without one
+var tokenMethods = map[string]bool{
+	pb.RateService_SetMaintenance_FullMethodName: true,
+	pb.RateService_PollNow_FullMethodName:        true,
+	pb.RateService_PausePoller_FullMethodName:    true,
+	pb.RateService_ResumePoller_FullMethodName:   true,
+}
+
+// GetCapabilities reports what this instance serves as configured: its serve mode, rate
+// sources, price strategies, poller state and the RPCs that don't fail outright because of the config
+func (s *RateServiceServer) GetCapabilities(ctx context.Context, req *pb.CapabilitiesReq) (*pb.CapabilitiesResp, error) {
+	_, span := otel.Tracer("grinex-rate-service").Start(ctx, "GetCapabilities")
+	defer span.End()
+
+	s.logger.Info("GetCapabilities called")
+
+	resp := &pb.CapabilitiesResp{
+		ServeMode:     config.ServeModeLive,
+		Strategies:    service.PriceStrategyNames(),
+		PriceStrategy: s.config.Grinex.PriceStrategy,
+		Poller:        s.pollerState(),
+	}
+	if s.dbOnly() {
+		resp.ServeMode = config.ServeModeDBOnly
+	} else {
+		resp.Sources = []string{service.SourceTrades, service.SourceOrderBook}
+		resp.DefaultSource = s.config.Grinex.RateSource
+	}
+
+	desc := pb.RateService_ServiceDesc
+	for _, method := range desc.Methods {
+		if s.methodAvailable("/" + desc.ServiceName + "/" + method.MethodName) {
+			resp.Endpoints = append(resp.Endpoints, method.MethodName)
+		}
+	}
+	for _, stream := range desc.Streams {
+		if s.methodAvailable("/" + desc.ServiceName + "/" + stream.StreamName) {
+			resp.Endpoints = append(resp.Endpoints, stream.StreamName)
+			resp.Streaming = append(resp.Streaming, stream.StreamName)
+		}
+	}
+
+	return resp, nil
+}
+
+// methodAvailable reports whether the config allows calling the fully qualified method
+func (s *RateServiceServer) methodAvailable(fullMethod string) bool {
+	if grinexMethods[fullMethod] && s.dbOnly() {
+		return false
+	}
+	if tokenMethods[fullMethod] && s.config.Server.AdminToken == "" {
+		return false
+	}
+	if pollerMethods[fullMethod] && s.poller == nil {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/atadzan/grinex-rate-service/internal/config"
+	"github.com/atadzan/grinex-rate-service/internal/service"
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+func TestGetCapabilities_Live(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	srv.config.Server.ServeMode = config.ServeModeLive
+	srv.config.Server.AdminToken = "secret"
+	srv.config.Grinex.PriceStrategy = service.StrategyExtremes
+	srv.config.Grinex.RateSource = service.SourceOrderBook
+	client := newTestClient(t, srv)
+
+	resp, err := client.GetCapabilities(context.Background(), &pb.CapabilitiesReq{})
+
+	require.NoError(t, err)
+	assert.Equal(t, "live", resp.ServeMode)
+	assert.Equal(t, []string{"trades", "order_book"}, resp.Sources)
+	assert.Equal(t, "order_book", resp.DefaultSource)
+	assert.Contains(t, resp.Strategies, "extremes")
+	assert.Contains(t, resp.Strategies, "trimmed_mean")
+	assert.Equal(t, "extremes", resp.PriceStrategy)
+	assert.ElementsMatch(t, []string{"SubscribeAlert", "ReplayRates", "StreamRates"}, resp.Streaming)
+	for _, endpoint := range []string{"GetRates", "GetDepth", "GetClockInfo", "PollNow", "SetMaintenance", "GetCapabilities", "StreamRates"} {
+		assert.Contains(t, resp.Endpoints, endpoint)
+	}
+}
+
+func TestGetCapabilities_DBOnlyWithoutAdminToken(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	srv.config.Server.ServeMode = config.ServeModeDBOnly
+	client := newTestClient(t, srv)
+
+	resp, err := client.GetCapabilities(context.Background(), &pb.CapabilitiesReq{})
+
+	require.NoError(t, err)
+	assert.Equal(t, "db_only", resp.ServeMode)
+	assert.Empty(t, resp.Sources)
+	assert.Empty(t, resp.DefaultSource)
+	assert.Contains(t, resp.Endpoints, "GetRates")
+	assert.Contains(t, resp.Endpoints, "GetHistoricalRates")
+	for _, endpoint := range []string{"GetDepth", "GetComposite", "GetClockInfo", "PollNow", "SetMaintenance"} {
+		assert.NotContains(t, resp.Endpoints, endpoint)
+	}
+	assert.ElementsMatch(t, []string{"SubscribeAlert", "ReplayRates", "StreamRates"}, resp.Streaming)
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/atadzan/grinex-rate-service/internal/config"
+	"github.com/atadzan/grinex-rate-service/internal/service"
+)
+
+// maxCompositePairs bounds the number of concurrent Grinex requests a single basket can cause
+const maxCompositePairs = 20
+
+// GetComposite returns the weighted average mid price of a basket of pairs, computed from their
+// live rates fetched concurrently through the rate cache. A pair without a rate fails the request
+// unless exclude_missing is set, in which case it is left out and the remaining weights are
+// renormalized.
+func (s *RateServiceServer) GetComposite(ctx context.Context, req *pb.CompositeReq) (*pb.CompositeResp, error) {
+	ctx, span := otel.Tracer("grinex-rate-service").Start(ctx, "GetComposite")
+	defer span.End()
+
+	s.logger.Info("GetComposite called",
+		zap.Int("pairs", len(req.GetPairs())),
+		zap.Bool("exclude_missing", req.GetExcludeMissing()),
+	)
+
+	if err := validateCompositePairs(req.GetPairs()); err != nil {
+		return nil, err
+	}
+	markets := make([]string, len(req.GetPairs()))
+	for i, pair := range req.GetPairs() {
+		market, err := s.grinexSvc.ResolvePair(pair.GetTradingPair())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid trading_pair: %v", err)
+		}
+		if err := s.checkMarketAllowed(market); err != nil {
+			return nil, err
+		}
+		markets[i] = market
+	}
+	if s.dbOnly() {
+		return nil, status.Error(codes.FailedPrecondition, "composite rates need Grinex, which is not called in db_only serve mode")
+	}
+
+	grinexSvc, err := s.grinexServiceFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make([]*service.Rate, len(markets))
+	fetched := make([]bool, len(markets))
+	errs := make([]error, len(markets))
+	var wg sync.WaitGroup
+	for i, market := range markets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rates[i], fetched[i], errs[i] = s.cachedLiveRate(ctx, grinexSvc, market, pb.RateSource_RATE_SOURCE_UNSPECIFIED)
+		}()
+	}
+	wg.Wait()
+
+	// Component rates are served like GetRates ones: fresh rates are stored, and with
+	// GRINEX_ON_FAILURE=last_known a failed fetch falls back to the last stored rate
+	for i, market := range markets {
+		switch {
+		case errs[i] != nil && s.config.Grinex.OnFailure == config.OnFailureLastKnown:
+			if rate, err := s.lastKnownStoredRate(market, errs[i]); err == nil {
+				rates[i], errs[i] = rate, nil
+			}
+		case errs[i] == nil && fetched[i] && grinexSvc == s.grinexSvc:
+			if err := s.saveRate(rates[i]); err != nil {
+				s.logger.Error("Failed to save rate to database", zap.Error(err))
+			}
+		}
+	}
+
+	resp := &pb.CompositeResp{}
+	var weighted, totalWeight float64
+	for i, pair := range req.GetPairs() {
+		if errs[i] != nil {
+			if !req.GetExcludeMissing() {
+				s.logger.Error("Failed to get composite component rate", zap.String("trading_pair", pair.GetTradingPair()), zap.Error(errs[i]))
+				return nil, status.Errorf(codes.Unavailable, "failed to get rate for %s: %v", pair.GetTradingPair(), errs[i])
+			}
+			s.logger.Warn("Excluding composite component without a rate", zap.String("trading_pair", pair.GetTradingPair()), zap.Error(errs[i]))
+			resp.Excluded = append(resp.Excluded, pair.GetTradingPair())
+			continue
+		}
+
+		rate := rates[i]
+		weighted += pair.GetWeight() * rate.MidPrice
+		totalWeight += pair.GetWeight()
+		if resp.Timestamp == nil || rate.Timestamp.Before(resp.Timestamp.AsTime()) {
+			resp.Timestamp = timestamppb.New(rate.Timestamp)
+		}
+		resp.Components = append(resp.Components, &pb.CompositeComponent{
+			TradingPair: rate.TradingPair,
+			Weight:      pair.GetWeight(),
+			MidPrice:    rate.MidPrice,
+			Timestamp:   timestamppb.New(rate.Timestamp),
+		})
+	}
+
+	if len(resp.Components) == 0 {
+		return nil, status.Error(codes.Unavailable, "no pair in the basket has a rate")
+	}
+	resp.MidPrice = weighted / totalWeight
+
+	return resp, nil
+}
+
+// validateCompositePairs checks that a basket is non-empty, bounded and has unique pairs with positive weights
+func validateCompositePairs(pairs []*pb.CompositeWeight) error {
+	if len(pairs) == 0 {
+		return status.Error(codes.InvalidArgument, "pairs are required")
+	}
+	if len(pairs) > maxCompositePairs {
+		return status.Errorf(codes.InvalidArgument, "too many pairs: at most %d are allowed, got %d", maxCompositePairs, len(pairs))
+	}
+
+	seen := make(map[string]bool, len(pairs))
+	for _, pair := range pairs {
+		name := strings.ToUpper(strings.TrimSpace(pair.GetTradingPair()))
+		switch {
+		case name == "":
+			return status.Error(codes.InvalidArgument, "trading_pair is required")
+		case pair.GetWeight() <= 0:
+			return status.Errorf(codes.InvalidArgument, "weight must be positive: %s", pair.GetTradingPair())
+		case seen[name]:
+			return status.Errorf(codes.InvalidArgument, "duplicate trading_pair: %s", pair.GetTradingPair())
+		}
+		seen[name] = true
+	}
+	return nil
+}
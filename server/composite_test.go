@@ -0,0 +1,181 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/atadzan/grinex-rate-service/internal/config"
+	"github.com/atadzan/grinex-rate-service/internal/service"
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+// basketHandler fakes Grinex with trades for usdtrub and btcrub and no trades for other markets
+func basketHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Query().Get("market") {
+	case "usdtrub":
+		w.Write([]byte(testTradesResponse))
+	case "btcrub":
+		w.Write([]byte(`[
+			{"id": 4, "price": "9000000", "volume": "0.1", "funds": "900000", "market": "btcrub", "created_at": "2025-07-28T21:20:00+03:00"},
+			{"id": 3, "price": "8999000", "volume": "0.1", "funds": "899900", "market": "btcrub", "created_at": "2025-07-28T21:18:00+03:00"}
+		]`))
+	default:
+		w.Write([]byte(`[]`))
+	}
+}
+
+func TestGetComposite_TwoPairBasket(t *testing.T) {
+	srv, _ := newTestServer(t, basketHandler)
+	client := newTestClient(t, srv)
+
+	resp, err := client.GetComposite(context.Background(), &pb.CompositeReq{
+		Pairs: []*pb.CompositeWeight{
+			{TradingPair: "USDT/RUB", Weight: 3},
+			{TradingPair: "btcrub", Weight: 1},
+		},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Components, 2)
+	assert.Equal(t, "USDT/RUB", resp.Components[0].TradingPair)
+	assert.InDelta(t, 81.225, resp.Components[0].MidPrice, 1e-9)
+	assert.Equal(t, "BTC/RUB", resp.Components[1].TradingPair)
+	assert.InDelta(t, 8999500, resp.Components[1].MidPrice, 1e-9)
+	assert.InDelta(t, (3*81.225+8999500)/4, resp.MidPrice, 1e-6)
+	assert.Empty(t, resp.Excluded)
+	// The oldest component rate is the BTC one
+	assert.Equal(t, resp.Components[1].Timestamp.AsTime(), resp.Timestamp.AsTime())
+}
+
+func TestGetComposite_MissingPair(t *testing.T) {
+	srv, _ := newTestServer(t, basketHandler)
+	client := newTestClient(t, srv)
+	pairs := []*pb.CompositeWeight{
+		{TradingPair: "USDT/RUB", Weight: 1},
+		{TradingPair: "ETH/RUB", Weight: 1},
+	}
+
+	_, err := client.GetComposite(context.Background(), &pb.CompositeReq{Pairs: pairs})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Contains(t, err.Error(), "ETH/RUB")
+
+	resp, err := client.GetComposite(context.Background(), &pb.CompositeReq{Pairs: pairs, ExcludeMissing: true})
+	require.NoError(t, err)
+	assert.InDelta(t, 81.225, resp.MidPrice, 1e-9)
+	assert.Equal(t, []string{"ETH/RUB"}, resp.Excluded)
+	require.Len(t, resp.Components, 1)
+}
+
+func TestGetComposite_AllMissing(t *testing.T) {
+	srv, _ := newTestServer(t, failingGrinexHandler)
+	client := newTestClient(t, srv)
+
+	_, err := client.GetComposite(context.Background(), &pb.CompositeReq{
+		Pairs:          []*pb.CompositeWeight{{TradingPair: "USDT/RUB", Weight: 1}},
+		ExcludeMissing: true,
+	})
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestGetComposite_InvalidBasket(t *testing.T) {
+	srv, _ := newTestServer(t, basketHandler)
+	client := newTestClient(t, srv)
+
+	tests := map[string][]*pb.CompositeWeight{
+		"empty":          nil,
+		"missing pair":   {{Weight: 1}},
+		"zero weight":    {{TradingPair: "USDT/RUB"}},
+		"negative":       {{TradingPair: "USDT/RUB", Weight: -1}},
+		"duplicate pair": {{TradingPair: "USDT/RUB", Weight: 1}, {TradingPair: "usdt/rub", Weight: 2}},
+		"unknown pair":   {{TradingPair: "USDT/RUB", Weight: 1}, {TradingPair: "DOGE/USD", Weight: 1}},
+	}
+
+	for name, pairs := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := client.GetComposite(context.Background(), &pb.CompositeReq{Pairs: pairs})
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestGetComposite_DBOnly(t *testing.T) {
+	srv, _ := newTestServer(t, basketHandler)
+	srv.config.Server.ServeMode = config.ServeModeDBOnly
+	client := newTestClient(t, srv)
+
+	_, err := client.GetComposite(context.Background(), &pb.CompositeReq{
+		Pairs: []*pb.CompositeWeight{{TradingPair: "USDT/RUB", Weight: 1}},
+	})
+
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestGetComposite_Cached(t *testing.T) {
+	var requests atomic.Int32
+	srv, mock := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		basketHandler(w, r)
+	})
+	srv.rateCache = service.NewRateCache(time.Minute, 0)
+	client := newTestClient(t, srv)
+
+	// The rate fetched for the basket is stored once and then served from the cache
+	mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	_, err := client.GetComposite(context.Background(), &pb.CompositeReq{
+		Pairs: []*pb.CompositeWeight{{TradingPair: "USDT/RUB", Weight: 1}},
+	})
+	require.NoError(t, err)
+	resp, err := client.GetRates(context.Background(), &pb.GetRatesReq{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 81.25, resp.AskPrice)
+	assert.Equal(t, int32(1), requests.Load())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetComposite_ConfiguredRateSource(t *testing.T) {
+	srv, _ := newTestServer(t, orderBookHandler)
+	srv.config.Grinex.RateSource = service.SourceOrderBook
+	client := newTestClient(t, srv)
+
+	resp, err := client.GetComposite(context.Background(), &pb.CompositeReq{
+		Pairs: []*pb.CompositeWeight{{TradingPair: "USDT/RUB", Weight: 1}},
+	})
+
+	require.NoError(t, err)
+	// The mid of the best ask and bid of testDepthResponse, the trades would give 81.225
+	assert.InDelta(t, 81.25, resp.MidPrice, 1e-9)
+}
+
+func TestGetComposite_OnFailureLastKnown(t *testing.T) {
+	srv, mock := newTestServer(t, failingGrinexHandler)
+	srv.config.Grinex.OnFailure = config.OnFailureLastKnown
+	client := newTestClient(t, srv)
+
+	timestamp := time.Date(2025, 7, 28, 18, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT id, trading_pair, ask_price, bid_price, timestamp, created_at, vwap FROM rates").
+		WithArgs("USDT/RUB").
+		WillReturnRows(sqlmock.NewRows(latestRateColumns).
+			AddRow(1, "USDT/RUB", 81.30, 81.20, timestamp, timestamp, nil))
+
+	resp, err := client.GetComposite(context.Background(), &pb.CompositeReq{
+		Pairs: []*pb.CompositeWeight{{TradingPair: "USDT/RUB", Weight: 1}},
+	})
+
+	require.NoError(t, err)
+	assert.InDelta(t, 81.25, resp.MidPrice, 1e-9)
+	assert.True(t, timestamp.Equal(resp.Timestamp.AsTime()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"strconv"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/atadzan/grinex-rate-service/internal/service"
+)
+
+// maxComputeTrades bounds the trades a single ComputeRate request can submit
+const maxComputeTrades = 10000
+
+// ComputeRate prices the submitted trades with every registered price strategy and reports their
+// VWAP and median price, without calling Grinex or the database. A strategy that cannot price
+// the trades reports its error in its entry rather than failing the request.
+func (s *RateServiceServer) ComputeRate(ctx context.Context, req *pb.ComputeRateReq) (*pb.ComputeRateResp, error) {
+	_, span := otel.Tracer("grinex-rate-service").Start(ctx, "ComputeRate")
+	defer span.End()
+
+	s.logger.Info("ComputeRate called", zap.Int("trades", len(req.GetTrades())))
+
+	switch {
+	case len(req.GetTrades()) == 0:
+		return nil, status.Error(codes.InvalidArgument, "trades are required")
+	case len(req.GetTrades()) > maxComputeTrades:
+		return nil, status.Errorf(codes.InvalidArgument, "too many trades: %d exceeds %d", len(req.GetTrades()), maxComputeTrades)
+	}
+
+	trades := make([]service.GrinexTrade, len(req.GetTrades()))
+	for i, trade := range req.GetTrades() {
+		if trade.GetPrice() <= 0 || trade.GetVolume() < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid trade: trade %d needs a positive price and a non-negative volume", i)
+		}
+		trades[i] = service.GrinexTrade{
+			Price:  strconv.FormatFloat(trade.GetPrice(), 'f', -1, 64),
+			Volume: strconv.FormatFloat(trade.GetVolume(), 'f', -1, 64),
+			Funds:  strconv.FormatFloat(trade.GetPrice()*trade.GetVolume(), 'f', -1, 64),
+		}
+	}
+
+	resp := &pb.ComputeRateResp{}
+	for _, name := range service.PriceStrategyNames() {
+		rate := &pb.StrategyRate{Strategy: name}
+		strategy, err := s.computeStrategy(name)
+		if err == nil {
+			rate.AskPrice, rate.BidPrice, rate.MidPrice, err = strategy.Compute(trades)
+		}
+		if err != nil {
+			rate = &pb.StrategyRate{Strategy: name, Error: err.Error()}
+		}
+		resp.Rates = append(resp.Rates, rate)
+	}
+
+	// Trades without volume leave the VWAP at zero, and validated trades always have a median
+	resp.Vwap = service.TradesVWAP(trades, 0)
+	resp.MedianPrice, _ = service.TradesMedianPrice(trades)
+
+	return resp, nil
+}
+
+// computeStrategy returns the price strategy registered under name, using the configured trim
+// fraction when name is the trimmed mean strategy the server prices rates with
+func (s *RateServiceServer) computeStrategy(name string) (service.PriceStrategy, error) {
+	if name == service.StrategyTrimmedMean && s.config.Grinex.PriceStrategy == name {
+		return service.TrimmedMeanStrategy{Trim: s.config.Grinex.TrimFraction}, nil
+	}
+	return service.LookupPriceStrategy(name)
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/atadzan/grinex-rate-service/internal/service"
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+func TestComputeRate(t *testing.T) {
+	srv, mock := newTestServer(t, failingGrinexHandler)
+	client := newTestClient(t, srv)
+
+	resp, err := client.ComputeRate(context.Background(), &pb.ComputeRateReq{
+		Trades: []*pb.ComputeTrade{
+			{Price: 81.20, Volume: 10},
+			{Price: 81.30, Volume: 30},
+			{Price: 81.25, Volume: 10},
+			{Price: 81.10, Volume: 50},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.InDelta(t, 81.185, resp.Vwap, 1e-9)
+	assert.InDelta(t, 81.225, resp.MedianPrice, 1e-9)
+
+	rates := make(map[string]*pb.StrategyRate)
+	for _, rate := range resp.Rates {
+		rates[rate.Strategy] = rate
+	}
+	require.Contains(t, rates, service.StrategyExtremes)
+	assert.Equal(t, 81.30, rates[service.StrategyExtremes].AskPrice)
+	assert.Equal(t, 81.10, rates[service.StrategyExtremes].BidPrice)
+	assert.InDelta(t, 81.20, rates[service.StrategyExtremes].MidPrice, 1e-9)
+	assert.Empty(t, rates[service.StrategyExtremes].Error)
+	require.Contains(t, rates, service.StrategyTrimmedMean)
+	assert.InDelta(t, 81.2125, rates[service.StrategyTrimmedMean].MidPrice, 1e-9)
+
+	// Neither Grinex nor the database is touched
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestComputeRate_ConfiguredTrimFraction(t *testing.T) {
+	srv, _ := newTestServer(t, failingGrinexHandler)
+	srv.config.Grinex.PriceStrategy = service.StrategyTrimmedMean
+	srv.config.Grinex.TrimFraction = 0.25
+	client := newTestClient(t, srv)
+
+	resp, err := client.ComputeRate(context.Background(), &pb.ComputeRateReq{
+		Trades: []*pb.ComputeTrade{{Price: 1}, {Price: 81.20}, {Price: 81.30}, {Price: 900}},
+	})
+
+	require.NoError(t, err)
+	for _, rate := range resp.Rates {
+		if rate.Strategy == service.StrategyTrimmedMean {
+			assert.Equal(t, 81.30, rate.AskPrice)
+			assert.Equal(t, 81.20, rate.BidPrice)
+		}
+	}
+	assert.Zero(t, resp.Vwap)
+}
+
+func TestComputeRate_InvalidTrades(t *testing.T) {
+	srv, _ := newTestServer(t, failingGrinexHandler)
+	client := newTestClient(t, srv)
+
+	tests := map[string][]*pb.ComputeTrade{
+		"no trades":       nil,
+		"zero price":      {{Price: 0, Volume: 1}},
+		"negative volume": {{Price: 81.25, Volume: -1}},
+		"too many trades": make([]*pb.ComputeTrade, maxComputeTrades+1),
+	}
+
+	for name, trades := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := client.ComputeRate(context.Background(), &pb.ComputeRateReq{Trades: trades})
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
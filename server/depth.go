@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/atadzan/grinex-rate-service/internal/service"
+)
+
+// maxDepthLimit bounds the levels per side a single GetDepth request can ask Grinex for
+const maxDepthLimit = 200
+
+// GetDepth returns the order book snapshot of a pair with up to limit levels per side,
+// defaulting to GRINEX_DEPTH_LIMIT. Snapshots are briefly cached by the Grinex client.
+func (s *RateServiceServer) GetDepth(ctx context.Context, req *pb.GetDepthReq) (*pb.GetDepthResp, error) {
+	ctx, span := otel.Tracer("grinex-rate-service").Start(ctx, "GetDepth")
+	defer span.End()
+
+	s.logger.Info("GetDepth called",
+		zap.String("trading_pair", req.GetTradingPair()),
+		zap.Int32("limit", req.GetLimit()),
+	)
+
+	if strings.TrimSpace(req.GetTradingPair()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "trading_pair is required")
+	}
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = s.config.Grinex.DepthLimit
+	}
+	if limit < 1 || limit > maxDepthLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid limit: must be between 1 and %d, got %d", maxDepthLimit, limit)
+	}
+	market, err := s.grinexSvc.ResolvePair(req.GetTradingPair())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid trading_pair: %v", err)
+	}
+	if err := s.checkMarketAllowed(market); err != nil {
+		return nil, err
+	}
+	if s.dbOnly() {
+		return nil, status.Error(codes.FailedPrecondition, "depth needs Grinex, which is not called in db_only serve mode")
+	}
+
+	grinexSvc, err := s.grinexServiceFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	depth, err := grinexSvc.GetDepth(ctx, market, limit)
+	if err != nil {
+		s.logger.Error("Failed to get depth from Grinex", zap.String("trading_pair", req.GetTradingPair()), zap.Error(err))
+		return nil, status.Errorf(codes.Unavailable, "failed to get depth from Grinex: %v", err)
+	}
+
+	return &pb.GetDepthResp{
+		TradingPair: depth.TradingPair,
+		Asks:        newDepthLevels(depth.Asks),
+		Bids:        newDepthLevels(depth.Bids),
+		Timestamp:   timestamppb.New(depth.Timestamp),
+	}, nil
+}
+
+func newDepthLevels(levels []service.DepthLevel) []*pb.DepthLevel {
+	result := make([]*pb.DepthLevel, len(levels))
+	for i, level := range levels {
+		result[i] = &pb.DepthLevel{Price: level.Price, Volume: level.Volume}
+	}
+	return result
+}
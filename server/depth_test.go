@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/atadzan/grinex-rate-service/internal/config"
+	"github.com/atadzan/grinex-rate-service/internal/database"
+	"github.com/atadzan/grinex-rate-service/internal/service"
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+const testDepthResponse = `{
+	"timestamp": 1753726800,
+	"asks": [["81.30", "100.5"], ["81.35", "250"], ["81.40", "500"]],
+	"bids": [["81.20", "120"], ["81.15", "80.25"], ["81.10", "1000"]]
+}`
+
+// depthHandler fakes the Grinex depth endpoint, counting the requests it serves
+func depthHandler(requests *atomic.Int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/depth" || r.URL.Query().Get("market") != "usdtrub" {
+			http.NotFound(w, r)
+			return
+		}
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(testDepthResponse))
+	}
+}
+
+func TestGetDepth(t *testing.T) {
+	var requests atomic.Int32
+	srv, _ := newTestServer(t, depthHandler(&requests))
+	srv.config.Grinex.DepthLimit = 20
+	client := newTestClient(t, srv)
+
+	resp, err := client.GetDepth(context.Background(), &pb.GetDepthReq{TradingPair: "USDT/RUB", Limit: 2})
+
+	require.NoError(t, err)
+	assert.Equal(t, "USDT/RUB", resp.TradingPair)
+	require.Len(t, resp.Asks, 2)
+	assert.Equal(t, 81.30, resp.Asks[0].Price)
+	assert.Equal(t, 100.5, resp.Asks[0].Volume)
+	assert.Equal(t, 81.35, resp.Asks[1].Price)
+	require.Len(t, resp.Bids, 2)
+	assert.Equal(t, 81.20, resp.Bids[0].Price)
+	assert.Equal(t, 80.25, resp.Bids[1].Volume)
+	assert.Equal(t, time.Unix(1753726800, 0).UTC(), resp.Timestamp.AsTime())
+
+	resp, err = client.GetDepth(context.Background(), &pb.GetDepthReq{TradingPair: "usdtrub"})
+	require.NoError(t, err)
+	assert.Len(t, resp.Asks, 3, "the configured default limit keeps all levels")
+}
+
+func TestGetDepth_Cached(t *testing.T) {
+	var requests atomic.Int32
+	srv, _ := newTestServer(t, tradesHandler)
+	srv.config.Grinex.DepthLimit = 20
+
+	grinex := httptest.NewServer(depthHandler(&requests))
+	t.Cleanup(grinex.Close)
+	srv.grinexSvc = service.NewGrinexService(&service.GrinexConfig{
+		BaseURL:       grinex.URL,
+		Timeout:       5 * time.Second,
+		DepthCacheTTL: time.Minute,
+	}, zap.NewNop())
+	client := newTestClient(t, srv)
+
+	for i := 0; i < 3; i++ {
+		_, err := client.GetDepth(context.Background(), &pb.GetDepthReq{TradingPair: "USDT/RUB", Limit: 5})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), requests.Load())
+
+	// Another limit is a separate snapshot
+	_, err := client.GetDepth(context.Background(), &pb.GetDepthReq{TradingPair: "USDT/RUB", Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestGetDepth_Errors(t *testing.T) {
+	srv, _ := newTestServer(t, failingGrinexHandler)
+	srv.config.Grinex.DepthLimit = 20
+	client := newTestClient(t, srv)
+
+	tests := map[string]struct {
+		req  *pb.GetDepthReq
+		code codes.Code
+	}{
+		"missing pair":       {req: &pb.GetDepthReq{}, code: codes.InvalidArgument},
+		"unknown pair":       {req: &pb.GetDepthReq{TradingPair: "DOGE/USD"}, code: codes.InvalidArgument},
+		"negative limit":     {req: &pb.GetDepthReq{TradingPair: "USDT/RUB", Limit: -1}, code: codes.InvalidArgument},
+		"limit above max":    {req: &pb.GetDepthReq{TradingPair: "USDT/RUB", Limit: maxDepthLimit + 1}, code: codes.InvalidArgument},
+		"grinex unavailable": {req: &pb.GetDepthReq{TradingPair: "USDT/RUB"}, code: codes.Unavailable},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := client.GetDepth(context.Background(), tt.req)
+			assert.Equal(t, tt.code, status.Code(err))
+		})
+	}
+}
+
+func TestGetDepth_DBOnly(t *testing.T) {
+	var requests atomic.Int32
+	srv, _ := newTestServer(t, depthHandler(&requests))
+	srv.config.Server.ServeMode = config.ServeModeDBOnly
+	srv.config.Grinex.DepthLimit = 20
+	client := newTestClient(t, srv)
+
+	_, err := client.GetDepth(context.Background(), &pb.GetDepthReq{TradingPair: "USDT/RUB"})
+
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Zero(t, requests.Load())
+}
+
+// orderBookHandler fakes Grinex serving testDepthResponse for depth and testTradesResponse for trades
+func orderBookHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.URL.Path == "/api/v2/depth" {
+		w.Write([]byte(testDepthResponse))
+		return
+	}
+	w.Write([]byte(testTradesResponse))
+}
+
+func TestGetRates_OrderBookSource(t *testing.T) {
+	srv, mock := newTestServer(t, orderBookHandler)
+	client := newTestClient(t, srv)
+
+	anyArg := sqlmock.AnyArg()
+	mock.ExpectQuery("INSERT INTO rates").
+		WithArgs(anyArg, 81.30, 81.20, anyArg, anyArg, anyArg, anyArg, service.SourceOrderBook, sql.NullFloat64{}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	resp, err := client.GetRates(context.Background(), &pb.GetRatesReq{Source: pb.RateSource_RATE_SOURCE_ORDER_BOOK})
+
+	require.NoError(t, err)
+	assert.Equal(t, 81.30, resp.AskPrice)
+	assert.Equal(t, 81.20, resp.BidPrice)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRateLevels(t *testing.T) {
+	levels := rateLevels(&service.Depth{
+		Asks: []service.DepthLevel{{Price: 81.30, Volume: 100.5}, {Price: 81.35, Volume: 250}},
+		Bids: []service.DepthLevel{{Price: 81.20, Volume: 120}},
+	})
+
+	assert.Equal(t, []database.RateLevel{
+		{Side: database.SideAsk, Level: 1, Price: 81.30, Volume: 100.5},
+		{Side: database.SideAsk, Level: 2, Price: 81.35, Volume: 250},
+		{Side: database.SideBid, Level: 1, Price: 81.20, Volume: 120},
+	}, levels)
+}
+
+func TestGetRates_ConfiguredRateSource(t *testing.T) {
+	srv, mock := newTestServer(t, orderBookHandler)
+	srv.config.Grinex.RateSource = service.SourceOrderBook
+	client := newTestClient(t, srv)
+
+	mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	resp, err := client.GetRates(context.Background(), &pb.GetRatesReq{})
+	require.NoError(t, err)
+	assert.Equal(t, 81.30, resp.AskPrice, "the configured source is the order book")
+
+	// Callers can still ask for the trade derived rate
+	mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	resp, err = client.GetRates(context.Background(), &pb.GetRatesReq{Source: pb.RateSource_RATE_SOURCE_TRADES})
+	require.NoError(t, err)
+	assert.Equal(t, 81.25, resp.AskPrice)
+
+	_, err = client.GetRates(context.Background(), &pb.GetRatesReq{Source: pb.RateSource(7)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
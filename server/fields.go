@@ -0,0 +1,49 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+// validateRatesFieldMask checks that every path of mask names a top-level GetRatesResp field
+func validateRatesFieldMask(mask *fieldmaskpb.FieldMask) error {
+	fields := (&pb.GetRatesResp{}).ProtoReflect().Descriptor().Fields()
+
+	var unknown []string
+	for _, path := range mask.GetPaths() {
+		if fields.ByName(protoreflect.Name(path)) == nil {
+			unknown = append(unknown, path)
+		}
+	}
+
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown response fields: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// applyRatesFieldMask clears every field of resp not listed in mask. An empty mask keeps all fields.
+func applyRatesFieldMask(resp *pb.GetRatesResp, mask *fieldmaskpb.FieldMask) *pb.GetRatesResp {
+	if len(mask.GetPaths()) == 0 {
+		return resp
+	}
+
+	keep := make(map[protoreflect.Name]bool, len(mask.GetPaths()))
+	for _, path := range mask.GetPaths() {
+		keep[protoreflect.Name(path)] = true
+	}
+
+	msg := resp.ProtoReflect()
+	fields := msg.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		if field := fields.Get(i); !keep[field.Name()] {
+			msg.Clear(field)
+		}
+	}
+	return resp
+}
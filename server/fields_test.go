@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+func TestGetRates_FieldMaskMidPriceOnly(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	client := newTestClient(t, srv)
+
+	mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	resp, err := client.GetRates(context.Background(), &pb.GetRatesReq{
+		Fields: &fieldmaskpb.FieldMask{Paths: []string{"mid_price"}},
+	})
+
+	require.NoError(t, err)
+	assert.InDelta(t, 81.225, resp.MidPrice, 1e-9)
+	assert.Zero(t, resp.AskPrice)
+	assert.Zero(t, resp.BidPrice)
+	assert.Empty(t, resp.TradingPair)
+	assert.Nil(t, resp.Timestamp)
+	assert.Empty(t, resp.LocalTime)
+	assert.NoError(t, mock.ExpectationsWereMet()) // The full rate is still stored
+}
+
+func TestGetRates_FieldMaskEmptyReturnsAll(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	client := newTestClient(t, srv)
+
+	mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	resp, err := client.GetRates(context.Background(), &pb.GetRatesReq{Fields: &fieldmaskpb.FieldMask{}})
+
+	require.NoError(t, err)
+	assert.Equal(t, "USDT/RUB", resp.TradingPair)
+	assert.Equal(t, 81.25, resp.AskPrice)
+	assert.Equal(t, 81.20, resp.BidPrice)
+	assert.InDelta(t, 81.225, resp.MidPrice, 1e-9)
+}
+
+func TestGetRates_FieldMaskUnknownField(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	client := newTestClient(t, srv)
+
+	_, err := client.GetRates(context.Background(), &pb.GetRatesReq{
+		Fields: &fieldmaskpb.FieldMask{Paths: []string{"mid_price", "spread", "timestamp.seconds"}},
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "unknown response fields: spread, timestamp.seconds")
+	assert.NoError(t, mock.ExpectationsWereMet()) // Rejected before fetching
+}
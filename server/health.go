@@ -0,0 +1,153 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+// Statuses of Healthcheck. A failed Grinex degrades the service, which still serves stored rates,
+// while a failed database makes it unhealthy. Components are either healthy or unhealthy.
+const (
+	healthStatusHealthy   = "healthy"
+	healthStatusDegraded  = "degraded"
+	healthStatusUnhealthy = "unhealthy"
+)
+
+// Names of the dependencies checked by Healthcheck
+const (
+	componentDatabase = "database"
+	componentGrinex   = "grinex"
+)
+
+// scoreFreshness names the health score input rating the age of the newest stored rate
+const scoreFreshness = "freshness"
+
+// scoreInput is one weighted input of the health score, scored from 0 to 100
+type scoreInput struct {
+	name   string
+	weight int
+	score  float64
+}
+
+// healthScore combines the inputs into a score from 0 to 100 weighted by their share of the
+// total weight, returning each input's contribution. Inputs without a positive weight are left
+// out, and a zero total weight scores zero without contributions.
+func healthScore(inputs []scoreInput) (float64, []*pb.ScoreContribution) {
+	total := 0
+	for _, input := range inputs {
+		total += max(input.weight, 0)
+	}
+	if total == 0 {
+		return 0, nil
+	}
+
+	var score float64
+	contributions := make([]*pb.ScoreContribution, 0, len(inputs))
+	for _, input := range inputs {
+		if input.weight <= 0 {
+			continue
+		}
+		points := input.score * float64(input.weight) / float64(total)
+		score += points
+		contributions = append(contributions, &pb.ScoreContribution{
+			Name:   input.name,
+			Weight: int32(input.weight),
+			Score:  input.score,
+			Points: points,
+		})
+	}
+	return score, contributions
+}
+
+// componentScore scores a checked dependency from 100 at no latency down to 0 at maxLatency,
+// and 0 when the check failed
+func componentScore(component *pb.ComponentHealth, maxLatency time.Duration) float64 {
+	if component.Status != healthStatusHealthy {
+		return 0
+	}
+	return linearScore(time.Duration(component.LatencyMs)*time.Millisecond, maxLatency)
+}
+
+// linearScore scores value from 100 at zero down to 0 at limit and beyond. A non-positive limit
+// scores every value 100.
+func linearScore(value, limit time.Duration) float64 {
+	if limit <= 0 {
+		return 100
+	}
+	return 100 * min(max(1-float64(value)/float64(limit), 0), 1)
+}
+
+// checkComponent runs the check of a dependency and reports its status, latency and failure
+func checkComponent(name string, check func() error) (*pb.ComponentHealth, error) {
+	start := time.Now()
+	err := check()
+
+	component := &pb.ComponentHealth{
+		Name:      name,
+		Status:    healthStatusHealthy,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		component.Status = healthStatusUnhealthy
+		component.Error = err.Error()
+	}
+	return component, err
+}
+
+// healthHysteresis debounces the Grinex probes of Healthcheck so single blips don't flap the
+// status: it turns degraded after failAfter consecutive failed probes and healthy again after
+// recoverAfter consecutive successful ones. A nil hysteresis follows every probe.
+type healthHysteresis struct {
+	failAfter    int
+	recoverAfter int
+
+	mu       sync.Mutex
+	degraded bool
+	// streak counts the consecutive probes disagreeing with the current status
+	streak int
+	// lastErr is the most recent probe failure, reported while degraded
+	lastErr error
+}
+
+// newHealthHysteresis returns a hysteresis with the given thresholds, values below 1 meaning 1
+func newHealthHysteresis(failAfter, recoverAfter int) *healthHysteresis {
+	return &healthHysteresis{
+		failAfter:    max(failAfter, 1),
+		recoverAfter: max(recoverAfter, 1),
+	}
+}
+
+// observe records the result of a probe and returns whether the status is degraded along with
+// the failure to report for it
+func (h *healthHysteresis) observe(err error) (bool, error) {
+	if h == nil {
+		return err != nil, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err != nil {
+		h.lastErr = err
+	}
+
+	failed := err != nil
+	if failed != h.degraded {
+		h.streak++
+	} else {
+		h.streak = 0
+	}
+
+	threshold := h.failAfter
+	if h.degraded {
+		threshold = h.recoverAfter
+	}
+	if h.streak >= threshold {
+		h.degraded = failed
+		h.streak = 0
+	}
+
+	return h.degraded, h.lastErr
+}
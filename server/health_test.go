@@ -0,0 +1,309 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.uber.org/zap"
+
+	"github.com/atadzan/grinex-rate-service/internal/database"
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+func TestHealthHysteresis(t *testing.T) {
+	h := newHealthHysteresis(3, 2)
+	blip := errors.New("grinex blip")
+	outage := errors.New("grinex down")
+
+	observe := func(err error) bool {
+		degraded, _ := h.observe(err)
+		return degraded
+	}
+
+	// Blips shorter than the failure threshold keep the status healthy
+	assert.False(t, observe(blip))
+	assert.False(t, observe(blip))
+	assert.False(t, observe(nil))
+
+	// Sustained failures degrade it
+	assert.False(t, observe(outage))
+	assert.False(t, observe(outage))
+	degraded, failure := h.observe(outage)
+	assert.True(t, degraded)
+	assert.Equal(t, outage, failure)
+
+	// A single success doesn't recover it, a failure restarts the recovery count
+	assert.True(t, observe(nil))
+	assert.True(t, observe(outage))
+	assert.True(t, observe(nil))
+	assert.False(t, observe(nil))
+}
+
+func TestHealthHysteresis_Nil(t *testing.T) {
+	var h *healthHysteresis
+	failure := errors.New("grinex down")
+
+	degraded, err := h.observe(failure)
+	assert.True(t, degraded)
+	assert.Equal(t, failure, err)
+
+	degraded, err = h.observe(nil)
+	assert.False(t, degraded)
+	assert.NoError(t, err)
+}
+
+func TestNewHealthHysteresis_ClampsThresholds(t *testing.T) {
+	h := newHealthHysteresis(0, -1)
+
+	degraded, _ := h.observe(errors.New("grinex down"))
+	assert.True(t, degraded)
+	degraded, _ = h.observe(nil)
+	assert.False(t, degraded)
+}
+
+func TestHealthcheck_Hysteresis(t *testing.T) {
+	var failing atomic.Bool
+	srv, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			failingGrinexHandler(w, r)
+			return
+		}
+		tradesHandler(w, r)
+	})
+	srv.grinexHealth = newHealthHysteresis(2, 2)
+	client := newTestClient(t, srv)
+
+	check := func() string {
+		resp, err := client.Healthcheck(context.Background(), &pb.HealthcheckReq{})
+		require.NoError(t, err)
+		return resp.Status
+	}
+
+	// A single failed probe is a blip
+	failing.Store(true)
+	assert.Equal(t, "healthy", check())
+	failing.Store(false)
+	assert.Equal(t, "healthy", check())
+
+	// Two in a row degrade the status until two probes succeed again
+	failing.Store(true)
+	assert.Equal(t, "healthy", check())
+	assert.Equal(t, "degraded", check())
+	failing.Store(false)
+	assert.Equal(t, "degraded", check())
+	assert.Equal(t, "healthy", check())
+}
+
+func TestHealthcheck_Components(t *testing.T) {
+	tests := map[string]struct {
+		grinex          http.HandlerFunc
+		pingErr         error
+		status          string
+		databaseHealthy bool
+		grinexHealthy   bool
+	}{
+		"all healthy":     {grinex: tradesHandler, status: "healthy", databaseHealthy: true, grinexHealthy: true},
+		"grinex down":     {grinex: failingGrinexHandler, status: "degraded", databaseHealthy: true},
+		"database down":   {grinex: tradesHandler, pingErr: sql.ErrConnDone, status: "unhealthy", grinexHealthy: true},
+		"everything down": {grinex: failingGrinexHandler, pingErr: sql.ErrConnDone, status: "unhealthy"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv, _ := newTestServer(t, tt.grinex)
+			db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+			require.NoError(t, err)
+			t.Cleanup(func() { db.Close() })
+			srv.db = database.New(db, zap.NewNop())
+			mock.ExpectPing().WillReturnError(tt.pingErr)
+			client := newTestClient(t, srv)
+
+			resp, err := client.Healthcheck(context.Background(), &pb.HealthcheckReq{})
+
+			// Failures are reported in the response, never as an RPC error
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.Status)
+			require.Len(t, resp.Components, 2)
+			assertComponent(t, resp.Components[0], "database", tt.databaseHealthy)
+			assertComponent(t, resp.Components[1], "grinex", tt.grinexHealthy)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func assertComponent(t *testing.T, component *pb.ComponentHealth, name string, healthy bool) {
+	t.Helper()
+
+	assert.Equal(t, name, component.Name)
+	assert.GreaterOrEqual(t, component.LatencyMs, int64(0))
+	if healthy {
+		assert.Equal(t, "healthy", component.Status)
+		assert.Empty(t, component.Error)
+	} else {
+		assert.Equal(t, "unhealthy", component.Status)
+		assert.NotEmpty(t, component.Error)
+	}
+}
+
+func TestHealthScore(t *testing.T) {
+	tests := map[string]struct {
+		inputs []scoreInput
+		score  float64
+		points []float64
+	}{
+		"all healthy": {
+			inputs: []scoreInput{{"database", 40, 100}, {"grinex", 30, 100}, {"freshness", 30, 100}},
+			score:  100,
+			points: []float64{40, 30, 30},
+		},
+		"grinex down": {
+			inputs: []scoreInput{{"database", 40, 100}, {"grinex", 30, 0}, {"freshness", 30, 100}},
+			score:  70,
+			points: []float64{40, 0, 30},
+		},
+		"slow database and stale rates": {
+			inputs: []scoreInput{{"database", 40, 50}, {"grinex", 30, 100}, {"freshness", 30, 20}},
+			score:  56,
+			points: []float64{20, 30, 6},
+		},
+		"weights renormalized without grinex": {
+			inputs: []scoreInput{{"database", 50, 100}, {"freshness", 50, 40}},
+			score:  70,
+			points: []float64{50, 20},
+		},
+		"unweighted inputs left out": {
+			inputs: []scoreInput{{"database", 1, 80}, {"grinex", 0, 0}, {"freshness", -5, 0}},
+			score:  80,
+			points: []float64{80},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			score, contributions := healthScore(tt.inputs)
+
+			assert.InDelta(t, tt.score, score, 1e-9)
+			require.Len(t, contributions, len(tt.points))
+			var sum float64
+			for i, contribution := range contributions {
+				assert.InDelta(t, tt.points[i], contribution.Points, 1e-9, contribution.Name)
+				sum += contribution.Points
+			}
+			assert.InDelta(t, score, sum, 1e-9)
+		})
+	}
+}
+
+func TestHealthScore_NoWeights(t *testing.T) {
+	score, contributions := healthScore([]scoreInput{{"database", 0, 100}, {"freshness", 0, 100}})
+
+	assert.Zero(t, score)
+	assert.Nil(t, contributions)
+}
+
+func TestLinearScore(t *testing.T) {
+	assert.Equal(t, 100.0, linearScore(0, time.Second))
+	assert.Equal(t, 75.0, linearScore(250*time.Millisecond, time.Second))
+	assert.Equal(t, 0.0, linearScore(time.Second, time.Second))
+	assert.Equal(t, 0.0, linearScore(time.Hour, time.Second))
+	assert.Equal(t, 100.0, linearScore(-time.Second, time.Second), "clock skew")
+	assert.Equal(t, 100.0, linearScore(time.Hour, 0), "unbounded")
+}
+
+func TestComponentScore(t *testing.T) {
+	healthy := &pb.ComponentHealth{Status: "healthy", LatencyMs: 200}
+	unhealthy := &pb.ComponentHealth{Status: "unhealthy", LatencyMs: 1}
+
+	assert.Equal(t, 80.0, componentScore(healthy, time.Second))
+	assert.Equal(t, 0.0, componentScore(unhealthy, time.Second))
+}
+
+func TestHealthcheck_Score(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	srv.config.Server.HealthWeightDatabase = 40
+	srv.config.Server.HealthWeightGrinex = 30
+	srv.config.Server.HealthWeightFreshness = 30
+	srv.config.Server.HealthMaxLatency = time.Hour
+	srv.config.Server.HealthMaxRateAge = 10 * time.Minute
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+	defer provider.Shutdown(context.Background())
+	gauge, err := newHealthScoreGauge(provider.Meter("test"))
+	require.NoError(t, err)
+	srv.healthScoreGauge = gauge
+
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	srv.db = database.New(db, zap.NewNop())
+	mock.ExpectPing()
+	mock.ExpectQuery(`SELECT MAX\(created_at\) FROM rates`).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(time.Now().Add(-5 * time.Minute)))
+	client := newTestClient(t, srv)
+
+	resp, err := client.Healthcheck(context.Background(), &pb.HealthcheckReq{})
+
+	require.NoError(t, err)
+	// Both dependencies answer well within the latency bound, rates are half way to stale
+	assert.InDelta(t, 85, resp.Score, 0.5)
+	require.Len(t, resp.ScoreContributions, 3)
+	assert.Equal(t, "database", resp.ScoreContributions[0].Name)
+	assert.Equal(t, int32(40), resp.ScoreContributions[0].Weight)
+	assert.Equal(t, "grinex", resp.ScoreContributions[1].Name)
+	assert.Equal(t, "freshness", resp.ScoreContributions[2].Name)
+	assert.InDelta(t, 50, resp.ScoreContributions[2].Score, 0.5)
+	assert.InDelta(t, 15, resp.ScoreContributions[2].Points, 0.5)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	assert.Equal(t, "grinex_health_score", rm.ScopeMetrics[0].Metrics[0].Name)
+	points := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Gauge[float64]).DataPoints
+	require.Len(t, points, 1)
+	assert.Equal(t, resp.Score, points[0].Value)
+}
+
+func TestHealthcheck_ScoreDatabaseDown(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	srv.config.Server.HealthWeightDatabase = 40
+	srv.config.Server.HealthWeightGrinex = 30
+	srv.config.Server.HealthWeightFreshness = 30
+	srv.config.Server.HealthMaxLatency = time.Hour
+
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	srv.db = database.New(db, zap.NewNop())
+	mock.ExpectPing().WillReturnError(sql.ErrConnDone)
+	client := newTestClient(t, srv)
+
+	resp, err := client.Healthcheck(context.Background(), &pb.HealthcheckReq{})
+
+	// Only Grinex scores, freshness is not queried from a failed database
+	require.NoError(t, err)
+	assert.Equal(t, "unhealthy", resp.Status)
+	assert.InDelta(t, 30, resp.Score, 0.5)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHealthcheck_ScoreDisabled(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	client := newTestClient(t, srv)
+
+	resp, err := client.Healthcheck(context.Background(), &pb.HealthcheckReq{})
+
+	require.NoError(t, err)
+	assert.Zero(t, resp.Score)
+	assert.Empty(t, resp.ScoreContributions)
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// runHeartbeat writes a heartbeat row for instance right away and then every interval until
+// ctx is cancelled, so monitoring can tell the service is alive and persisting even when no
+// client requests rates. Failed writes are logged and retried on the next tick.
+func (s *RateServiceServer) runHeartbeat(ctx context.Context, interval time.Duration, instance string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.db.SaveHeartbeat(instance, time.Now()); err != nil {
+			s.logger.Warn("Failed to save heartbeat", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// heartbeatInstance names this instance in heartbeat rows by its hostname
+func heartbeatInstance() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return "unknown"
+	}
+	return hostname
+}
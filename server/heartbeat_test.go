@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunHeartbeat(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	mock.MatchExpectationsInOrder(true)
+	for i := 0; i < 3; i++ {
+		mock.ExpectExec("INSERT INTO heartbeats").
+			WithArgs("instance-1", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(int64(i+1), 1))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		srv.runHeartbeat(ctx, 10*time.Millisecond, "instance-1")
+	}()
+
+	assert.Eventually(t, func() bool { return mock.ExpectationsWereMet() == nil }, time.Second, 5*time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("heartbeat did not stop after cancellation")
+	}
+}
+
+func TestRunHeartbeat_ContinuesAfterFailure(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	mock.ExpectExec("INSERT INTO heartbeats").WillReturnError(assert.AnError)
+	mock.ExpectExec("INSERT INTO heartbeats").WillReturnResult(sqlmock.NewResult(1, 1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go srv.runHeartbeat(ctx, 10*time.Millisecond, "instance-1")
+
+	assert.Eventually(t, func() bool { return mock.ExpectationsWereMet() == nil }, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GetHistoricalRates returns the stored rates of a pair created within a time range, newest
+// first and at most limit of them. The range may span at most MAX_QUERY_RANGE. A range without
+// rates returns an empty list.
+func (s *RateServiceServer) GetHistoricalRates(ctx context.Context, req *pb.GetHistoricalRatesReq) (*pb.GetHistoricalRatesResp, error) {
+	ctx, span := otel.Tracer("grinex-rate-service").Start(ctx, "GetHistoricalRates")
+	defer span.End()
+
+	s.logger.Info("GetHistoricalRates called",
+		zap.String("trading_pair", req.GetTradingPair()),
+		zap.Int32("limit", req.GetLimit()),
+	)
+
+	switch {
+	case req.GetTradingPair() == "":
+		return nil, status.Error(codes.InvalidArgument, "trading_pair is required")
+	case req.GetStart() == nil || req.GetEnd() == nil:
+		return nil, status.Error(codes.InvalidArgument, "start and end are required")
+	case req.GetLimit() < 0:
+		return nil, status.Error(codes.InvalidArgument, "limit must not be negative")
+	}
+
+	start, end := req.GetStart().AsTime(), req.GetEnd().AsTime()
+	if !start.Before(end) {
+		return nil, status.Error(codes.InvalidArgument, "start must be before end")
+	}
+	if maxRange := s.config.Database.MaxQueryRange; maxRange > 0 && end.Sub(start) > maxRange {
+		return nil, status.Errorf(codes.InvalidArgument, "time range is too wide: %s exceeds %s", end.Sub(start), maxRange)
+	}
+	market, err := s.grinexSvc.ResolvePair(req.GetTradingPair())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid trading_pair: %v", err)
+	}
+	if err := s.checkMarketAllowed(market); err != nil {
+		return nil, err
+	}
+
+	records, err := s.db.GetRatesByTimeRange(ctx, s.grinexSvc.PairLabel(market), start, end, "", int(req.GetLimit()))
+	if err != nil {
+		s.logger.Error("Failed to get historical rates", zap.Error(err))
+		return nil, databaseError(err, "failed to get historical rates")
+	}
+
+	resp := &pb.GetHistoricalRatesResp{Rates: make([]*pb.HistoricalRate, len(records))}
+	for i, record := range records {
+		resp.Rates[i] = &pb.HistoricalRate{
+			TradingPair: record.TradingPair,
+			AskPrice:    record.AskPrice,
+			BidPrice:    record.BidPrice,
+			Timestamp:   timestamppb.New(record.Timestamp),
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+var historicalRateColumns = []string{"id", "trading_pair", "ask_price", "bid_price", "timestamp", "created_at", "source"}
+
+func TestGetHistoricalRates(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	client := newTestClient(t, srv)
+
+	start := time.Date(2025, 7, 28, 18, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	// The limit is applied by the query, so the database returns only the newest two rates
+	mock.ExpectQuery(`SELECT id, trading_pair, ask_price, bid_price, timestamp, created_at, source FROM rates .* LIMIT \$4`).
+		WithArgs("USDT/RUB", start, end, 2).
+		WillReturnRows(sqlmock.NewRows(historicalRateColumns).
+			AddRow(3, "USDT/RUB", 81.30, 81.20, start.Add(2*time.Hour), start.Add(2*time.Hour), "trades").
+			AddRow(2, "USDT/RUB", 81.25, 81.15, start.Add(time.Hour), start.Add(time.Hour), "trades"))
+
+	// The market name resolves to the pair label rates are stored under
+	resp, err := client.GetHistoricalRates(context.Background(), &pb.GetHistoricalRatesReq{
+		TradingPair: "usdtrub",
+		Start:       timestamppb.New(start),
+		End:         timestamppb.New(end),
+		Limit:       2,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Rates, 2)
+	assert.Equal(t, "USDT/RUB", resp.Rates[0].TradingPair)
+	assert.Equal(t, 81.30, resp.Rates[0].AskPrice)
+	assert.Equal(t, 81.20, resp.Rates[0].BidPrice)
+	assert.Equal(t, start.Add(2*time.Hour), resp.Rates[0].Timestamp.AsTime())
+	assert.Equal(t, 81.25, resp.Rates[1].AskPrice)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetHistoricalRates_Empty(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	client := newTestClient(t, srv)
+
+	start := time.Date(2025, 7, 28, 18, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT id, trading_pair, ask_price, bid_price, timestamp, created_at, source FROM rates").
+		WillReturnRows(sqlmock.NewRows(historicalRateColumns))
+
+	resp, err := client.GetHistoricalRates(context.Background(), &pb.GetHistoricalRatesReq{
+		TradingPair: "USDT/RUB",
+		Start:       timestamppb.New(start),
+		End:         timestamppb.New(start.Add(time.Hour)),
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, resp.Rates)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetHistoricalRates_Errors(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	srv.config.Database.MaxQueryRange = 30 * 24 * time.Hour
+	client := newTestClient(t, srv)
+
+	start := time.Date(2025, 7, 28, 18, 0, 0, 0, time.UTC)
+	day := func(days int) *timestamppb.Timestamp {
+		return timestamppb.New(start.Add(time.Duration(days) * 24 * time.Hour))
+	}
+
+	tests := map[string]*pb.GetHistoricalRatesReq{
+		"missing pair":   {Start: day(0), End: day(1)},
+		"missing range":  {TradingPair: "USDT/RUB"},
+		"end before":     {TradingPair: "USDT/RUB", Start: day(1), End: day(0)},
+		"empty range":    {TradingPair: "USDT/RUB", Start: day(1), End: day(1)},
+		"too wide":       {TradingPair: "USDT/RUB", Start: day(0), End: day(31)},
+		"unknown pair":   {TradingPair: "DOGE/USD", Start: day(0), End: day(1)},
+		"negative limit": {TradingPair: "USDT/RUB", Start: day(0), End: day(1), Limit: -1},
+	}
+
+	for name, req := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := client.GetHistoricalRates(context.Background(), req)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
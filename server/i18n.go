@@ -0,0 +1,210 @@
+package server
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// langKey is the metadata key selecting the error message language, taking precedence over Accept-Language
+	langKey = "x-lang"
+	// acceptLanguageKey carries an HTTP style Accept-Language list, e.g. "ru-RU,ru;q=0.9,en;q=0.8"
+	acceptLanguageKey = "accept-language"
+	// defaultLanguage is the language error messages are written in
+	defaultLanguage = "en"
+)
+
+// messageCatalog maps English error messages to their translations by language. A message
+// matches either exactly or as a prefix followed by ": ", in which case the prefix is translated
+// and the details after it are translated the same way when the catalog knows them, otherwise
+// kept as is.
+var messageCatalog = map[string]map[string]string{
+	"ru": {
+		"trading_pair is required":                                "trading_pair обязателен",
+		"start and end are required":                              "start и end обязательны",
+		"max_gap is required":                                     "max_gap обязателен",
+		"failed to find gaps":                                     "не удалось найти пропуски",
+		"window is required":                                      "window обязателен",
+		"pairs are required":                                      "pairs обязательны",
+		"threshold must be positive":                              "threshold должен быть положительным",
+		"direction must be above or below":                        "direction должен быть above или below",
+		"speed must not be negative":                              "speed не может быть отрицательным",
+		"market is not allowed":                                   "рынок не разрешен",
+		"invalid admin token":                                     "неверный токен администратора",
+		"no pair in the basket has a rate":                        "ни для одной пары корзины нет курса",
+		defaultMaintenanceMessage:                                 "сервис на обслуживании",
+		"failed to get latest rate":                               "не удалось получить последний курс",
+		"failed to get latest rates":                              "не удалось получить последние курсы",
+		"failed to get volatility":                                "не удалось рассчитать волатильность",
+		"failed to get TWAP":                                      "не удалось рассчитать TWAP",
+		"failed to get rates for replay":                          "не удалось получить курсы для воспроизведения",
+		"invalid trading_pair":                                    "неверный trading_pair",
+		"interval must be positive":                               "interval должен быть положительным",
+		"limit must not be negative":                              "limit не может быть отрицательным",
+		"start must be before end":                                "start должен быть раньше end",
+		"time range is too wide":                                  "слишком большой период",
+		"failed to get historical rates":                          "не удалось получить историю курсов",
+		"trades are required":                                     "trades обязательны",
+		"too many trades":                                         "слишком много сделок",
+		"invalid trade":                                           "неверная сделка",
+		"invalid fields":                                          "неверные поля",
+		"unknown response fields":                                 "неизвестные поля ответа",
+		"unknown source":                                          "неизвестный источник",
+		"invalid timezone":                                        "неверный часовой пояс",
+		"unsupported trading_pair":                                "неподдерживаемый trading_pair",
+		"too many pairs":                                          "слишком много пар",
+		"weight must be positive":                                 "вес должен быть положительным",
+		"duplicate trading_pair":                                  "повторяющийся trading_pair",
+		"invalid limit":                                           "неверный limit",
+		"missing required metadata":                               "отсутствуют обязательные метаданные",
+		"invalid base URL override":                               "неверное переопределение базового URL",
+		"invalid time range":                                      "неверный период",
+		"failed to save rate":                                     "не удалось сохранить курс",
+		"admin RPCs are disabled, set ADMIN_TOKEN to enable them": "административные методы отключены, задайте ADMIN_TOKEN, чтобы включить их",
+		"clock info needs Grinex, which is not called in db_only serve mode":     "для сведений о часах нужен Grinex, а в режиме db_only он не вызывается",
+		"composite rates need Grinex, which is not called in db_only serve mode": "для композитного курса нужен Grinex, а в режиме db_only он не вызывается",
+		"polling needs Grinex, which is not called in db_only serve mode":        "для опроса нужен Grinex, а в режиме db_only он не вызывается",
+		"poller is not running, set POLL_ENABLED to enable it":                   "периодический опрос не запущен, задайте POLL_ENABLED, чтобы включить его",
+	},
+}
+
+// SupportedLanguage reports whether error messages can be returned in lang
+func SupportedLanguage(lang string) bool {
+	_, ok := messageCatalog[lang]
+	return ok || lang == defaultLanguage
+}
+
+// LocalizeUnaryInterceptor translates the status messages of unary RPC errors into the language
+// requested by the x-lang or Accept-Language metadata, falling back to fallback
+func LocalizeUnaryInterceptor(fallback string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			err = localizeError(err, requestLanguage(ctx, fallback))
+		}
+		return resp, err
+	}
+}
+
+// LocalizeStreamInterceptor translates the status messages of streaming RPC errors like LocalizeUnaryInterceptor
+func LocalizeStreamInterceptor(fallback string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		if err != nil {
+			err = localizeError(err, requestLanguage(ss.Context(), fallback))
+		}
+		return err
+	}
+}
+
+// requestLanguage picks the first supported language from x-lang, then Accept-Language in
+// order of preference, and otherwise returns fallback
+func requestLanguage(ctx context.Context, fallback string) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var candidates []string
+	candidates = append(candidates, md.Get(langKey)...)
+	for _, value := range md.Get(acceptLanguageKey) {
+		candidates = append(candidates, parseAcceptLanguage(value)...)
+	}
+
+	for _, candidate := range candidates {
+		lang := baseLanguage(candidate)
+		if SupportedLanguage(lang) {
+			return lang
+		}
+	}
+	return fallback
+}
+
+// parseAcceptLanguage returns the language tags of an Accept-Language value sorted by their
+// q weights, highest first, dropping tags with a zero weight
+func parseAcceptLanguage(value string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(value, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if qValue, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(qValue, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag: tag, q: q})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	result := make([]string, len(tags))
+	for i, tag := range tags {
+		result[i] = tag.tag
+	}
+	return result
+}
+
+// baseLanguage reduces a language tag like "ru-RU" to its lower-cased primary subtag
+func baseLanguage(tag string) string {
+	base, _, _ := strings.Cut(strings.TrimSpace(tag), "-")
+	return strings.ToLower(base)
+}
+
+// localizeError replaces the message of a status error with its translation, keeping the code
+// and details. Errors without a status or a known message are returned unchanged.
+func localizeError(err error, lang string) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	message, ok := localizeMessage(st.Message(), lang)
+	if !ok {
+		return err
+	}
+
+	proto := st.Proto()
+	proto.Message = message
+	return status.ErrorProto(proto)
+}
+
+// localizeMessage translates message from the catalog of lang
+func localizeMessage(message, lang string) (string, bool) {
+	catalog := messageCatalog[lang]
+	if catalog == nil {
+		return "", false
+	}
+
+	if translated, ok := catalog[message]; ok {
+		return translated, true
+	}
+
+	prefix, details, ok := strings.Cut(message, ": ")
+	if !ok {
+		return "", false
+	}
+	if translated, ok := catalog[prefix]; ok {
+		if localized, ok := localizeMessage(details, lang); ok {
+			details = localized
+		}
+		return translated + ": " + details, true
+	}
+	return "", false
+}
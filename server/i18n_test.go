@@ -0,0 +1,205 @@
+package server
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+func newLocalizedTestClient(t *testing.T, srv *RateServiceServer, fallback string) pb.RateServiceClient {
+	return newTestClient(t, srv,
+		grpc.UnaryInterceptor(LocalizeUnaryInterceptor(fallback)),
+		grpc.StreamInterceptor(LocalizeStreamInterceptor(fallback)),
+	)
+}
+
+func TestLocalize_Russian(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	client := newLocalizedTestClient(t, srv, defaultLanguage)
+
+	tests := map[string]metadata.MD{
+		"x-lang":          metadata.Pairs("x-lang", "ru"),
+		"accept-language": metadata.Pairs("accept-language", "de-DE,ru-RU;q=0.9,en;q=0.8"),
+		"x-lang wins":     metadata.Pairs("x-lang", "ru", "accept-language", "en"),
+	}
+
+	for name, md := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+			_, err := client.GetTWAP(ctx, &pb.GetTWAPReq{})
+
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			assert.Equal(t, "trading_pair обязателен", status.Convert(err).Message())
+		})
+	}
+}
+
+func TestLocalize_DefaultsToEnglish(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	client := newLocalizedTestClient(t, srv, defaultLanguage)
+
+	tests := map[string]metadata.MD{
+		"no metadata":          nil,
+		"unsupported language": metadata.Pairs("accept-language", "de-DE,fr;q=0.5"),
+		"zero weight":          metadata.Pairs("accept-language", "ru;q=0"),
+	}
+
+	for name, md := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+			_, err := client.GetTWAP(ctx, &pb.GetTWAPReq{})
+
+			assert.Equal(t, "trading_pair is required", status.Convert(err).Message())
+		})
+	}
+}
+
+func TestLocalize_ConfiguredFallback(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	client := newLocalizedTestClient(t, srv, "ru")
+
+	_, err := client.GetTWAP(context.Background(), &pb.GetTWAPReq{})
+	assert.Equal(t, "trading_pair обязателен", status.Convert(err).Message())
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-lang", "en")
+	_, err = client.GetTWAP(ctx, &pb.GetTWAPReq{})
+	assert.Equal(t, "trading_pair is required", status.Convert(err).Message())
+}
+
+func TestLocalize_Stream(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	client := newLocalizedTestClient(t, srv, defaultLanguage)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-lang", "ru")
+
+	stream, err := client.ReplayRates(ctx, &pb.ReplayReq{
+		TradingPair: "USDT/RUB",
+		Start:       timestamppb.New(time.Now()),
+		Speed:       1,
+	})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, "start и end обязательны", status.Convert(err).Message())
+}
+
+func TestLocalizeMessage(t *testing.T) {
+	message, ok := localizeMessage("failed to get TWAP: no rates found in the time range", "ru")
+	assert.True(t, ok)
+	assert.Equal(t, "не удалось рассчитать TWAP: no rates found in the time range", message)
+
+	_, ok = localizeMessage("something unexpected", "ru")
+	assert.False(t, ok)
+
+	_, ok = localizeMessage("trading_pair is required", "en")
+	assert.False(t, ok)
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	assert.Equal(t, []string{"ru-RU", "en", "de"}, parseAcceptLanguage("de;q=0.5, ru-RU, en;q=0.8, *;q=0.1, fr;q=0"))
+	assert.Empty(t, parseAcceptLanguage(""))
+}
+
+// invalidArgumentMessages returns the messages, or their format strings, of the InvalidArgument
+// errors created in the non-test sources of this package, along with the messages passed to
+// databaseError, which reports invalid time ranges as InvalidArgument
+func invalidArgumentMessages(t *testing.T) []string {
+	t.Helper()
+
+	files, err := filepath.Glob("*.go")
+	require.NoError(t, err)
+
+	var messages []string
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		parsed, err := parser.ParseFile(fset, file, nil, 0)
+		require.NoError(t, err)
+
+		ast.Inspect(parsed, func(node ast.Node) bool {
+			if decl, ok := node.(*ast.FuncDecl); ok && decl.Name.Name == "databaseError" {
+				return false // Its messages are collected from its callers
+			}
+			call, ok := node.(*ast.CallExpr)
+			if !ok || len(call.Args) < 2 {
+				return true
+			}
+
+			var message ast.Expr
+			switch fun := call.Fun.(type) {
+			case *ast.SelectorExpr:
+				code, ok := call.Args[0].(*ast.SelectorExpr)
+				if !ok || code.Sel.Name != "InvalidArgument" || (fun.Sel.Name != "Error" && fun.Sel.Name != "Errorf") {
+					return true
+				}
+				message = call.Args[1]
+			case *ast.Ident:
+				if fun.Name != "databaseError" {
+					return true
+				}
+				message = call.Args[1]
+			default:
+				return true
+			}
+
+			literal, ok := message.(*ast.BasicLit)
+			if !assert.True(t, ok && literal.Kind == token.STRING, "%s: InvalidArgument message is not a string literal", fset.Position(message.Pos())) {
+				return true
+			}
+			unquoted, err := strconv.Unquote(literal.Value)
+			require.NoError(t, err)
+			messages = append(messages, unquoted)
+			return true
+		})
+	}
+	return messages
+}
+
+func TestMessageCatalog_CoversInvalidArgument(t *testing.T) {
+	messages := invalidArgumentMessages(t)
+	require.NotEmpty(t, messages)
+
+	for lang, catalog := range messageCatalog {
+		for _, message := range messages {
+			// Only the part before the details is looked up, so it must not vary
+			key, _, _ := strings.Cut(message, ": ")
+			assert.NotContains(t, key, "%", "%q: formatted values must follow the translated prefix", message)
+			_, ok := catalog[key]
+			assert.True(t, ok, "%s catalog misses %q", lang, message)
+		}
+	}
+}
+
+func TestLocalizeMessage_Details(t *testing.T) {
+	tests := map[string]string{
+		"invalid fields: unknown response fields: spread":                 "неверные поля: неизвестные поля ответа: spread",
+		"failed to get TWAP: invalid time range: window must be positive": "не удалось рассчитать TWAP: неверный период: window must be positive",
+		"invalid timezone: unknown time zone Mars/Olympus":                "неверный часовой пояс: unknown time zone Mars/Olympus",
+	}
+
+	for message, expected := range tests {
+		translated, ok := localizeMessage(message, "ru")
+		assert.True(t, ok, message)
+		assert.Equal(t, expected, translated)
+	}
+}
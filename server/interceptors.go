@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+// healthMethods are exempt from request requirements so probes keep working
+var healthMethods = map[string]bool{
+	pb.RateService_Healthcheck_FullMethodName: true,
+}
+
+// RequiredMetadataUnaryInterceptor rejects unary RPCs missing any of the required metadata keys
+func RequiredMetadataUnaryInterceptor(keys []string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := checkRequiredMetadata(ctx, info.FullMethod, keys); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// RequiredMetadataStreamInterceptor rejects streaming RPCs missing any of the required metadata keys
+func RequiredMetadataStreamInterceptor(keys []string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := checkRequiredMetadata(ss.Context(), info.FullMethod, keys); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func checkRequiredMetadata(ctx context.Context, method string, keys []string) error {
+	if len(keys) == 0 || healthMethods[method] {
+		return nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var missing []string
+	for _, key := range keys {
+		values := md.Get(key)
+		if len(values) == 0 || values[0] == "" {
+			missing = append(missing, key)
+		}
+	}
+
+	if len(missing) > 0 {
+		return status.Errorf(codes.InvalidArgument, "missing required metadata: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// TimeoutUnaryInterceptor bounds unary RPCs by a per-method deadline looked up by the
+// lower-cased method name in timeouts, falling back to fallback. A non-positive timeout leaves
+// the method unbounded. The handler runs with the deadline on its context and stops its Grinex
+// and database calls when it passes, so nothing keeps running after the client was told the
+// RPC failed with DeadlineExceeded. Streaming RPCs are long-lived by design and are not bounded.
+func TimeoutUnaryInterceptor(timeouts map[string]time.Duration, fallback time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		timeout := methodTimeout(info.FullMethod, timeouts, fallback)
+		if timeout <= 0 {
+			return handler(ctx, req)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		resp, err := handler(ctx, req)
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, status.Errorf(codes.DeadlineExceeded, "%s exceeded its %s timeout", info.FullMethod, timeout)
+		}
+		return resp, err
+	}
+}
+
+// methodTimeout returns the timeout configured for the short name of fullMethod, or fallback
+func methodTimeout(fullMethod string, timeouts map[string]time.Duration, fallback time.Duration) time.Duration {
+	name := strings.ToLower(fullMethod[strings.LastIndex(fullMethod, "/")+1:])
+	if timeout, ok := timeouts[name]; ok {
+		return timeout
+	}
+	return fallback
+}
+
+// validateMethodTimeouts rejects timeouts for methods the service does not have, so a typo
+// does not silently leave a method on the default timeout
+func validateMethodTimeouts(timeouts map[string]time.Duration) error {
+	methods := make(map[string]bool, len(pb.RateService_ServiceDesc.Methods))
+	for _, method := range pb.RateService_ServiceDesc.Methods {
+		methods[strings.ToLower(method.MethodName)] = true
+	}
+
+	for name := range timeouts {
+		if !methods[name] {
+			return fmt.Errorf("method timeout configured for unknown unary method: %s", name)
+		}
+	}
+	return nil
+}
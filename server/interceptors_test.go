@@ -0,0 +1,151 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+func TestRequiredMetadataInterceptor_Missing(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	client := newTestClient(t, srv, grpc.UnaryInterceptor(RequiredMetadataUnaryInterceptor([]string{"client-id", "x-team"})))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-team", "desk")
+	_, err := client.GetVolatility(ctx, &pb.GetVolatilityReq{TradingPair: "USDT/RUB", Window: durationpb.New(time.Hour)})
+
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, "missing required metadata: client-id", status.Convert(err).Message())
+}
+
+func TestRequiredMetadataInterceptor_Present(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	client := newTestClient(t, srv, grpc.UnaryInterceptor(RequiredMetadataUnaryInterceptor([]string{"client-id"})))
+
+	mock.ExpectQuery("SELECT").
+		WillReturnRows(sqlmock.NewRows([]string{"mid_price"}).AddRow(81.0))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "client-id", "desk-1")
+	_, err := client.GetVolatility(ctx, &pb.GetVolatilityReq{TradingPair: "USDT/RUB", Window: durationpb.New(time.Hour)})
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRequiredMetadataInterceptor_HealthcheckBypass(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	client := newTestClient(t, srv, grpc.UnaryInterceptor(RequiredMetadataUnaryInterceptor([]string{"client-id"})))
+
+	resp, err := client.Healthcheck(context.Background(), &pb.HealthcheckReq{})
+
+	require.NoError(t, err)
+	assert.Equal(t, "healthy", resp.Status)
+}
+
+func TestTimeoutInterceptor_SlowMethodTimesOut(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	timeouts := map[string]time.Duration{"gettwap": 50 * time.Millisecond}
+	client := newTestClient(t, srv, grpc.UnaryInterceptor(TimeoutUnaryInterceptor(timeouts, time.Minute)))
+
+	start := time.Date(2025, 7, 28, 18, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT").
+		WillDelayFor(time.Second).
+		WillReturnRows(sqlmock.NewRows([]string{"mid_price", "created_at"}).AddRow(80.0, start))
+
+	began := time.Now()
+	_, err := client.GetTWAP(context.Background(), &pb.GetTWAPReq{
+		TradingPair: "USDT/RUB",
+		Start:       timestamppb.New(start),
+		End:         timestamppb.New(start.Add(time.Hour)),
+	})
+	elapsed := time.Since(began)
+
+	require.Error(t, err)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "GetTWAP exceeded its 50ms timeout")
+	assert.GreaterOrEqual(t, elapsed, 50*time.Millisecond)
+	assert.Less(t, elapsed, 500*time.Millisecond, "did not return at the method's budget")
+}
+
+func TestTimeoutInterceptor_NoSideEffectsAfterDeadline(t *testing.T) {
+	srv, mock := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		tradesHandler(w, r)
+	})
+	timeouts := map[string]time.Duration{"getrates": 50 * time.Millisecond}
+	client := newTestClient(t, srv, grpc.UnaryInterceptor(TimeoutUnaryInterceptor(timeouts, time.Minute)))
+
+	mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	_, err := client.GetRates(context.Background(), &pb.GetRatesReq{})
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	// Had the handler kept running, it would have saved the rate once Grinex answered
+	time.Sleep(300 * time.Millisecond)
+	assert.Error(t, mock.ExpectationsWereMet(), "the rate was saved after the deadline")
+}
+
+func TestTimeoutInterceptor_WaitsForHandler(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	timeouts := map[string]time.Duration{"getrates": 50 * time.Millisecond}
+	client := newTestClient(t, srv, grpc.UnaryInterceptor(TimeoutUnaryInterceptor(timeouts, time.Minute)))
+
+	// The save outlasts the deadline but completes, so the client must not be told the RPC failed
+	mock.ExpectQuery("INSERT INTO rates").
+		WillDelayFor(150 * time.Millisecond).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	resp, err := client.GetRates(context.Background(), &pb.GetRatesReq{})
+
+	require.NoError(t, err)
+	assert.Equal(t, 81.25, resp.AskPrice)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTimeoutInterceptor_MethodOverridesDefault(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	timeouts := map[string]time.Duration{"gettwap": 5 * time.Second}
+	client := newTestClient(t, srv, grpc.UnaryInterceptor(TimeoutUnaryInterceptor(timeouts, 10*time.Millisecond)))
+
+	start := time.Date(2025, 7, 28, 18, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT").
+		WillDelayFor(50 * time.Millisecond).
+		WillReturnRows(sqlmock.NewRows([]string{"mid_price", "created_at"}).AddRow(80.0, start))
+
+	resp, err := client.GetTWAP(context.Background(), &pb.GetTWAPReq{
+		TradingPair: "USDT/RUB",
+		Start:       timestamppb.New(start),
+		End:         timestamppb.New(start.Add(time.Hour)),
+	})
+
+	require.NoError(t, err)
+	assert.InDelta(t, 80.0, resp.Twap, 1e-9)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMethodTimeout(t *testing.T) {
+	timeouts := map[string]time.Duration{"getrates": 10 * time.Second, "healthcheck": 0}
+
+	assert.Equal(t, 10*time.Second, methodTimeout(pb.RateService_GetRates_FullMethodName, timeouts, time.Second))
+	assert.Equal(t, time.Second, methodTimeout(pb.RateService_GetTWAP_FullMethodName, timeouts, time.Second))
+	assert.Equal(t, time.Duration(0), methodTimeout(pb.RateService_Healthcheck_FullMethodName, timeouts, time.Second))
+}
+
+func TestValidateMethodTimeouts(t *testing.T) {
+	assert.NoError(t, validateMethodTimeouts(nil))
+	assert.NoError(t, validateMethodTimeouts(map[string]time.Duration{"getrates": time.Second, "findgaps": time.Second}))
+	assert.ErrorContains(t, validateMethodTimeouts(map[string]time.Duration{"getcachedrate": time.Second}), "getcachedrate")
+}
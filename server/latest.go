@@ -0,0 +1,40 @@
+package server
+
+import (
+	"context"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GetAllLatest returns the latest stored rate of every pair in one compact response, so clients
+// tracking many pairs need a single call instead of one GetRates per pair. Pairs outside
+// ALLOWED_MARKETS are left out. It never calls Grinex.
+func (s *RateServiceServer) GetAllLatest(ctx context.Context, req *pb.AllLatestReq) (*pb.AllLatestResp, error) {
+	ctx, span := otel.Tracer("grinex-rate-service").Start(ctx, "GetAllLatest")
+	defer span.End()
+
+	s.logger.Info("GetAllLatest called")
+
+	records, err := s.db.GetAllLatestRates(ctx)
+	if err != nil {
+		s.logger.Error("Failed to get latest rates", zap.Error(err))
+		return nil, databaseError(err, "failed to get latest rates")
+	}
+
+	resp := &pb.AllLatestResp{Rates: make([]*pb.HistoricalRate, 0, len(records))}
+	for _, record := range records {
+		if s.checkPairAllowed(record.TradingPair) != nil {
+			continue
+		}
+		resp.Rates = append(resp.Rates, &pb.HistoricalRate{
+			TradingPair: record.TradingPair,
+			AskPrice:    record.AskPrice,
+			BidPrice:    record.BidPrice,
+			Timestamp:   timestamppb.New(record.Timestamp),
+		})
+	}
+	return resp, nil
+}
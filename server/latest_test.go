@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+var latestRateColumns = []string{"id", "trading_pair", "ask_price", "bid_price", "timestamp", "created_at", "vwap"}
+
+func TestGetAllLatest(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	client := newTestClient(t, srv)
+
+	now := time.Date(2025, 7, 28, 18, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT DISTINCT ON \(trading_pair\) id, trading_pair, ask_price, bid_price, timestamp, created_at, vwap FROM rates`).
+		WillReturnRows(sqlmock.NewRows(latestRateColumns).
+			AddRow(4, "BTC/RUB", 9000000.0, 8990000.0, now.Add(-time.Minute), now, nil).
+			AddRow(7, "ETH/RUB", 300000.0, 299000.0, now, now, nil).
+			AddRow(9, "USDT/RUB", 81.25, 81.20, now, now, 81.22))
+
+	resp, err := client.GetAllLatest(context.Background(), &pb.AllLatestReq{})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Rates, 3)
+	assert.Equal(t, "BTC/RUB", resp.Rates[0].TradingPair)
+	assert.Equal(t, 9000000.0, resp.Rates[0].AskPrice)
+	assert.Equal(t, now.Add(-time.Minute), resp.Rates[0].Timestamp.AsTime())
+	assert.Equal(t, "ETH/RUB", resp.Rates[1].TradingPair)
+	assert.Equal(t, "USDT/RUB", resp.Rates[2].TradingPair)
+	assert.Equal(t, 81.25, resp.Rates[2].AskPrice)
+	assert.Equal(t, 81.20, resp.Rates[2].BidPrice)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAllLatest_AllowedMarkets(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	srv.config.Server.AllowedMarkets = []string{"usdtrub", "btcrub"}
+	client := newTestClient(t, srv)
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT DISTINCT ON").
+		WillReturnRows(sqlmock.NewRows(latestRateColumns).
+			AddRow(4, "BTC/RUB", 9000000.0, 8990000.0, now, now, nil).
+			AddRow(7, "ETH/RUB", 300000.0, 299000.0, now, now, nil).
+			AddRow(9, "USDT/RUB", 81.25, 81.20, now, now, nil))
+
+	resp, err := client.GetAllLatest(context.Background(), &pb.AllLatestReq{})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Rates, 2)
+	assert.Equal(t, "BTC/RUB", resp.Rates[0].TradingPair)
+	assert.Equal(t, "USDT/RUB", resp.Rates[1].TradingPair)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAllLatest_Empty(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	client := newTestClient(t, srv)
+
+	mock.ExpectQuery("SELECT DISTINCT ON").WillReturnRows(sqlmock.NewRows(latestRateColumns))
+
+	resp, err := client.GetAllLatest(context.Background(), &pb.AllLatestReq{})
+
+	require.NoError(t, err)
+	assert.Empty(t, resp.Rates)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAllLatest_DatabaseError(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	client := newTestClient(t, srv)
+
+	mock.ExpectQuery("SELECT DISTINCT ON").WillReturnError(errors.New("connection refused"))
+
+	_, err := client.GetAllLatest(context.Background(), &pb.AllLatestReq{})
+
+	require.Error(t, err)
+	assert.Equal(t, codes.Unknown, status.Code(err))
+	assert.Contains(t, err.Error(), "failed to get latest rates")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"sync"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// adminTokenKey is the metadata key carrying the ADMIN_TOKEN for admin RPCs
+	adminTokenKey = "x-admin-token"
+	// defaultMaintenanceMessage is returned when maintenance is enabled without a message
+	defaultMaintenanceMessage = "service is under maintenance"
+)
+
+// adminMethods keep working during maintenance so it can be turned off again
+var adminMethods = map[string]bool{
+	pb.RateService_SetMaintenance_FullMethodName: true,
+	pb.RateService_PollNow_FullMethodName:        true,
+	pb.RateService_PausePoller_FullMethodName:    true,
+	pb.RateService_ResumePoller_FullMethodName:   true,
+}
+
+// Maintenance holds the maintenance mode state shared by the server and its interceptors.
+// The zero value has maintenance off.
+type Maintenance struct {
+	mu      sync.RWMutex
+	enabled bool
+	message string
+}
+
+// Set turns maintenance on or off. An empty message falls back to a generic one.
+func (m *Maintenance) Set(enabled bool, message string) {
+	if message == "" {
+		message = defaultMaintenanceMessage
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.enabled = enabled
+	m.message = message
+}
+
+// State reports whether maintenance is on and the message returned to clients
+func (m *Maintenance) State() (bool, string) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.enabled, m.message
+}
+
+// MaintenanceUnaryInterceptor rejects unary data RPCs with Unavailable while maintenance is on
+func MaintenanceUnaryInterceptor(m *Maintenance) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := checkMaintenance(m, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// MaintenanceStreamInterceptor rejects streaming data RPCs with Unavailable while maintenance is on
+func MaintenanceStreamInterceptor(m *Maintenance) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := checkMaintenance(m, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func checkMaintenance(m *Maintenance, method string) error {
+	if healthMethods[method] || adminMethods[method] {
+		return nil
+	}
+
+	if enabled, message := m.State(); enabled {
+		return status.Error(codes.Unavailable, message)
+	}
+	return nil
+}
+
+// SetMaintenance turns maintenance mode on or off. It requires the ADMIN_TOKEN in the
+// x-admin-token metadata and is disabled when no admin token is configured.
+func (s *RateServiceServer) SetMaintenance(ctx context.Context, req *pb.SetMaintenanceReq) (*pb.SetMaintenanceResp, error) {
+	ctx, span := otel.Tracer("grinex-rate-service").Start(ctx, "SetMaintenance")
+	defer span.End()
+
+	s.logger.Info("SetMaintenance called", zap.Bool("enabled", req.GetEnabled()))
+
+	if err := s.checkAdminToken(ctx); err != nil {
+		return nil, err
+	}
+
+	s.maintenance.Set(req.GetEnabled(), req.GetMessage())
+	enabled, message := s.maintenance.State()
+
+	s.logger.Warn("Maintenance mode changed", zap.Bool("enabled", enabled), zap.String("message", message))
+
+	return &pb.SetMaintenanceResp{
+		Enabled: enabled,
+		Message: message,
+	}, nil
+}
+
+// checkAdminToken verifies the admin token metadata of an admin RPC
+func (s *RateServiceServer) checkAdminToken(ctx context.Context) error {
+	if s.config.Server.AdminToken == "" {
+		return status.Error(codes.PermissionDenied, "admin RPCs are disabled, set ADMIN_TOKEN to enable them")
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(adminTokenKey)
+	if len(values) == 0 || subtle.ConstantTimeCompare([]byte(values[0]), []byte(s.config.Server.AdminToken)) != 1 {
+		return status.Error(codes.Unauthenticated, "invalid admin token")
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+func newMaintenanceTestClient(t *testing.T, srv *RateServiceServer) pb.RateServiceClient {
+	return newTestClient(t, srv,
+		grpc.UnaryInterceptor(MaintenanceUnaryInterceptor(srv.maintenance)),
+		grpc.StreamInterceptor(MaintenanceStreamInterceptor(srv.maintenance)),
+	)
+}
+
+func TestMaintenance_Toggle(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	srv.config.Server.AdminToken = "s3cret"
+	client := newMaintenanceTestClient(t, srv)
+	admin := metadata.AppendToOutgoingContext(context.Background(), "x-admin-token", "s3cret")
+
+	resp, err := client.SetMaintenance(admin, &pb.SetMaintenanceReq{Enabled: true, Message: "database migration until 03:00 UTC"})
+	require.NoError(t, err)
+	assert.True(t, resp.Enabled)
+
+	// Data RPCs are rejected with the message
+	_, err = client.GetRates(context.Background(), &pb.GetRatesReq{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, "database migration until 03:00 UTC", status.Convert(err).Message())
+
+	stream, err := client.ReplayRates(context.Background(), &pb.ReplayReq{
+		TradingPair: "USDT/RUB",
+		Start:       timestamppb.New(time.Now().Add(-time.Hour)),
+		End:         timestamppb.Now(),
+	})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	// Health keeps working
+	health, err := client.Healthcheck(context.Background(), &pb.HealthcheckReq{})
+	require.NoError(t, err)
+	assert.Equal(t, "healthy", health.Status)
+
+	resp, err = client.SetMaintenance(admin, &pb.SetMaintenanceReq{Enabled: false})
+	require.NoError(t, err)
+	assert.False(t, resp.Enabled)
+
+	mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	_, err = client.GetRates(context.Background(), &pb.GetRatesReq{})
+	assert.NoError(t, err)
+}
+
+func TestMaintenance_DefaultMessage(t *testing.T) {
+	var m Maintenance
+
+	m.Set(true, "")
+
+	enabled, message := m.State()
+	assert.True(t, enabled)
+	assert.Equal(t, "service is under maintenance", message)
+}
+
+func TestSetMaintenance_AdminToken(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	client := newMaintenanceTestClient(t, srv)
+
+	// Disabled without a configured admin token
+	_, err := client.SetMaintenance(context.Background(), &pb.SetMaintenanceReq{Enabled: true})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	srv.config.Server.AdminToken = "s3cret"
+
+	_, err = client.SetMaintenance(context.Background(), &pb.SetMaintenanceReq{Enabled: true})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	wrong := metadata.AppendToOutgoingContext(context.Background(), "x-admin-token", "guess")
+	_, err = client.SetMaintenance(wrong, &pb.SetMaintenanceReq{Enabled: true})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	enabled, _ := srv.maintenance.State()
+	assert.False(t, enabled)
+}
@@ -0,0 +1,202 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"time"
+
+	promclient "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/prometheus"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.uber.org/zap"
+
+	"github.com/atadzan/grinex-rate-service/internal/config"
+)
+
+// unknownPairLabel labels GetRates calls rejected before their trading pair was resolved
+const unknownPairLabel = "unknown"
+
+// newMetricReader creates the reader for the configured metrics backend: a Prometheus exporter
+// collected on scrape, or an OTLP gRPC exporter pushed every export interval
+func newMetricReader(ctx context.Context, cfg config.MetricsConfig) (metric.Reader, error) {
+	switch cfg.Backend {
+	case config.MetricsBackendPrometheus, "":
+		exporter, err := prometheus.New()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create prometheus exporter: %w", err)
+		}
+		return exporter, nil
+
+	case config.MetricsBackendOTLP:
+		opts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(cfg.OTLPEndpoint)}
+		if cfg.OTLPInsecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		}
+		exporter, err := otlpmetricgrpc.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP metric exporter: %w", err)
+		}
+
+		var readerOpts []metric.PeriodicReaderOption
+		if cfg.ExportInterval > 0 {
+			readerOpts = append(readerOpts, metric.WithInterval(cfg.ExportInterval))
+		}
+		return metric.NewPeriodicReader(exporter, readerOpts...), nil
+
+	default:
+		return nil, fmt.Errorf("unknown metrics backend: %s", cfg.Backend)
+	}
+}
+
+// dropLabelsOptions returns the meter provider options removing the given labels from every
+// metric, e.g. trading_pair when serving so many pairs that per-pair series would blow up the
+// cardinality. Series differing only in dropped labels are aggregated together.
+func dropLabelsOptions(labels []string) []metric.Option {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	keys := make([]attribute.Key, len(labels))
+	for i, label := range labels {
+		keys[i] = attribute.Key(label)
+	}
+	return []metric.Option{metric.WithView(metric.NewView(
+		metric.Instrument{Name: "*"},
+		metric.Stream{AttributeFilter: attribute.NewDenyKeysFilter(keys...)},
+	))}
+}
+
+// RegisterRuntimeMetrics registers asynchronous gauges reporting goroutine, heap and GC statistics.
+// Values are sampled on every collection, i.e. on each Prometheus scrape or OTLP export.
+func RegisterRuntimeMetrics(meter otelmetric.Meter) error {
+	goroutines, err := meter.Int64ObservableGauge("runtime_goroutines",
+		otelmetric.WithDescription("Number of goroutines that currently exist"))
+	if err != nil {
+		return fmt.Errorf("failed to create goroutines gauge: %w", err)
+	}
+
+	heapAlloc, err := meter.Int64ObservableGauge("runtime_heap_alloc",
+		otelmetric.WithDescription("Bytes of allocated heap objects"),
+		otelmetric.WithUnit("By"))
+	if err != nil {
+		return fmt.Errorf("failed to create heap alloc gauge: %w", err)
+	}
+
+	gcCount, err := meter.Int64ObservableCounter("runtime_gc_count",
+		otelmetric.WithDescription("Number of completed GC cycles"))
+	if err != nil {
+		return fmt.Errorf("failed to create GC count counter: %w", err)
+	}
+
+	gcPauseTotal, err := meter.Float64ObservableCounter("runtime_gc_pause_total",
+		otelmetric.WithDescription("Cumulative time spent in GC stop-the-world pauses"),
+		otelmetric.WithUnit("s"))
+	if err != nil {
+		return fmt.Errorf("failed to create GC pause total counter: %w", err)
+	}
+
+	gcLastPause, err := meter.Float64ObservableGauge("runtime_gc_last_pause",
+		otelmetric.WithDescription("Duration of the most recent GC stop-the-world pause"),
+		otelmetric.WithUnit("s"))
+	if err != nil {
+		return fmt.Errorf("failed to create GC last pause gauge: %w", err)
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o otelmetric.Observer) error {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+
+		o.ObserveInt64(goroutines, int64(runtime.NumGoroutine()))
+		o.ObserveInt64(heapAlloc, int64(stats.HeapAlloc))
+		o.ObserveInt64(gcCount, int64(stats.NumGC))
+		o.ObserveFloat64(gcPauseTotal, time.Duration(stats.PauseTotalNs).Seconds())
+
+		var lastPause time.Duration
+		if stats.NumGC > 0 {
+			lastPause = time.Duration(stats.PauseNs[(stats.NumGC+255)%256])
+		}
+		o.ObserveFloat64(gcLastPause, lastPause.Seconds())
+		return nil
+	}, goroutines, heapAlloc, gcCount, gcPauseTotal, gcLastPause)
+	if err != nil {
+		return fmt.Errorf("failed to register runtime metrics callback: %w", err)
+	}
+
+	return nil
+}
+
+// newRatesRequestsCounter creates the counter of GetRates calls by trading pair and outcome
+func newRatesRequestsCounter(meter otelmetric.Meter) (otelmetric.Int64Counter, error) {
+	return meter.Int64Counter("grinex_rate_requests",
+		otelmetric.WithDescription("GetRates calls by trading pair and outcome, success or error"))
+}
+
+// newHealthScoreGauge creates the gauge of the weighted health score reported by Healthcheck
+func newHealthScoreGauge(meter otelmetric.Meter) (otelmetric.Float64Gauge, error) {
+	return meter.Float64Gauge("grinex_health_score",
+		otelmetric.WithDescription("Weighted health score from 0 to 100 of the latest Healthcheck"))
+}
+
+// newPersistFailuresCounter creates the counter of live rates that failed to be saved by trading pair
+func newPersistFailuresCounter(meter otelmetric.Meter) (otelmetric.Int64Counter, error) {
+	return meter.Int64Counter("grinex_persist_failures",
+		otelmetric.WithDescription("Live rates that failed to be saved to the database by trading pair"))
+}
+
+// recordPersistFailure counts a failed save of a rate of pair
+func (s *RateServiceServer) recordPersistFailure(pair string) {
+	if s.persistFailures == nil {
+		return
+	}
+	s.persistFailures.Add(context.Background(), 1, otelmetric.WithAttributes(attribute.String("trading_pair", pair)))
+}
+
+// recordRatesRequest counts a GetRates call that returned err
+func (s *RateServiceServer) recordRatesRequest(ctx context.Context, pair string, err error) {
+	if s.ratesRequests == nil {
+		return
+	}
+
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	s.ratesRequests.Add(ctx, 1, otelmetric.WithAttributes(
+		attribute.String("trading_pair", pair),
+		attribute.String("outcome", outcome),
+	))
+}
+
+// newMetricsServer returns an HTTP server exposing the metrics gathered by gatherer on /metrics
+func newMetricsServer(addr string, gatherer promclient.Gatherer) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+	return &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+}
+
+// runMetricsServer serves /metrics on port until ctx is cancelled, then shuts the server down
+// within shutdownTimeout
+func runMetricsServer(ctx context.Context, port string, shutdownTimeout time.Duration, logger *zap.Logger) {
+	srv := newMetricsServer(":"+port, promclient.DefaultGatherer)
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			logger.Warn("Failed to shut down metrics server", zap.Error(err))
+		}
+	}()
+
+	logger.Info("Metrics server listening", zap.String("port", srv.Addr))
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("Metrics server error", zap.Error(err))
+	}
+}
@@ -0,0 +1,327 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	promclient "github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	collectormetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/atadzan/grinex-rate-service/internal/config"
+	"github.com/atadzan/grinex-rate-service/internal/service"
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+func TestRegisterRuntimeMetrics(t *testing.T) {
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+	defer provider.Shutdown(context.Background())
+
+	require.NoError(t, RegisterRuntimeMetrics(provider.Meter("test")))
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+
+	values := make(map[string]float64)
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		switch data := m.Data.(type) {
+		case metricdata.Gauge[int64]:
+			values[m.Name] = float64(data.DataPoints[0].Value)
+		case metricdata.Gauge[float64]:
+			values[m.Name] = data.DataPoints[0].Value
+		case metricdata.Sum[int64]:
+			values[m.Name] = float64(data.DataPoints[0].Value)
+		case metricdata.Sum[float64]:
+			values[m.Name] = data.DataPoints[0].Value
+		}
+	}
+
+	for _, name := range []string{"runtime_goroutines", "runtime_heap_alloc", "runtime_gc_count", "runtime_gc_pause_total", "runtime_gc_last_pause"} {
+		value, ok := values[name]
+		require.True(t, ok, "missing metric %s", name)
+		assert.GreaterOrEqual(t, value, 0.0, name)
+	}
+	assert.Greater(t, values["runtime_goroutines"], 0.0)
+	assert.Greater(t, values["runtime_heap_alloc"], 0.0)
+}
+
+// fakeCollector is an OTLP metrics collector recording the names of the metrics it receives
+type fakeCollector struct {
+	collectormetricspb.UnimplementedMetricsServiceServer
+
+	mu      sync.Mutex
+	metrics map[string]int
+}
+
+func (c *fakeCollector) Export(_ context.Context, req *collectormetricspb.ExportMetricsServiceRequest) (*collectormetricspb.ExportMetricsServiceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, rm := range req.GetResourceMetrics() {
+		for _, sm := range rm.GetScopeMetrics() {
+			for _, m := range sm.GetMetrics() {
+				c.metrics[m.GetName()]++
+			}
+		}
+	}
+	return &collectormetricspb.ExportMetricsServiceResponse{}, nil
+}
+
+func (c *fakeCollector) received(name string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.metrics[name]
+}
+
+// newFakeCollector serves a fake OTLP collector on a local port and returns it with its endpoint
+func newFakeCollector(t *testing.T) (*fakeCollector, string) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	collector := &fakeCollector{metrics: make(map[string]int)}
+	s := grpc.NewServer()
+	collectormetricspb.RegisterMetricsServiceServer(s, collector)
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+
+	return collector, lis.Addr().String()
+}
+
+func TestOTLPMetrics_PeriodicExport(t *testing.T) {
+	collector, endpoint := newFakeCollector(t)
+
+	reader, err := newMetricReader(context.Background(), config.MetricsConfig{
+		Backend:        config.MetricsBackendOTLP,
+		OTLPEndpoint:   endpoint,
+		OTLPInsecure:   true,
+		ExportInterval: 50 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+	defer provider.Shutdown(context.Background())
+
+	require.NoError(t, RegisterRuntimeMetrics(provider.Meter("test")))
+
+	assert.Eventually(t, func() bool {
+		return collector.received("runtime_goroutines") > 0
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+func TestOTLPMetrics_ShutdownFlushes(t *testing.T) {
+	collector, endpoint := newFakeCollector(t)
+
+	// The interval is far longer than the test, so only the shutdown flush exports
+	reader, err := newMetricReader(context.Background(), config.MetricsConfig{
+		Backend:        config.MetricsBackendOTLP,
+		OTLPEndpoint:   endpoint,
+		OTLPInsecure:   true,
+		ExportInterval: time.Hour,
+	})
+	require.NoError(t, err)
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+
+	counter, err := provider.Meter("test").Int64Counter("grinex_test_requests")
+	require.NoError(t, err)
+	counter.Add(context.Background(), 1)
+
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	assert.Equal(t, 1, collector.received("grinex_test_requests"))
+}
+
+func TestSetupMetrics_UnknownBackend(t *testing.T) {
+	_, err := SetupMetrics(config.MetricsConfig{Backend: "statsd"})
+
+	assert.ErrorContains(t, err, "unknown metrics backend")
+}
+
+func TestGetRates_RecordsRequests(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+	defer provider.Shutdown(context.Background())
+	counter, err := newRatesRequestsCounter(provider.Meter("test"))
+	require.NoError(t, err)
+	srv.ratesRequests = counter
+	client := newTestClient(t, srv)
+
+	mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	_, err = client.GetRates(context.Background(), &pb.GetRatesReq{})
+	require.NoError(t, err)
+	_, err = client.GetRates(context.Background(), &pb.GetRatesReq{TradingPair: "DOGE/RUB"})
+	require.Error(t, err)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	assert.Equal(t, "grinex_rate_requests", rm.ScopeMetrics[0].Metrics[0].Name)
+
+	counts := make(map[string]int64)
+	for _, point := range rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64]).DataPoints {
+		pair, _ := point.Attributes.Value("trading_pair")
+		outcome, _ := point.Attributes.Value("outcome")
+		counts[pair.AsString()+"/"+outcome.AsString()] = point.Value
+	}
+	assert.Equal(t, map[string]int64{"USDT/RUB/success": 1, unknownPairLabel + "/error": 1}, counts)
+}
+
+func TestGetRates_RecordsPersistFailures(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+	defer provider.Shutdown(context.Background())
+	counter, err := newPersistFailuresCounter(provider.Meter("test"))
+	require.NoError(t, err)
+	srv.persistFailures = counter
+	client := newTestClient(t, srv)
+
+	mock.ExpectQuery("INSERT INTO rates").WillReturnError(sql.ErrConnDone)
+
+	_, err = client.GetRates(context.Background(), &pb.GetRatesReq{})
+	require.NoError(t, err)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	assert.Equal(t, "grinex_persist_failures", rm.ScopeMetrics[0].Metrics[0].Name)
+
+	points := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64]).DataPoints
+	require.Len(t, points, 1)
+	assert.Equal(t, int64(1), points[0].Value)
+	pair, _ := points[0].Attributes.Value("trading_pair")
+	assert.Equal(t, "USDT/RUB", pair.AsString())
+}
+
+func TestDropLabelsOptions(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	reader := metric.NewManualReader()
+	opts := append([]metric.Option{metric.WithReader(reader)}, dropLabelsOptions([]string{"trading_pair", "path"})...)
+	provider := metric.NewMeterProvider(opts...)
+	defer provider.Shutdown(context.Background())
+	counter, err := newRatesRequestsCounter(provider.Meter("test"))
+	require.NoError(t, err)
+	srv.ratesRequests = counter
+	grinex := httptest.NewServer(http.HandlerFunc(tradesHandler))
+	t.Cleanup(grinex.Close)
+	srv.grinexSvc = service.NewGrinexService(&service.GrinexConfig{
+		BaseURL: grinex.URL,
+		Timeout: 5 * time.Second,
+		Meter:   provider.Meter("test"),
+	}, zap.NewNop())
+	client := newTestClient(t, srv)
+
+	mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+
+	_, err = client.GetRates(context.Background(), &pb.GetRatesReq{})
+	require.NoError(t, err)
+	_, err = client.GetRates(context.Background(), &pb.GetRatesReq{TradingPair: "BTC/RUB"})
+	require.NoError(t, err)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+
+	var sawRequests, sawDuration bool
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		switch m.Name {
+		case "grinex_rate_requests":
+			sawRequests = true
+			points := m.Data.(metricdata.Sum[int64]).DataPoints
+			require.Len(t, points, 1, "both pairs are counted in one series")
+			assert.Equal(t, int64(2), points[0].Value)
+			assert.False(t, points[0].Attributes.HasValue("trading_pair"))
+			outcome, _ := points[0].Attributes.Value("outcome")
+			assert.Equal(t, "success", outcome.AsString())
+		case "grinex_request_duration":
+			sawDuration = true
+			for _, point := range m.Data.(metricdata.Histogram[float64]).DataPoints {
+				assert.False(t, point.Attributes.HasValue("path"))
+				assert.True(t, point.Attributes.HasValue("error"))
+			}
+		}
+	}
+	assert.True(t, sawRequests)
+	assert.True(t, sawDuration)
+}
+
+func TestDropLabelsOptions_None(t *testing.T) {
+	assert.Empty(t, dropLabelsOptions(nil))
+}
+
+func TestMetricsServer(t *testing.T) {
+	registry := promclient.NewRegistry()
+	exporter, err := prometheus.New(prometheus.WithRegisterer(registry))
+	require.NoError(t, err)
+	provider := metric.NewMeterProvider(metric.WithReader(exporter))
+	defer provider.Shutdown(context.Background())
+	meter := provider.Meter("test")
+
+	srv, mock := newTestServer(t, tradesHandler)
+	srv.ratesRequests, err = newRatesRequestsCounter(meter)
+	require.NoError(t, err)
+	grinex := httptest.NewServer(http.HandlerFunc(tradesHandler))
+	defer grinex.Close()
+	srv.grinexSvc = service.NewGrinexService(&service.GrinexConfig{
+		BaseURL: grinex.URL,
+		Timeout: 5 * time.Second,
+		Meter:   meter,
+	}, zap.NewNop())
+	client := newTestClient(t, srv)
+
+	mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	_, err = client.GetRates(context.Background(), &pb.GetRatesReq{})
+	require.NoError(t, err)
+
+	metricsServer := httptest.NewServer(newMetricsServer("", registry).Handler)
+	defer metricsServer.Close()
+
+	resp, err := http.Get(metricsServer.URL + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), `grinex_rate_requests_total{otel_scope_name="test",otel_scope_version="",outcome="success",trading_pair="USDT/RUB"} 1`)
+	assert.Contains(t, string(body), "grinex_request_duration_seconds_bucket")
+}
+
+func TestRunMetricsServer_StopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		runMetricsServer(ctx, "0", time.Second, zap.NewNop())
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("metrics server did not stop after cancellation")
+	}
+}
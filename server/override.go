@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/atadzan/grinex-rate-service/internal/service"
+)
+
+// baseURLOverrideKey is the metadata key carrying a per-request Grinex base URL
+const baseURLOverrideKey = "x-grinex-base-url"
+
+// grinexServiceFor returns the Grinex client for a request, pointing it at the base URL from
+// the x-grinex-base-url metadata when present and allowed by GRINEX_BASE_URL_OVERRIDE_HOSTS.
+// Overriding the base URL requires the ADMIN_TOKEN in the x-admin-token metadata.
+func (s *RateServiceServer) grinexServiceFor(ctx context.Context) (*service.GrinexService, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(baseURLOverrideKey)
+	if len(values) == 0 || values[0] == "" {
+		return s.grinexSvc, nil
+	}
+	if err := s.checkAdminToken(ctx); err != nil {
+		return nil, err
+	}
+
+	baseURL, err := parseBaseURLOverride(values[0])
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid base URL override: %v", err)
+	}
+	if !hostAllowed(s.config.Grinex.BaseURLOverrideHosts, baseURL.Hostname()) {
+		return nil, status.Errorf(codes.PermissionDenied, "base URL override to host %q is not allowed", baseURL.Hostname())
+	}
+
+	s.logger.Debug("Using Grinex base URL override")
+	return s.grinexSvc.WithBaseURL(baseURL.String()), nil
+}
+
+// parseBaseURLOverride validates that raw is an absolute http(s) URL without query or credentials
+func parseBaseURLOverride(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, errors.New("missing host")
+	}
+	if u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return nil, errors.New("credentials, query and fragment are not allowed")
+	}
+	return u, nil
+}
+
+// hostAllowed reports whether host is listed in allowed, an empty list allowing nothing
+func hostAllowed(allowed []string, host string) bool {
+	for _, h := range allowed {
+		if strings.EqualFold(strings.TrimSpace(h), host) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/atadzan/grinex-rate-service/internal/service"
+)
+
+// PollNow fetches the rate of a pair from Grinex right away, bypassing a still fresh cached
+// rate, stores it and returns it. A fetch of the pair already in progress, e.g. for GetRates,
+// is joined rather than repeated. It requires the ADMIN_TOKEN in the x-admin-token metadata.
+func (s *RateServiceServer) PollNow(ctx context.Context, req *pb.PollNowReq) (*pb.PollNowResp, error) {
+	ctx, span := otel.Tracer("grinex-rate-service").Start(ctx, "PollNow")
+	defer span.End()
+
+	s.logger.Info("PollNow called", zap.String("trading_pair", req.GetTradingPair()))
+
+	if err := s.checkAdminToken(ctx); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.GetTradingPair()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "trading_pair is required")
+	}
+	if _, ok := pb.RateSource_name[int32(req.GetSource())]; !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown source: %d", req.GetSource())
+	}
+	market, err := s.grinexSvc.ResolvePair(req.GetTradingPair())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid trading_pair: %v", err)
+	}
+	if err := s.checkMarketAllowed(market); err != nil {
+		return nil, err
+	}
+	if s.dbOnly() {
+		return nil, status.Error(codes.FailedPrecondition, "polling needs Grinex, which is not called in db_only serve mode")
+	}
+
+	rate, fetched, err := s.refreshRate(ctx, market, req.GetSource())
+	if err != nil {
+		s.logger.Error("Failed to poll rate from Grinex", zap.String("market", market), zap.Error(err))
+		return nil, status.Errorf(codes.Unavailable, "failed to get rate from Grinex: %v", err)
+	}
+
+	if fetched {
+		if err := s.saveRate(rate); err != nil {
+			s.logger.Error("Failed to save polled rate to database", zap.Error(err))
+			return nil, databaseError(err, "failed to save rate")
+		}
+	}
+
+	s.logger.Info("Polled rate", zap.String("market", market), zap.Bool("fetched", fetched))
+
+	return &pb.PollNowResp{
+		Rate:    rate.ToProto(),
+		Fetched: fetched,
+	}, nil
+}
+
+// pollMarket is the PollFunc of the background poller: it refreshes the rate of market from
+// GRINEX_RATE_SOURCE through the rate cache, so it shares a fetch with PollNow and GetRates,
+// and stores the rate when this poll owns the fetch
+func (s *RateServiceServer) pollMarket(ctx context.Context, market string) (*service.Rate, error) {
+	rate, fetched, err := s.refreshRate(ctx, market, pb.RateSource_RATE_SOURCE_UNSPECIFIED)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rate from Grinex: %w", err)
+	}
+	if !fetched {
+		return rate, nil
+	}
+	if err := s.saveRate(rate); err != nil {
+		return nil, err
+	}
+	return rate, nil
+}
+
+// refreshRate fetches the rate of market from Grinex even when a fresh one is cached, joining a
+// fetch of the same market and source already in progress
+func (s *RateServiceServer) refreshRate(ctx context.Context, market string, source pb.RateSource) (*service.Rate, bool, error) {
+	return s.rateCache.Refresh(ctx, s.rateCacheKey(market, source), func(ctx context.Context) (*service.Rate, error) {
+		return s.liveRate(ctx, s.grinexSvc, market, source)
+	})
+}
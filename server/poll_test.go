@@ -0,0 +1,193 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/atadzan/grinex-rate-service/internal/config"
+	"github.com/atadzan/grinex-rate-service/internal/service"
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+func adminContext() context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), adminTokenKey, "s3cret")
+}
+
+func TestPollNow(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	srv.config.Server.AdminToken = "s3cret"
+	client := newTestClient(t, srv)
+
+	mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	resp, err := client.PollNow(adminContext(), &pb.PollNowReq{TradingPair: "USDT/RUB"})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Fetched)
+	assert.Equal(t, "USDT/RUB", resp.Rate.TradingPair)
+	assert.Equal(t, 81.25, resp.Rate.AskPrice)
+	assert.Equal(t, 81.20, resp.Rate.BidPrice)
+	assert.NotNil(t, resp.Rate.IngestedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPollNow_BypassesCache(t *testing.T) {
+	var requests atomic.Int32
+	srv, mock := newTestServer(t, sequenceTradesHandler(&requests, "81.20", "81.30"))
+	srv.config.Server.AdminToken = "s3cret"
+	srv.rateCache = service.NewRateCache(time.Minute, 0)
+	client := newTestClient(t, srv)
+
+	mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+
+	resp, err := client.GetRates(context.Background(), &pb.GetRatesReq{})
+	require.NoError(t, err)
+	assert.Equal(t, 81.20, resp.AskPrice)
+
+	polled, err := client.PollNow(adminContext(), &pb.PollNowReq{TradingPair: "usdtrub"})
+	require.NoError(t, err)
+	assert.True(t, polled.Fetched)
+	assert.Equal(t, 81.30, polled.Rate.AskPrice)
+
+	// GetRates serves the polled rate from the cache
+	resp, err = client.GetRates(context.Background(), &pb.GetRatesReq{})
+	require.NoError(t, err)
+	assert.Equal(t, 81.30, resp.AskPrice)
+
+	assert.Equal(t, int32(2), requests.Load())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPollNow_JoinsInflightFetch(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	srv, mock := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		tradesHandler(w, r)
+	})
+	srv.config.Server.AdminToken = "s3cret"
+	srv.rateCache = service.NewRateCache(time.Minute, 0)
+	client := newTestClient(t, srv)
+
+	// Only the GetRates that fetched the rate stores it
+	mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := client.GetRates(context.Background(), &pb.GetRatesReq{})
+		assert.NoError(t, err)
+	}()
+	require.Eventually(t, func() bool { return requests.Load() == 1 }, time.Second, time.Millisecond)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+	resp, err := client.PollNow(adminContext(), &pb.PollNowReq{TradingPair: "USDT/RUB"})
+	<-done
+
+	require.NoError(t, err)
+	assert.False(t, resp.Fetched)
+	assert.Equal(t, 81.25, resp.Rate.AskPrice)
+	assert.Equal(t, int32(1), requests.Load())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPollMarket_SharesRateCache(t *testing.T) {
+	var requests atomic.Int32
+	srv, mock := newTestServer(t, sequenceTradesHandler(&requests, "81.20", "81.30"))
+	srv.rateCache = service.NewRateCache(time.Minute, 0)
+	client := newTestClient(t, srv)
+
+	mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	rate, err := srv.pollMarket(context.Background(), "usdtrub")
+	require.NoError(t, err)
+	assert.Equal(t, 81.20, rate.AskPrice)
+
+	// GetRates serves the polled rate from the cache without fetching or storing it again
+	resp, err := client.GetRates(context.Background(), &pb.GetRatesReq{})
+	require.NoError(t, err)
+	assert.Equal(t, 81.20, resp.AskPrice)
+	assert.Equal(t, dataSourceCache, resp.Source)
+
+	assert.Equal(t, int32(1), requests.Load())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPollNow_DuringMaintenance(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	srv.config.Server.AdminToken = "s3cret"
+	srv.maintenance.Set(true, "")
+	client := newMaintenanceTestClient(t, srv)
+
+	mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	resp, err := client.PollNow(adminContext(), &pb.PollNowReq{TradingPair: "USDT/RUB"})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Fetched)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPollNow_AdminToken(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	client := newTestClient(t, srv)
+
+	_, err := client.PollNow(adminContext(), &pb.PollNowReq{TradingPair: "USDT/RUB"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	srv.config.Server.AdminToken = "other"
+	_, err = client.PollNow(adminContext(), &pb.PollNowReq{TradingPair: "USDT/RUB"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestPollNow_InvalidArgument(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	srv.config.Server.AdminToken = "s3cret"
+	client := newTestClient(t, srv)
+
+	for _, req := range []*pb.PollNowReq{
+		{},
+		{TradingPair: "DOGE/USD"},
+		{TradingPair: "USDT/RUB", Source: pb.RateSource(42)},
+	} {
+		_, err := client.PollNow(adminContext(), req)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "request %v", req)
+	}
+}
+
+func TestPollNow_GrinexFailure(t *testing.T) {
+	srv, mock := newTestServer(t, failingGrinexHandler)
+	srv.config.Server.AdminToken = "s3cret"
+	client := newTestClient(t, srv)
+
+	_, err := client.PollNow(adminContext(), &pb.PollNowReq{TradingPair: "USDT/RUB"})
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPollNow_DBOnly(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	srv.config.Server.AdminToken = "s3cret"
+	srv.config.Server.ServeMode = config.ServeModeDBOnly
+	client := newTestClient(t, srv)
+
+	_, err := client.PollNow(adminContext(), &pb.PollNowReq{TradingPair: "USDT/RUB"})
+
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
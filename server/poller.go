@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// States of the background poller reported by GetCapabilities
+const (
+	pollerStateDisabled = "disabled"
+	pollerStateRunning  = "running"
+	pollerStatePaused   = "paused"
+)
+
+// pollerMethods control the background poller and fail with FailedPrecondition when it is not running
+var pollerMethods = map[string]bool{
+	pb.RateService_PausePoller_FullMethodName:     true,
+	pb.RateService_ResumePoller_FullMethodName:    true,
+	pb.RateService_GetMarketStatus_FullMethodName: true,
+}
+
+// PausePoller stops the background poller from fetching rates until ResumePoller. A poll in
+// progress is finished first. It requires the ADMIN_TOKEN in the x-admin-token metadata.
+func (s *RateServiceServer) PausePoller(ctx context.Context, req *pb.PausePollerReq) (*pb.PausePollerResp, error) {
+	ctx, span := otel.Tracer("grinex-rate-service").Start(ctx, "PausePoller")
+	defer span.End()
+
+	s.logger.Info("PausePoller called")
+
+	if err := s.checkAdminToken(ctx); err != nil {
+		return nil, err
+	}
+	if s.poller == nil {
+		return nil, status.Error(codes.FailedPrecondition, "poller is not running, set POLL_ENABLED to enable it")
+	}
+
+	changed := s.poller.Pause()
+	s.logger.Info("Poller pause requested", zap.Bool("changed", changed))
+
+	return &pb.PausePollerResp{Changed: changed}, nil
+}
+
+// ResumePoller restarts the background poller after PausePoller: it polls right away and then
+// every POLL_INTERVAL again. It requires the ADMIN_TOKEN in the x-admin-token metadata.
+func (s *RateServiceServer) ResumePoller(ctx context.Context, req *pb.ResumePollerReq) (*pb.ResumePollerResp, error) {
+	ctx, span := otel.Tracer("grinex-rate-service").Start(ctx, "ResumePoller")
+	defer span.End()
+
+	s.logger.Info("ResumePoller called")
+
+	if err := s.checkAdminToken(ctx); err != nil {
+		return nil, err
+	}
+	if s.poller == nil {
+		return nil, status.Error(codes.FailedPrecondition, "poller is not running, set POLL_ENABLED to enable it")
+	}
+
+	changed := s.poller.Resume()
+	s.logger.Info("Poller resume requested", zap.Bool("changed", changed))
+
+	return &pb.ResumePollerResp{Changed: changed}, nil
+}
+
+// GetMarketStatus reports the state the background poller keeps for each polled market: when
+// it last got a rate and its price, how many polls failed since along with the backoff, and the
+// VWAP of the distinct trades polled so far.
+// It also reports whether the poller is paused by PausePoller.
+func (s *RateServiceServer) GetMarketStatus(ctx context.Context, req *pb.MarketStatusReq) (*pb.MarketStatusResp, error) {
+	_, span := otel.Tracer("grinex-rate-service").Start(ctx, "GetMarketStatus")
+	defer span.End()
+
+	s.logger.Info("GetMarketStatus called")
+
+	if s.poller == nil {
+		return nil, status.Error(codes.FailedPrecondition, "poller is not running, set POLL_ENABLED to enable it")
+	}
+
+	resp := &pb.MarketStatusResp{Paused: s.poller.Paused()}
+	for _, market := range s.poller.Status() {
+		entry := &pb.MarketStatus{
+			Market:              market.Market,
+			TradingPair:         s.grinexSvc.PairLabel(market.Market),
+			LastPrice:           market.LastPrice,
+			ConsecutiveFailures: int32(market.ConsecutiveFailures),
+			Backoff:             durationpb.New(market.Backoff),
+			LastError:           market.LastError,
+			TradesSeen:          int64(market.TradesSeen),
+			SessionVwap:         market.SessionVWAP,
+		}
+		if !market.LastFetch.IsZero() {
+			entry.LastFetch = timestamppb.New(market.LastFetch)
+		}
+		resp.Markets = append(resp.Markets, entry)
+	}
+
+	return resp, nil
+}
+
+// pollerState reports whether the background poller is disabled, running or paused
+func (s *RateServiceServer) pollerState() string {
+	switch {
+	case s.poller == nil:
+		return pollerStateDisabled
+	case s.poller.Paused():
+		return pollerStatePaused
+	default:
+		return pollerStateRunning
+	}
+}
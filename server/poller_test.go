@@ -0,0 +1,195 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/atadzan/grinex-rate-service/internal/poller"
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+func newServerPoller(t *testing.T, srv *RateServiceServer) *poller.Poller {
+	t.Helper()
+
+	p, err := poller.New(srv.grinexSvc, srv.pollMarket, poller.Config{
+		Interval: time.Hour,
+		Pairs:    []string{"usdtrub"},
+	}, zap.NewNop())
+	require.NoError(t, err)
+	return p
+}
+
+// runServerPoller runs the poller of srv until the test ends
+func runServerPoller(t *testing.T, srv *RateServiceServer) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		srv.poller.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestPauseAndResumePoller(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	srv.config.Server.AdminToken = "s3cret"
+	srv.poller = newServerPoller(t, srv)
+	client := newTestClient(t, srv)
+
+	paused, err := client.PausePoller(adminContext(), &pb.PausePollerReq{})
+	require.NoError(t, err)
+	assert.True(t, paused.Changed)
+	assert.True(t, srv.poller.Paused())
+
+	capabilities, err := client.GetCapabilities(context.Background(), &pb.CapabilitiesReq{})
+	require.NoError(t, err)
+	assert.Equal(t, "paused", capabilities.Poller)
+
+	marketStatus, err := client.GetMarketStatus(context.Background(), &pb.MarketStatusReq{})
+	require.NoError(t, err)
+	assert.True(t, marketStatus.Paused)
+	require.Len(t, marketStatus.Markets, 1)
+
+	paused, err = client.PausePoller(adminContext(), &pb.PausePollerReq{})
+	require.NoError(t, err)
+	assert.False(t, paused.Changed, "already paused")
+
+	resumed, err := client.ResumePoller(adminContext(), &pb.ResumePollerReq{})
+	require.NoError(t, err)
+	assert.True(t, resumed.Changed)
+	assert.False(t, srv.poller.Paused())
+
+	resumed, err = client.ResumePoller(adminContext(), &pb.ResumePollerReq{})
+	require.NoError(t, err)
+	assert.False(t, resumed.Changed, "not paused")
+
+	capabilities, err = client.GetCapabilities(context.Background(), &pb.CapabilitiesReq{})
+	require.NoError(t, err)
+	assert.Equal(t, "running", capabilities.Poller)
+
+	marketStatus, err = client.GetMarketStatus(context.Background(), &pb.MarketStatusReq{})
+	require.NoError(t, err)
+	assert.False(t, marketStatus.Paused)
+}
+
+func TestPausePoller_RequiresAdminToken(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	srv.config.Server.AdminToken = "s3cret"
+	srv.poller = newServerPoller(t, srv)
+	client := newTestClient(t, srv)
+
+	_, err := client.PausePoller(context.Background(), &pb.PausePollerReq{})
+
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.False(t, srv.poller.Paused())
+}
+
+func TestPausePoller_Disabled(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	srv.config.Server.AdminToken = "s3cret"
+	client := newTestClient(t, srv)
+
+	_, err := client.PausePoller(adminContext(), &pb.PausePollerReq{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = client.ResumePoller(adminContext(), &pb.ResumePollerReq{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	capabilities, err := client.GetCapabilities(context.Background(), &pb.CapabilitiesReq{})
+	require.NoError(t, err)
+	assert.Equal(t, "disabled", capabilities.Poller)
+	assert.NotContains(t, capabilities.Endpoints, "PausePoller")
+	assert.NotContains(t, capabilities.Endpoints, "ResumePoller")
+}
+
+func TestPausePoller_DuringMaintenance(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	srv.config.Server.AdminToken = "s3cret"
+	srv.poller = newServerPoller(t, srv)
+	srv.maintenance.Set(true, "")
+	client := newMaintenanceTestClient(t, srv)
+
+	paused, err := client.PausePoller(adminContext(), &pb.PausePollerReq{})
+	require.NoError(t, err)
+	assert.True(t, paused.Changed)
+
+	resumed, err := client.ResumePoller(adminContext(), &pb.ResumePollerReq{})
+	require.NoError(t, err)
+	assert.True(t, resumed.Changed)
+}
+
+func TestGetMarketStatus(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	srv.poller = newServerPoller(t, srv)
+	client := newTestClient(t, srv)
+
+	mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	resp, err := client.GetMarketStatus(context.Background(), &pb.MarketStatusReq{})
+	require.NoError(t, err)
+	require.Len(t, resp.Markets, 1)
+	assert.Equal(t, "usdtrub", resp.Markets[0].Market)
+	assert.Nil(t, resp.Markets[0].LastFetch, "not polled yet")
+
+	// The poller polls right away on start
+	runServerPoller(t, srv)
+
+	require.Eventually(t, func() bool {
+		resp, err = client.GetMarketStatus(context.Background(), &pb.MarketStatusReq{})
+		return err == nil && resp.Markets[0].LastFetch != nil
+	}, time.Second, 5*time.Millisecond)
+
+	market := resp.Markets[0]
+	assert.Equal(t, "USDT/RUB", market.TradingPair)
+	assert.InDelta(t, 81.225, market.LastPrice, 1e-9)
+	assert.Zero(t, market.ConsecutiveFailures)
+	assert.Zero(t, market.Backoff.AsDuration())
+	assert.Empty(t, market.LastError)
+	assert.Equal(t, int64(2), market.TradesSeen)
+	assert.InDelta(t, 81.225, market.SessionVwap, 1e-9)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetMarketStatus_Failures(t *testing.T) {
+	srv, _ := newTestServer(t, failingGrinexHandler)
+	srv.poller = newServerPoller(t, srv)
+	client := newTestClient(t, srv)
+
+	runServerPoller(t, srv)
+
+	var resp *pb.MarketStatusResp
+	require.Eventually(t, func() bool {
+		var err error
+		resp, err = client.GetMarketStatus(context.Background(), &pb.MarketStatusReq{})
+		return err == nil && resp.Markets[0].ConsecutiveFailures == 1
+	}, 5*time.Second, 5*time.Millisecond)
+
+	market := resp.Markets[0]
+	assert.Nil(t, market.LastFetch)
+	assert.Contains(t, market.LastError, "failed to get rate from Grinex")
+}
+
+func TestGetMarketStatus_Disabled(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	client := newTestClient(t, srv)
+
+	_, err := client.GetMarketStatus(context.Background(), &pb.MarketStatusReq{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	capabilities, err := client.GetCapabilities(context.Background(), &pb.CapabilitiesReq{})
+	require.NoError(t, err)
+	assert.NotContains(t, capabilities.Endpoints, "GetMarketStatus")
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+const (
+	// defaultPriceDecimals is the minor unit precision of markets without GRINEX_PRICE_DECIMALS, e.g. kopecks
+	defaultPriceDecimals = 2
+	// maxPriceDecimals matches the precision prices are stored with
+	maxPriceDecimals = 8
+	// maxFeeBps keeps fees below 100%, which would zero or negate the client bid
+	maxFeeBps = 9999
+)
+
+// applyFee fills the client prices of resp with the fee of market: the ask is raised and the bid
+// lowered by fee_bps basis points. The client bid never goes below zero.
+func (s *RateServiceServer) applyFee(resp *pb.GetRatesResp, market string) *pb.GetRatesResp {
+	bps := s.config.Grinex.FeeBps[market]
+	fee := float64(bps) / 10000
+
+	resp.ClientAsk = resp.AskPrice * (1 + fee)
+	resp.ClientBid = math.Max(resp.BidPrice*(1-fee), 0)
+	resp.FeeBps = int32(bps)
+	return resp
+}
+
+// formatPrices fills the integer minor unit prices of resp, at the precision of market, when the
+// client asked for them
+func (s *RateServiceServer) formatPrices(resp *pb.GetRatesResp, market string, format pb.PriceFormat) *pb.GetRatesResp {
+	if format != pb.PriceFormat_PRICE_FORMAT_MINOR_UNITS {
+		return resp
+	}
+
+	decimals := defaultPriceDecimals
+	if d, ok := s.config.Grinex.PriceDecimals[market]; ok {
+		decimals = d
+	}
+
+	resp.AskMinor = toMinorUnits(resp.AskPrice, decimals)
+	resp.BidMinor = toMinorUnits(resp.BidPrice, decimals)
+	resp.PriceDecimals = int32(decimals)
+	return resp
+}
+
+// toMinorUnits scales price by 10^decimals, rounding half away from zero. Rounding works on the
+// shortest decimal representation of price, so 81.255 becomes 8126 rather than 8125 even though
+// its binary value is slightly below 81.255.
+func toMinorUnits(price float64, decimals int) int64 {
+	if math.IsNaN(price) || math.IsInf(price, 0) {
+		return 0
+	}
+
+	digits := strconv.FormatFloat(math.Abs(price), 'f', -1, 64)
+	whole, fraction, _ := strings.Cut(digits, ".")
+	fraction += strings.Repeat("0", decimals+1)
+
+	minor, err := strconv.ParseInt(whole+fraction[:decimals], 10, 64)
+	if err != nil {
+		return 0
+	}
+	if fraction[decimals] >= '5' {
+		minor++
+	}
+
+	if price < 0 {
+		return -minor
+	}
+	return minor
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+func TestToMinorUnits(t *testing.T) {
+	tests := []struct {
+		price    float64
+		decimals int
+		expected int64
+	}{
+		{81.25, 2, 8125},
+		{81.2, 2, 8120},
+		{81, 2, 8100},
+		{81.255, 2, 8126}, // Binary 81.255 is slightly below, rounding uses the decimal value
+		{81.254, 2, 8125},
+		{81.2549, 2, 8125},
+		{0.005, 2, 1},
+		{0.004, 2, 0},
+		{81.25, 0, 81},
+		{81.5, 0, 82},
+		{1.123456789, 8, 112345679},
+		{-81.255, 2, -8126},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, toMinorUnits(tt.price, tt.decimals), "%v at %d decimals", tt.price, tt.decimals)
+	}
+}
+
+func TestGetRates_MinorUnits(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	client := newTestClient(t, srv)
+
+	mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	resp, err := client.GetRates(context.Background(), &pb.GetRatesReq{PriceFormat: pb.PriceFormat_PRICE_FORMAT_MINOR_UNITS})
+
+	require.NoError(t, err)
+	assert.Equal(t, 81.25, resp.AskPrice)
+	assert.Equal(t, int64(8125), resp.AskMinor)
+	assert.Equal(t, int64(8120), resp.BidMinor)
+	assert.Equal(t, int32(2), resp.PriceDecimals)
+}
+
+func TestGetRates_MinorUnitsConfiguredDecimals(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	srv.config.Grinex.PriceDecimals = map[string]int{"usdtrub": 4}
+	client := newTestClient(t, srv)
+
+	mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	resp, err := client.GetRates(context.Background(), &pb.GetRatesReq{PriceFormat: pb.PriceFormat_PRICE_FORMAT_MINOR_UNITS})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(812500), resp.AskMinor)
+	assert.Equal(t, int64(812000), resp.BidMinor)
+	assert.Equal(t, int32(4), resp.PriceDecimals)
+}
+
+func TestGetRates_FloatFormatOmitsMinorUnits(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	client := newTestClient(t, srv)
+
+	mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	resp, err := client.GetRates(context.Background(), &pb.GetRatesReq{})
+
+	require.NoError(t, err)
+	assert.Zero(t, resp.AskMinor)
+	assert.Zero(t, resp.BidMinor)
+	assert.Zero(t, resp.PriceDecimals)
+}
+
+func TestGetRates_FeeAdjusted(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	srv.config.Grinex.FeeBps = map[string]int{"usdtrub": 50}
+	client := newTestClient(t, srv)
+
+	mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	resp, err := client.GetRates(context.Background(), &pb.GetRatesReq{})
+
+	require.NoError(t, err)
+	// Raw prices are kept, 50 bps widens them by 0.5% each way
+	assert.Equal(t, 81.25, resp.AskPrice)
+	assert.Equal(t, 81.20, resp.BidPrice)
+	assert.InDelta(t, 81.65625, resp.ClientAsk, 1e-9)
+	assert.InDelta(t, 80.794, resp.ClientBid, 1e-9)
+	assert.Equal(t, int32(50), resp.FeeBps)
+}
+
+func TestGetRates_WithoutFee(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	client := newTestClient(t, srv)
+
+	mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	resp, err := client.GetRates(context.Background(), &pb.GetRatesReq{})
+
+	require.NoError(t, err)
+	assert.Equal(t, resp.AskPrice, resp.ClientAsk)
+	assert.Equal(t, resp.BidPrice, resp.ClientBid)
+	assert.Zero(t, resp.FeeBps)
+}
+
+func TestGetRates_PerMarketFeeAndDecimals(t *testing.T) {
+	srv, mock := newTestServer(t, basketHandler)
+	srv.config.Grinex.FeeBps = map[string]int{"usdtrub": 50, "btcrub": 10}
+	srv.config.Grinex.PriceDecimals = map[string]int{"usdtrub": 4, "btcrub": 0}
+	client := newTestClient(t, srv)
+
+	mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	resp, err := client.GetRates(context.Background(), &pb.GetRatesReq{
+		TradingPair: "BTC/RUB",
+		PriceFormat: pb.PriceFormat_PRICE_FORMAT_MINOR_UNITS,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "BTC/RUB", resp.TradingPair)
+	assert.Equal(t, int32(10), resp.FeeBps)
+	assert.InDelta(t, resp.AskPrice*1.001, resp.ClientAsk, 1e-6)
+	assert.Equal(t, int32(0), resp.PriceDecimals)
+	assert.Equal(t, int64(math.Round(resp.AskPrice)), resp.AskMinor)
+}
+
+func TestApplyFee_ClientBidNotNegative(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	srv.config.Grinex.FeeBps = map[string]int{"usdtrub": 20000}
+
+	resp := srv.applyFee(&pb.GetRatesResp{AskPrice: 81.25, BidPrice: 81.20}, "usdtrub")
+
+	assert.InDelta(t, 243.75, resp.ClientAsk, 1e-9)
+	assert.Zero(t, resp.ClientBid)
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"slices"
+	"time"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ReplayRates streams the stored rates of a pair within a time range oldest first, spacing the
+// messages by the original gaps between their created_at times divided by the speed multiplier
+func (s *RateServiceServer) ReplayRates(req *pb.ReplayReq, stream grpc.ServerStreamingServer[pb.ReplayResp]) error {
+	ctx, span := otel.Tracer("grinex-rate-service").Start(stream.Context(), "ReplayRates")
+	defer span.End()
+
+	s.logger.Info("ReplayRates called",
+		zap.String("trading_pair", req.GetTradingPair()),
+		zap.Float64("speed", req.GetSpeed()),
+	)
+
+	switch {
+	case req.GetTradingPair() == "":
+		return status.Error(codes.InvalidArgument, "trading_pair is required")
+	case req.GetStart() == nil || req.GetEnd() == nil:
+		return status.Error(codes.InvalidArgument, "start and end are required")
+	case req.GetSpeed() < 0:
+		return status.Error(codes.InvalidArgument, "speed must not be negative")
+	}
+
+	if err := s.checkPairAllowed(req.GetTradingPair()); err != nil {
+		return err
+	}
+
+	speed := req.GetSpeed()
+	if speed == 0 {
+		speed = 1
+	}
+
+	records, err := s.db.GetRatesByTimeRange(ctx, req.GetTradingPair(), req.GetStart().AsTime(), req.GetEnd().AsTime(), "", 0)
+	if err != nil {
+		s.logger.Error("Failed to get rates for replay", zap.Error(err))
+		return databaseError(err, "failed to get rates for replay")
+	}
+	slices.Reverse(records) // Stored rates come newest first
+
+	for i, record := range records {
+		if i > 0 {
+			gap := record.CreatedAt.Sub(records[i-1].CreatedAt)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(float64(gap) / speed)):
+			}
+		}
+
+		err := stream.Send(&pb.ReplayResp{
+			TradingPair: record.TradingPair,
+			AskPrice:    record.AskPrice,
+			BidPrice:    record.BidPrice,
+			Timestamp:   timestamppb.New(record.Timestamp),
+			CreatedAt:   timestamppb.New(record.CreatedAt),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+func TestReplayRates(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	client := newTestClient(t, srv)
+
+	start := time.Date(2025, 7, 28, 18, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	// Stored rates come newest first, a minute apart
+	rows := sqlmock.NewRows([]string{"id", "trading_pair", "ask_price", "bid_price", "timestamp", "created_at", "source"})
+	for id := 3; id >= 1; id-- {
+		created := start.Add(time.Duration(id) * time.Minute)
+		rows.AddRow(id, "USDT/RUB", 81.0+float64(id)/10, 80.9+float64(id)/10, created, created, "trades")
+	}
+	mock.ExpectQuery("SELECT id, trading_pair, ask_price, bid_price, timestamp, created_at, source FROM rates").
+		WithArgs("USDT/RUB", start, end).
+		WillReturnRows(rows)
+
+	stream, err := client.ReplayRates(context.Background(), &pb.ReplayReq{
+		TradingPair: "USDT/RUB",
+		Start:       timestamppb.New(start),
+		End:         timestamppb.New(end),
+		Speed:       3000, // A minute becomes 20ms
+	})
+	require.NoError(t, err)
+
+	began := time.Now()
+	var replayed []*pb.ReplayResp
+	for {
+		rate, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		replayed = append(replayed, rate)
+	}
+	elapsed := time.Since(began)
+
+	require.Len(t, replayed, 3)
+	for i, rate := range replayed {
+		assert.True(t, start.Add(time.Duration(i+1)*time.Minute).Equal(rate.CreatedAt.AsTime()), "replayed oldest first")
+	}
+	assert.Equal(t, 81.1, replayed[0].AskPrice)
+	assert.GreaterOrEqual(t, elapsed, 40*time.Millisecond, "relative timing is preserved")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReplayRates_Cancelled(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	client := newTestClient(t, srv)
+
+	start := time.Date(2025, 7, 28, 18, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT id, trading_pair, ask_price, bid_price, timestamp, created_at, source FROM rates").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "trading_pair", "ask_price", "bid_price", "timestamp", "created_at", "source"}).
+			AddRow(2, "USDT/RUB", 81.2, 81.1, start.Add(time.Hour), start.Add(time.Hour), "trades").
+			AddRow(1, "USDT/RUB", 81.1, 81.0, start, start, "trades"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.ReplayRates(ctx, &pb.ReplayReq{
+		TradingPair: "USDT/RUB",
+		Start:       timestamppb.New(start),
+		End:         timestamppb.New(start.Add(2 * time.Hour)),
+	})
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	// The second rate is an hour away at normal speed
+	cancel()
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Canceled, status.Code(err))
+}
+
+func TestReplayRates_InvalidArguments(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	client := newTestClient(t, srv)
+
+	start := timestamppb.New(time.Now().Add(-time.Hour))
+	end := timestamppb.Now()
+
+	tests := []struct {
+		name string
+		req  *pb.ReplayReq
+	}{
+		{"missing pair", &pb.ReplayReq{Start: start, End: end}},
+		{"missing range", &pb.ReplayReq{TradingPair: "USDT/RUB"}},
+		{"inverted range", &pb.ReplayReq{TradingPair: "USDT/RUB", Start: end, End: start}},
+		{"negative speed", &pb.ReplayReq{TradingPair: "USDT/RUB", Start: start, End: end, Speed: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream, err := client.ReplayRates(context.Background(), tt.req)
+			require.NoError(t, err)
+
+			_, err = stream.Recv()
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
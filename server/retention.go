@@ -0,0 +1,30 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// retentionInterval is how often rates older than RATE_RETENTION are pruned
+const retentionInterval = 24 * time.Hour
+
+// runRetention deletes the rates older than retention right away and then every interval until
+// ctx is cancelled. Failed runs are logged and retried on the next tick.
+func (s *RateServiceServer) runRetention(ctx context.Context, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.db.DeleteRatesOlderThan(time.Now().Add(-retention)); err != nil {
+			s.logger.Warn("Failed to delete old rates", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// beforeCutoff matches a cutoff retention before now, within a minute of slack
+type beforeCutoff struct {
+	retention time.Duration
+}
+
+func (b beforeCutoff) Match(v driver.Value) bool {
+	cutoff, ok := v.(time.Time)
+	if !ok {
+		return false
+	}
+	age := time.Since(cutoff)
+	return age >= b.retention && age < b.retention+time.Minute
+}
+
+func TestRunRetention(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	retention := 90 * 24 * time.Hour
+	for i := 0; i < 2; i++ {
+		mock.ExpectExec("DELETE FROM rates").
+			WithArgs(beforeCutoff{retention: retention}, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		srv.runRetention(ctx, retention, 10*time.Millisecond)
+	}()
+
+	assert.Eventually(t, func() bool { return mock.ExpectationsWereMet() == nil }, time.Second, 5*time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("retention did not stop after cancellation")
+	}
+}
+
+func TestRunRetention_ContinuesAfterFailure(t *testing.T) {
+	srv, mock := newTestServer(t, tradesHandler)
+	mock.ExpectExec("DELETE FROM rates").WillReturnError(assert.AnError)
+	mock.ExpectExec("DELETE FROM rates").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go srv.runRetention(ctx, time.Hour, 10*time.Millisecond)
+
+	assert.Eventually(t, func() bool { return mock.ExpectationsWereMet() == nil }, time.Second, 5*time.Millisecond)
+}
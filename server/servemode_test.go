@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/atadzan/grinex-rate-service/internal/config"
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+// newDBOnlyTestServer builds a db_only server whose fake Grinex counts the requests it receives
+func newDBOnlyTestServer(t *testing.T) (*RateServiceServer, sqlmock.Sqlmock, *atomic.Int32) {
+	var requests atomic.Int32
+	srv, mock := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		tradesHandler(w, r)
+	})
+	srv.config.Server.ServeMode = config.ServeModeDBOnly
+	return srv, mock, &requests
+}
+
+func TestServeModeDBOnly_NoGrinexRequests(t *testing.T) {
+	srv, mock, requests := newDBOnlyTestServer(t)
+	client := newTestClient(t, srv)
+
+	timestamp := time.Date(2025, 7, 28, 18, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT id, trading_pair, ask_price, bid_price, timestamp, created_at, vwap FROM rates").
+		WithArgs("USDT/RUB").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "trading_pair", "ask_price", "bid_price", "timestamp", "created_at", "vwap"}).
+			AddRow(1, "USDT/RUB", 81.30, 81.20, timestamp, timestamp, nil))
+
+	resp, err := client.GetRates(context.Background(), &pb.GetRatesReq{})
+	require.NoError(t, err)
+	assert.Equal(t, 81.30, resp.AskPrice)
+	assert.Equal(t, 81.20, resp.BidPrice)
+	assert.False(t, resp.Stale)
+	assert.True(t, timestamp.Equal(resp.Timestamp.AsTime()))
+
+	health, err := client.Healthcheck(context.Background(), &pb.HealthcheckReq{})
+	require.NoError(t, err)
+	assert.Equal(t, "healthy", health.Status)
+	require.Len(t, health.Components, 1) // Only the database is checked
+	assert.Equal(t, "database", health.Components[0].Name)
+
+	_, err = client.GetClockInfo(context.Background(), &pb.ClockInfoReq{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	assert.Zero(t, requests.Load(), "db_only mode never calls Grinex")
+	assert.NoError(t, mock.ExpectationsWereMet()) // Nothing is saved
+}
+
+func TestServeModeDBOnly_NoStoredRate(t *testing.T) {
+	srv, mock, requests := newDBOnlyTestServer(t)
+	client := newTestClient(t, srv)
+
+	mock.ExpectQuery("SELECT id, trading_pair, ask_price, bid_price, timestamp, created_at, vwap FROM rates").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := client.GetRates(context.Background(), &pb.GetRatesReq{})
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Zero(t, requests.Load())
+}
@@ -2,148 +2,771 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"strconv"
 	"time"
 
 	pb "github.com/atadzan/grinex-rate-service/proto/v1"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/prometheus"
+	otelmetric "go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 
 	"github.com/atadzan/grinex-rate-service/internal/config"
 	"github.com/atadzan/grinex-rate-service/internal/database"
+	"github.com/atadzan/grinex-rate-service/internal/poller"
 	"github.com/atadzan/grinex-rate-service/internal/service"
 
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type RateServiceServer struct {
 	pb.UnimplementedRateServiceServer
-	db        *database.Database
-	grinexSvc *service.GrinexService
-	config    *config.Config
-	logger    *zap.Logger
+	db          *database.Database
+	grinexSvc   *service.GrinexService
+	rateCache   *service.RateCache
+	maintenance *Maintenance
+	config      *config.Config
+	logger      *zap.Logger
+	// ratesRequests counts GetRates calls, nil when the counter could not be created
+	ratesRequests otelmetric.Int64Counter
+	// grinexHealth debounces the Grinex probes of Healthcheck, nil reporting every probe as is
+	grinexHealth *healthHysteresis
+	// healthScoreGauge records the score of every Healthcheck, nil when the gauge could not be created
+	healthScoreGauge otelmetric.Float64Gauge
+	// persistFailures counts failed saves of live rates, nil when the counter could not be created
+	persistFailures otelmetric.Int64Counter
+	// poller polls Grinex in the background, nil unless POLL_ENABLED
+	poller *poller.Poller
 }
 
 func NewRateServiceServer(cfg *config.Config, logger *zap.Logger) (*RateServiceServer, error) {
-	db, err := database.NewDatabase(cfg.Database.GetDSN(), logger)
+	pairLabels, err := service.MergePairLabels(cfg.Grinex.PairLabelsFile, cfg.Grinex.PairLabels)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize database: %w", err)
+		return nil, fmt.Errorf("failed to load pair labels: %w", err)
+	}
+
+	switch cfg.Grinex.OnFailure {
+	case config.OnFailureError, config.OnFailureLastKnown:
+	default:
+		return nil, fmt.Errorf("unknown Grinex failure behavior: %s", cfg.Grinex.OnFailure)
+	}
+
+	switch cfg.Server.ServeMode {
+	case config.ServeModeLive, config.ServeModeDBOnly:
+	default:
+		return nil, fmt.Errorf("unknown serve mode: %s", cfg.Server.ServeMode)
+	}
+
+	switch cfg.Grinex.RateSource {
+	case service.SourceTrades, service.SourceOrderBook:
+	default:
+		return nil, fmt.Errorf("unknown Grinex rate source: %s", cfg.Grinex.RateSource)
+	}
+
+	switch cfg.Grinex.HTTPVersion {
+	case service.HTTPVersionAuto, service.HTTPVersion1, service.HTTPVersion2:
+	default:
+		return nil, fmt.Errorf("unknown Grinex HTTP version: %s", cfg.Grinex.HTTPVersion)
+	}
+
+	if !SupportedLanguage(cfg.Server.DefaultLanguage) {
+		return nil, fmt.Errorf("unsupported default language: %s", cfg.Server.DefaultLanguage)
+	}
+
+	if cfg.Server.AlertPollJitter < 0 || cfg.Server.AlertPollJitter > maxAlertPollJitter {
+		return nil, fmt.Errorf("alert poll jitter must be between 0 and %d percent, got %d", maxAlertPollJitter, cfg.Server.AlertPollJitter)
 	}
 
-	if err := database.RunMigrations(cfg.Database.GetDSN()); err != nil {
+	if err := validateMethodTimeouts(cfg.Server.MethodTimeouts); err != nil {
+		return nil, err
+	}
+
+	if cfg.Grinex.DepthLimit < 1 || cfg.Grinex.DepthLimit > maxDepthLimit {
+		return nil, fmt.Errorf("depth limit must be between 1 and %d, got %d", maxDepthLimit, cfg.Grinex.DepthLimit)
+	}
+
+	for market, decimals := range cfg.Grinex.PriceDecimals {
+		if decimals < 0 || decimals > maxPriceDecimals {
+			return nil, fmt.Errorf("price decimals for %s must be between 0 and %d, got %d", market, maxPriceDecimals, decimals)
+		}
+	}
+
+	for market, bps := range cfg.Grinex.FeeBps {
+		if bps < 0 || bps > maxFeeBps {
+			return nil, fmt.Errorf("fee for %s must be between 0 and %d basis points, got %d", market, maxFeeBps, bps)
+		}
+	}
+
+	strategy, err := service.LookupPriceStrategy(cfg.Grinex.PriceStrategy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select price strategy: %w", err)
+	}
+	if cfg.Grinex.PriceStrategy == service.StrategyTrimmedMean {
+		if cfg.Grinex.TrimFraction < 0 || cfg.Grinex.TrimFraction >= 0.5 {
+			return nil, fmt.Errorf("trim fraction must be at least 0 and below 0.5, got %v", cfg.Grinex.TrimFraction)
+		}
+		strategy = service.TrimmedMeanStrategy{Trim: cfg.Grinex.TrimFraction}
+	}
+
+	dbConfig := &database.Config{
+		DSN:               cfg.Database.GetDSN(),
+		MaxQueryRange:     cfg.Database.MaxQueryRange,
+		PersistRates:      cfg.Database.PersistRates,
+		PersistRateLevels: cfg.Database.PersistRateLevels,
+		PersistHeartbeats: cfg.Database.PersistHeartbeats,
+		MaxRateLevels:     cfg.Database.MaxRateLevels,
+		PrepareStatements: cfg.Database.PrepareStatements,
+		Schema:            cfg.Database.Schema,
+		TablePrefix:       cfg.Database.TablePrefix,
+		MaxOpenConns:      cfg.Database.MaxOpenConns,
+		MaxIdleConns:      cfg.Database.MaxIdleConns,
+		ConnMaxLifetime:   cfg.Database.ConnMaxLifetime,
+		ConnMaxIdleTime:   cfg.Database.ConnMaxIdleTime,
+	}
+
+	tables, err := database.NewTables(cfg.Database.Schema, cfg.Database.TablePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	// Migrations run first so prepared statements can reference the tables
+	if err := database.RunMigrations(cfg.Database.GetDSN(), cfg.Database.MigrationLockKey, tables); err != nil {
 		return nil, fmt.Errorf("failed to run database migrations: %w", err)
 	}
 
+	db, err := database.NewDatabase(dbConfig, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+
 	grinexConfig := &service.GrinexConfig{
-		BaseURL:   cfg.Grinex.BaseURL,
-		Timeout:   cfg.Grinex.Timeout,
-		UserAgent: cfg.Grinex.UserAgent,
+		BaseURL:               cfg.Grinex.BaseURL,
+		Timeout:               cfg.Grinex.Timeout,
+		UserAgent:             cfg.Grinex.UserAgent,
+		PairLabels:            pairLabels,
+		PriceStrategy:         strategy,
+		HedgeDelay:            cfg.Grinex.HedgeDelay,
+		TimestampTrades:       cfg.Grinex.TimestampTrades,
+		TimestampBucket:       cfg.Grinex.TimestampBucket,
+		KeepRawTimestamp:      cfg.Grinex.KeepRawTimestamp,
+		TradesLimit:           cfg.Grinex.TradesLimit,
+		MaxTradesLimit:        cfg.Grinex.MaxTradesLimit,
+		MarketMaxTradesLimits: cfg.Grinex.MarketMaxTradesLimits,
+		HealthMaxTradeAge:     cfg.Grinex.HealthMaxTradeAge,
+		HTTPVersion:           cfg.Grinex.HTTPVersion,
+		BodyReadTimeout:       cfg.Grinex.BodyReadTimeout,
+		CheckContentType:      cfg.Grinex.CheckContentType,
+		ConnectTimeout:        cfg.Grinex.ConnectTimeout,
+		MinTrades:             cfg.Grinex.MinTrades,
+		TLSHandshakeTimeout:   cfg.Grinex.TLSHandshakeTimeout,
+		DepthCacheTTL:         cfg.Grinex.DepthCacheTTL,
 	}
 	grinexSvc := service.NewGrinexService(grinexConfig, logger)
 
+	ratesRequests, err := newRatesRequestsCounter(otel.Meter("grinex-rate-service"))
+	if err != nil {
+		logger.Warn("Failed to create GetRates requests metric", zap.Error(err))
+	}
+	healthScoreGauge, err := newHealthScoreGauge(otel.Meter("grinex-rate-service"))
+	if err != nil {
+		logger.Warn("Failed to create health score metric", zap.Error(err))
+	}
+	persistFailures, err := newPersistFailuresCounter(otel.Meter("grinex-rate-service"))
+	if err != nil {
+		logger.Warn("Failed to create persist failures metric", zap.Error(err))
+	}
+
 	return &RateServiceServer{
-		db:        db,
-		grinexSvc: grinexSvc,
-		config:    cfg,
-		logger:    logger,
+		db:               db,
+		grinexSvc:        grinexSvc,
+		rateCache:        service.NewRateCache(cfg.Grinex.RateCacheTTL, cfg.Grinex.Timeout),
+		maintenance:      &Maintenance{},
+		config:           cfg,
+		logger:           logger,
+		ratesRequests:    ratesRequests,
+		grinexHealth:     newHealthHysteresis(cfg.Server.HealthFailureThreshold, cfg.Server.HealthRecoveryThreshold),
+		healthScoreGauge: healthScoreGauge,
+		persistFailures:  persistFailures,
 	}, nil
 }
 
-func (s *RateServiceServer) GetRates(ctx context.Context, req *pb.GetRatesReq) (*pb.GetRatesResp, error) {
+func (s *RateServiceServer) GetRates(ctx context.Context, req *pb.GetRatesReq) (_ *pb.GetRatesResp, err error) {
 	ctx, span := otel.Tracer("grinex-rate-service").Start(ctx, "GetRates")
 	defer span.End()
 
-	s.logger.Info("GetRates called")
+	s.logger.Info("GetRates called", zap.String("trading_pair", req.GetTradingPair()))
+
+	ctx, attempts := service.WithAttemptCounter(ctx)
+	var source string
+	pair := unknownPairLabel
+	defer func() {
+		setRatesTrailer(ctx, attempts.Count(), source)
+		s.recordRatesRequest(ctx, pair, err)
+	}()
+
+	loc, err := resolveTimezone(req.GetTimezone())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid timezone: %v", err)
+	}
+	if err := validateRatesFieldMask(req.GetFields()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid fields: %v", err)
+	}
+	if _, ok := pb.RateSource_name[int32(req.GetSource())]; !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown source: %d", req.GetSource())
+	}
+	market := service.USDTMarket
+	if req.GetTradingPair() != "" {
+		if market, err = s.grinexSvc.ResolvePair(req.GetTradingPair()); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid trading_pair: %v", err)
+		}
+	}
+	pair = s.grinexSvc.PairLabel(market)
+	if err := s.checkMarketAllowed(market); err != nil {
+		return nil, err
+	}
+
+	if s.dbOnly() {
+		rate, err := s.storedRate(market)
+		if err != nil {
+			s.logger.Error("Failed to get latest rate from database", zap.Error(err))
+			return nil, databaseError(err, "failed to get latest rate")
+		}
+		source = dataSourceDatabase
+		return s.finishRatesResp(rate.ToProtoIn(loc), req, market, source), nil
+	}
 
-	rate, err := s.grinexSvc.GetUSDTRate(ctx)
+	grinexSvc, err := s.grinexServiceFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rate, fetched, err := s.cachedLiveRate(ctx, grinexSvc, market, req.GetSource())
 	if err != nil {
 		s.logger.Error("Failed to get rate from Grinex", zap.Error(err))
-		return nil, fmt.Errorf("failed to get rate from Grinex: %w", err)
+		if s.config.Grinex.OnFailure != config.OnFailureLastKnown {
+			return nil, fmt.Errorf("failed to get rate from Grinex: %w", err)
+		}
+		resp, err := s.lastKnownRate(market, loc, err)
+		if err != nil {
+			return nil, err
+		}
+		source = dataSourceDatabase
+		return s.finishRatesResp(resp, req, market, source), nil
+	}
+
+	if !fetched {
+		source = dataSourceCache
+		return s.finishRatesResp(rate.ToProtoIn(loc), req, market, source), nil
 	}
 
-	dbRecord := &database.RateRecord{
-		TradingPair: rate.TradingPair,
-		AskPrice:    rate.AskPrice,
-		BidPrice:    rate.BidPrice,
-		Timestamp:   rate.Timestamp,
-		CreatedAt:   time.Now(),
+	// Rates from an overridden base URL never reach the rates table. Without FAIL_ON_PERSIST_ERROR
+	// a failed save is only logged and counted, the client still gets the valid live rate
+	if grinexSvc == s.grinexSvc {
+		if err := s.saveRate(rate); err != nil {
+			s.logger.Error("Failed to save rate to database", zap.Error(err))
+			if s.config.Server.FailOnPersistError {
+				return nil, fmt.Errorf("failed to save rate to database: %w", err)
+			}
+		}
 	}
 
-	if err := s.db.SaveRate(dbRecord); err != nil {
-		s.logger.Error("Failed to save rate to database", zap.Error(err))
-		return nil, fmt.Errorf("failed to save rate to database: %w", err)
+	source = dataSourceGrinex
+	return s.finishRatesResp(rate.ToProtoIn(loc), req, market, source), nil
+}
+
+// saveRate stores a rate fetched from Grinex along with the strategy that computed it, counting
+// failed saves. The order book levels of a rate taken from the order book are stored with it
+// when DB_PERSIST_RATE_LEVELS is set, failing to store them is only logged.
+func (s *RateServiceServer) saveRate(rate *service.Rate) error {
+	record := &database.RateRecord{
+		TradingPair:  rate.TradingPair,
+		AskPrice:     rate.AskPrice,
+		BidPrice:     rate.BidPrice,
+		Timestamp:    rate.Timestamp,
+		CreatedAt:    rate.IngestedAt,
+		Strategy:     s.config.Grinex.PriceStrategy,
+		RawTimestamp: rate.RawTimestamp,
+		Source:       rate.Source,
+		VWAP:         rate.VWAP,
+	}
+	if err := s.db.SaveRate(record); err != nil {
+		s.recordPersistFailure(rate.TradingPair)
+		return err
+	}
+
+	// A zero ID means rate persistence is disabled and there is no record to link levels to
+	if rate.Depth != nil && record.ID != 0 {
+		if err := s.db.SaveRateLevels(record.ID, rateLevels(rate.Depth)); err != nil {
+			s.logger.Warn("Failed to save rate levels to database", zap.Int64("rate_id", record.ID), zap.Error(err))
+		}
 	}
+	return nil
+}
+
+// rateLevels converts the order book of a rate to stored levels, numbered from 1 on each side
+func rateLevels(depth *service.Depth) []database.RateLevel {
+	levels := make([]database.RateLevel, 0, len(depth.Asks)+len(depth.Bids))
+	for i, ask := range depth.Asks {
+		levels = append(levels, database.RateLevel{Side: database.SideAsk, Level: i + 1, Price: ask.Price, Volume: ask.Volume})
+	}
+	for i, bid := range depth.Bids {
+		levels = append(levels, database.RateLevel{Side: database.SideBid, Level: i + 1, Price: bid.Price, Volume: bid.Volume})
+	}
+	return levels
+}
+
+// Trailer metadata describing how GetRates obtained the rate
+const (
+	// attemptsTrailerKey is the number of HTTP requests sent to Grinex, hedged requests included
+	attemptsTrailerKey = "x-grinex-attempts"
+	// dataSourceTrailerKey is where the rate came from: dataSourceGrinex, dataSourceDatabase or dataSourceCache
+	dataSourceTrailerKey = "x-data-source"
+
+	dataSourceGrinex   = "grinex"
+	dataSourceDatabase = "database"
+	dataSourceCache    = "cache"
+)
+
+// setRatesTrailer attaches the Grinex attempt count and, once known, the data source to the
+// response trailer. Calls outside a gRPC stream, e.g. direct calls in tests, are ignored.
+func setRatesTrailer(ctx context.Context, attempts int, source string) {
+	md := metadata.Pairs(attemptsTrailerKey, strconv.Itoa(attempts))
+	if source != "" {
+		md.Set(dataSourceTrailerKey, source)
+	}
+	_ = grpc.SetTrailer(ctx, md)
+}
+
+// finishRatesResp sets the data source of resp, adds the fee adjusted and requested price
+// formats configured for market and applies the field mask
+func (s *RateServiceServer) finishRatesResp(resp *pb.GetRatesResp, req *pb.GetRatesReq, market, source string) *pb.GetRatesResp {
+	resp.Source = source
+	return applyRatesFieldMask(s.formatPrices(s.applyFee(resp, market), market, req.GetPriceFormat()), req.GetFields())
+}
+
+// lastKnownRate serves the most recent stored rate marked as stale after a failed Grinex fetch.
+// A rate stored longer than GRINEX_LAST_KNOWN_MAX_AGE ago is not served and the fetch error is
+// returned instead.
+func (s *RateServiceServer) lastKnownRate(market string, loc *time.Location, fetchErr error) (*pb.GetRatesResp, error) {
+	rate, err := s.lastKnownStoredRate(market, fetchErr)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := rate.ToProtoIn(loc)
+	resp.Stale = true
+	resp.Age = durationpb.New(time.Since(rate.IngestedAt))
+	return resp, nil
+}
+
+// lastKnownStoredRate returns the most recent stored rate of a market to fall back on after a
+// failed Grinex fetch, or the fetch error when there is none younger than GRINEX_LAST_KNOWN_MAX_AGE
+func (s *RateServiceServer) lastKnownStoredRate(market string, fetchErr error) (*service.Rate, error) {
+	rate, err := s.storedRate(market)
+	if err != nil {
+		s.logger.Error("Failed to get last known rate from database", zap.Error(err))
+		return nil, fmt.Errorf("failed to get rate from Grinex: %w", fetchErr)
+	}
+
+	age := time.Since(rate.IngestedAt)
+	if maxAge := s.config.Grinex.LastKnownMaxAge; maxAge > 0 && age > maxAge {
+		s.logger.Warn("Last known rate is too old to serve",
+			zap.String("trading_pair", rate.TradingPair),
+			zap.Duration("age", age),
+			zap.Duration("max_age", maxAge),
+		)
+		return nil, fmt.Errorf("failed to get rate from Grinex: %w", fetchErr)
+	}
+
+	s.logger.Warn("Serving last known rate from database",
+		zap.String("trading_pair", rate.TradingPair),
+		zap.Time("timestamp", rate.Timestamp),
+		zap.Duration("age", age),
+	)
+	return rate, nil
+}
+
+// cachedLiveRate serves live rates through the rate cache keyed by market and source. fetched
+// reports whether this call fetched the rate from Grinex rather than reusing a cached or
+// concurrently fetched one. Requests with a base URL override bypass the cache.
+func (s *RateServiceServer) cachedLiveRate(ctx context.Context, grinexSvc *service.GrinexService, market string, source pb.RateSource) (*service.Rate, bool, error) {
+	fetch := func(ctx context.Context) (*service.Rate, error) {
+		return s.liveRate(ctx, grinexSvc, market, source)
+	}
+	if grinexSvc != s.grinexSvc {
+		rate, err := fetch(ctx)
+		return rate, true, err
+	}
+
+	return s.rateCache.Get(ctx, s.rateCacheKey(market, source), fetch)
+}
 
-	// Convert to protobuf response
-	return &pb.GetRatesResp{
-		TradingPair: rate.TradingPair,
-		AskPrice:    rate.AskPrice,
-		BidPrice:    rate.BidPrice,
-		Timestamp:   timestamppb.New(rate.Timestamp),
+// rateCacheKey names the cache entry of a market's rate from a source
+func (s *RateServiceServer) rateCacheKey(market string, source pb.RateSource) string {
+	return market + "/" + s.effectiveRateSource(source)
+}
+
+// effectiveRateSource names the source a request is served from
+func (s *RateServiceServer) effectiveRateSource(source pb.RateSource) string {
+	switch source {
+	case pb.RateSource_RATE_SOURCE_TRADES:
+		return service.SourceTrades
+	case pb.RateSource_RATE_SOURCE_ORDER_BOOK:
+		return service.SourceOrderBook
+	default:
+		return s.config.Grinex.RateSource
+	}
+}
+
+// liveRate fetches the rate of a market from the requested source, defaulting to GRINEX_RATE_SOURCE,
+// and marks it as ingested now
+func (s *RateServiceServer) liveRate(ctx context.Context, grinexSvc *service.GrinexService, market string, source pb.RateSource) (*service.Rate, error) {
+	var rate *service.Rate
+	var err error
+	if s.effectiveRateSource(source) == service.SourceOrderBook {
+		rate, err = grinexSvc.GetRateFromOrderBook(ctx, market)
+	} else {
+		rate, err = grinexSvc.GetRate(ctx, market)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rate.IngestedAt = time.Now()
+	return rate, nil
+}
+
+// storedRate returns the most recent rate of a market stored in the database
+func (s *RateServiceServer) storedRate(market string) (*service.Rate, error) {
+	record, err := s.db.GetLatestRate(s.grinexSvc.PairLabel(market))
+	if err != nil {
+		return nil, err
+	}
+
+	return &service.Rate{
+		TradingPair: record.TradingPair,
+		AskPrice:    record.AskPrice,
+		BidPrice:    record.BidPrice,
+		MidPrice:    (record.AskPrice + record.BidPrice) / 2,
+		VWAP:        record.VWAP,
+		Timestamp:   record.Timestamp,
+		IngestedAt:  record.CreatedAt,
 	}, nil
 }
 
+// dbOnly reports whether the server serves stored rates without calling Grinex
+func (s *RateServiceServer) dbOnly() bool {
+	return s.config.Server.ServeMode == config.ServeModeDBOnly
+}
+
+// resolveTimezone loads the requested IANA timezone, defaulting to UTC
+func resolveTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(name)
+}
+
 func (s *RateServiceServer) Healthcheck(ctx context.Context, req *pb.HealthcheckReq) (*pb.HealthcheckResp, error) {
 	ctx, span := otel.Tracer("grinex-rate-service").Start(ctx, "Healthcheck")
 	defer span.End()
 
 	s.logger.Info("Healthcheck called")
 
-	status := "healthy"
-	message := "Service is healthy"
+	resp := &pb.HealthcheckResp{
+		Status:  healthStatusHealthy,
+		Message: "Service is healthy",
+	}
 
-	// Check database health
-	if err := s.db.HealthCheck(); err != nil {
-		status = "unhealthy"
-		message = fmt.Sprintf("Database health check failed: %v", err)
-		s.logger.Error("Database health check failed", zap.Error(err))
-		return &pb.HealthcheckResp{
-			Status:  status,
-			Message: message,
-		}, fmt.Errorf("database health check failed: %w", err)
+	db, dbErr := checkComponent(componentDatabase, s.db.HealthCheck)
+	if dbErr != nil {
+		s.logger.Error("Database health check failed", zap.Error(dbErr))
+	}
+	resp.Components = append(resp.Components, db)
+
+	// Check Grinex API health, which db_only replicas never call. The hysteresis decides whether
+	// the probe counts as failed, so the component agrees with the overall status.
+	var grinex *pb.ComponentHealth
+	if !s.dbOnly() {
+		var failure error
+		grinex, failure = checkComponent(componentGrinex, func() error {
+			err := s.grinexSvc.HealthCheck(ctx)
+			if err != nil {
+				s.logger.Warn("Grinex API health check failed", zap.Error(err))
+			}
+			if degraded, failure := s.grinexHealth.observe(err); degraded {
+				return failure
+			}
+			return nil
+		})
+		if failure != nil {
+			resp.Status = healthStatusDegraded
+			resp.Message = fmt.Sprintf("Grinex API health check failed: %v", failure)
+		}
+		resp.Components = append(resp.Components, grinex)
+	}
+
+	// A failed database outranks a degraded Grinex. Either is reported in the response rather
+	// than as an RPC error, so probes can read which dependency failed.
+	if dbErr != nil {
+		resp.Status = healthStatusUnhealthy
+		resp.Message = fmt.Sprintf("Database health check failed: %v", dbErr)
 	}
 
-	// Check Grinex API health
-	if err := s.grinexSvc.HealthCheck(ctx); err != nil {
-		status = "degraded"
-		message = fmt.Sprintf("Grinex API health check failed: %v", err)
-		s.logger.Warn("Grinex API health check failed", zap.Error(err))
+	resp.Score, resp.ScoreContributions = s.healthScore(ctx, db, grinex)
+	if s.healthScoreGauge != nil && resp.ScoreContributions != nil {
+		s.healthScoreGauge.Record(ctx, resp.Score)
 	}
 
-	return &pb.HealthcheckResp{
-		Status:  status,
-		Message: message,
+	return resp, nil
+}
+
+// healthScore rates the checked dependencies by their latency and the newest stored rate by its
+// age, weighted by HEALTH_WEIGHT_*. A nil grinex, as in db_only serve mode, is left out.
+func (s *RateServiceServer) healthScore(ctx context.Context, db, grinex *pb.ComponentHealth) (float64, []*pb.ScoreContribution) {
+	cfg := s.config.Server
+	inputs := []scoreInput{
+		{name: componentDatabase, weight: cfg.HealthWeightDatabase, score: componentScore(db, cfg.HealthMaxLatency)},
+	}
+	if grinex != nil {
+		inputs = append(inputs, scoreInput{name: componentGrinex, weight: cfg.HealthWeightGrinex, score: componentScore(grinex, cfg.HealthMaxLatency)})
+	}
+
+	// The freshness query is skipped when it can't matter or can't succeed
+	freshness := scoreInput{name: scoreFreshness, weight: cfg.HealthWeightFreshness}
+	if freshness.weight > 0 && db.Status == healthStatusHealthy {
+		latest, err := s.db.LatestRateTime(ctx)
+		if err != nil {
+			s.logger.Warn("Failed to get latest rate time for the health score", zap.Error(err))
+		} else {
+			freshness.score = linearScore(time.Since(latest), cfg.HealthMaxRateAge)
+		}
+	}
+	inputs = append(inputs, freshness)
+
+	return healthScore(inputs)
+}
+
+func (s *RateServiceServer) GetVolatility(ctx context.Context, req *pb.GetVolatilityReq) (*pb.GetVolatilityResp, error) {
+	_, span := otel.Tracer("grinex-rate-service").Start(ctx, "GetVolatility")
+	defer span.End()
+
+	s.logger.Info("GetVolatility called", zap.String("trading_pair", req.GetTradingPair()))
+
+	if req.GetTradingPair() == "" {
+		return nil, status.Error(codes.InvalidArgument, "trading_pair is required")
+	}
+	if err := s.checkPairAllowed(req.GetTradingPair()); err != nil {
+		return nil, err
+	}
+	if req.GetWindow() == nil {
+		return nil, status.Error(codes.InvalidArgument, "window is required")
+	}
+
+	volatility, err := s.db.GetVolatility(ctx, req.GetTradingPair(), req.GetWindow().AsDuration())
+	if err != nil {
+		s.logger.Error("Failed to get volatility from database", zap.Error(err))
+		return nil, databaseError(err, "failed to get volatility")
+	}
+
+	return &pb.GetVolatilityResp{
+		TradingPair: req.GetTradingPair(),
+		Volatility:  volatility,
+		Window:      req.GetWindow(),
 	}, nil
 }
 
+// GetTWAP returns the time-weighted average mid-price of the stored rates in a time range
+func (s *RateServiceServer) GetTWAP(ctx context.Context, req *pb.GetTWAPReq) (*pb.GetTWAPResp, error) {
+	_, span := otel.Tracer("grinex-rate-service").Start(ctx, "GetTWAP")
+	defer span.End()
+
+	s.logger.Info("GetTWAP called", zap.String("trading_pair", req.GetTradingPair()))
+
+	if req.GetTradingPair() == "" {
+		return nil, status.Error(codes.InvalidArgument, "trading_pair is required")
+	}
+	if err := s.checkPairAllowed(req.GetTradingPair()); err != nil {
+		return nil, err
+	}
+	if req.GetStart() == nil || req.GetEnd() == nil {
+		return nil, status.Error(codes.InvalidArgument, "start and end are required")
+	}
+
+	twap, err := s.db.GetTWAP(ctx, req.GetTradingPair(), req.GetStart().AsTime(), req.GetEnd().AsTime())
+	if err != nil {
+		s.logger.Error("Failed to get TWAP from database", zap.Error(err))
+		return nil, databaseError(err, "failed to get TWAP")
+	}
+
+	return &pb.GetTWAPResp{
+		TradingPair: req.GetTradingPair(),
+		Twap:        twap,
+		Start:       req.GetStart(),
+		End:         req.GetEnd(),
+	}, nil
+}
+
+// FindGaps reports the periods within a time range where consecutive stored rates are further apart than max_gap
+func (s *RateServiceServer) FindGaps(ctx context.Context, req *pb.FindGapsReq) (*pb.FindGapsResp, error) {
+	_, span := otel.Tracer("grinex-rate-service").Start(ctx, "FindGaps")
+	defer span.End()
+
+	s.logger.Info("FindGaps called", zap.String("trading_pair", req.GetTradingPair()))
+
+	if req.GetTradingPair() == "" {
+		return nil, status.Error(codes.InvalidArgument, "trading_pair is required")
+	}
+	if err := s.checkPairAllowed(req.GetTradingPair()); err != nil {
+		return nil, err
+	}
+	if req.GetStart() == nil || req.GetEnd() == nil {
+		return nil, status.Error(codes.InvalidArgument, "start and end are required")
+	}
+	if req.GetMaxGap() == nil {
+		return nil, status.Error(codes.InvalidArgument, "max_gap is required")
+	}
+
+	gaps, err := s.db.FindGaps(ctx, req.GetTradingPair(), req.GetStart().AsTime(), req.GetEnd().AsTime(), req.GetMaxGap().AsDuration())
+	if err != nil {
+		s.logger.Error("Failed to find gaps in stored rates", zap.Error(err))
+		return nil, databaseError(err, "failed to find gaps")
+	}
+
+	resp := &pb.FindGapsResp{TradingPair: req.GetTradingPair()}
+	for _, gap := range gaps {
+		resp.Gaps = append(resp.Gaps, &pb.Gap{
+			Start:    timestamppb.New(gap.Start),
+			End:      timestamppb.New(gap.End),
+			Duration: durationpb.New(gap.Duration()),
+		})
+	}
+	return resp, nil
+}
+
+// GetClockInfo returns the server clock, the time of the latest Grinex trade and the drift between them
+func (s *RateServiceServer) GetClockInfo(ctx context.Context, req *pb.ClockInfoReq) (*pb.ClockInfoResp, error) {
+	ctx, span := otel.Tracer("grinex-rate-service").Start(ctx, "GetClockInfo")
+	defer span.End()
+
+	s.logger.Info("GetClockInfo called")
+
+	if s.dbOnly() {
+		return nil, status.Error(codes.FailedPrecondition, "clock info needs Grinex, which is not called in db_only serve mode")
+	}
+
+	grinexTime, err := s.grinexSvc.LatestTradeTime(ctx, service.USDTMarket)
+	if err != nil {
+		s.logger.Error("Failed to get latest trade time from Grinex", zap.Error(err))
+		return nil, fmt.Errorf("failed to get latest trade time from Grinex: %w", err)
+	}
+	serverTime := time.Now()
+
+	return &pb.ClockInfoResp{
+		ServerTime: timestamppb.New(serverTime),
+		GrinexTime: timestamppb.New(grinexTime),
+		Drift:      durationpb.New(serverTime.Sub(grinexTime)),
+	}, nil
+}
+
+// databaseError maps database errors to gRPC status codes
+func databaseError(err error, msg string) error {
+	switch {
+	case errors.Is(err, database.ErrInvalidTimeRange):
+		return status.Errorf(codes.InvalidArgument, "%s: %v", msg, err)
+	case errors.Is(err, database.ErrNoRates):
+		return status.Errorf(codes.NotFound, "%s: %v", msg, err)
+	default:
+		return fmt.Errorf("%s: %w", msg, err)
+	}
+}
+
 func (s *RateServiceServer) Close() error {
 	return s.db.Close()
 }
 
 func StartServer(ctx context.Context, cfg *config.Config, logger *zap.Logger) error {
+	tlsConfig, err := LoadServerTLSConfig(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile, cfg.Server.TLSClientCAFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS config: %w", err)
+	}
+
 	server, err := NewRateServiceServer(cfg, logger)
 	if err != nil {
 		return fmt.Errorf("failed to create server: %w", err)
 	}
 	defer server.Close()
 
+	// db_only mode never calls Grinex, so it runs no poller
+	var ratePoller *poller.Poller
+	if cfg.Poller.Enabled && !server.dbOnly() {
+		ratePoller, err = poller.New(server.grinexSvc, server.pollMarket, poller.Config{
+			Interval:       cfg.Poller.Interval,
+			Pairs:          cfg.Poller.Pairs,
+			AllowedMarkets: cfg.Server.AllowedMarkets,
+		}, logger)
+		if err != nil {
+			return fmt.Errorf("failed to create poller: %w", err)
+		}
+		server.poller = ratePoller
+	}
+
 	port := ":" + cfg.Server.Port
 	lis, err := net.Listen("tcp", port)
 	if err != nil {
 		return fmt.Errorf("failed to listen: %v", err)
 	}
 
-	s := grpc.NewServer()
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			LocalizeUnaryInterceptor(cfg.Server.DefaultLanguage),
+			RequiredMetadataUnaryInterceptor(cfg.Server.RequiredMetadata),
+			MaintenanceUnaryInterceptor(server.maintenance),
+			TimeoutUnaryInterceptor(cfg.Server.MethodTimeouts, cfg.Server.DefaultMethodTimeout),
+		),
+		grpc.ChainStreamInterceptor(
+			LocalizeStreamInterceptor(cfg.Server.DefaultLanguage),
+			RequiredMetadataStreamInterceptor(cfg.Server.RequiredMetadata),
+			MaintenanceStreamInterceptor(server.maintenance),
+		),
+	}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	s := grpc.NewServer(opts...)
 	pb.RegisterRateServiceServer(s, server)
 
 	reflection.Register(s)
 
-	logger.Info("gRPC server listening", zap.String("port", port))
+	logger.Info("gRPC server listening",
+		zap.String("port", port),
+		zap.Bool("tls", tlsConfig != nil),
+		zap.Bool("mtls", tlsConfig != nil && tlsConfig.ClientCAs != nil),
+	)
+
+	if cfg.Server.HeartbeatInterval > 0 {
+		go server.runHeartbeat(ctx, cfg.Server.HeartbeatInterval, heartbeatInstance())
+	}
+	if ratePoller != nil {
+		go ratePoller.Run(ctx)
+	}
+	if cfg.Database.RateRetention > 0 {
+		go server.runRetention(ctx, cfg.Database.RateRetention, retentionInterval)
+	}
+	if cfg.Metrics.Port != "" && cfg.Metrics.Backend != config.MetricsBackendOTLP {
+		go runMetricsServer(ctx, cfg.Metrics.Port, 5*time.Second, logger)
+	}
 
 	// Start server in a goroutine
 	go func() {
@@ -170,15 +793,21 @@ func StartServer(ctx context.Context, cfg *config.Config, logger *zap.Logger) er
 	return nil
 }
 
-// SetupMetrics sets up Prometheus metrics
-func SetupMetrics() (*metric.MeterProvider, error) {
-	exporter, err := prometheus.New()
+// SetupMetrics sets up the global meter provider with the configured metrics backend. Shut the
+// returned provider down on exit so the OTLP backend flushes the last collection.
+func SetupMetrics(cfg config.MetricsConfig) (*metric.MeterProvider, error) {
+	reader, err := newMetricReader(context.Background(), cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create prometheus exporter: %w", err)
+		return nil, err
 	}
 
-	provider := metric.NewMeterProvider(metric.WithReader(exporter))
+	opts := append([]metric.Option{metric.WithReader(reader)}, dropLabelsOptions(cfg.DropLabels)...)
+	provider := metric.NewMeterProvider(opts...)
 	otel.SetMeterProvider(provider)
 
+	if err := RegisterRuntimeMetrics(provider.Meter("grinex-rate-service")); err != nil {
+		return nil, fmt.Errorf("failed to register runtime metrics: %w", err)
+	}
+
 	return provider, nil
 }
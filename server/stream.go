@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/atadzan/grinex-rate-service/internal/service"
+)
+
+const (
+	// defaultStreamInterval is used when StreamRates is called without an interval
+	defaultStreamInterval = time.Second
+	// minStreamInterval bounds how often a single StreamRates call fetches rates
+	minStreamInterval = 500 * time.Millisecond
+)
+
+// StreamRates fetches the rate of a pair every interval and sends it down the stream like
+// GetRates would return it, skipping rates whose ask and bid match the last one sent. Rates
+// fetched from Grinex are stored as in GetRates. The stream runs until the client cancels it.
+func (s *RateServiceServer) StreamRates(req *pb.StreamRatesReq, stream grpc.ServerStreamingServer[pb.GetRatesResp]) error {
+	ctx, span := otel.Tracer("grinex-rate-service").Start(stream.Context(), "StreamRates")
+	defer span.End()
+
+	s.logger.Info("StreamRates called",
+		zap.String("trading_pair", req.GetTradingPair()),
+		zap.Duration("interval", req.GetInterval().AsDuration()),
+	)
+
+	loc, err := resolveTimezone(req.GetTimezone())
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid timezone: %v", err)
+	}
+	if _, ok := pb.RateSource_name[int32(req.GetSource())]; !ok {
+		return status.Errorf(codes.InvalidArgument, "unknown source: %d", req.GetSource())
+	}
+	interval := defaultStreamInterval
+	if req.GetInterval() != nil {
+		if interval = req.GetInterval().AsDuration(); interval <= 0 {
+			return status.Error(codes.InvalidArgument, "interval must be positive")
+		}
+	}
+	interval = max(interval, minStreamInterval)
+	market := service.USDTMarket
+	if req.GetTradingPair() != "" {
+		if market, err = s.grinexSvc.ResolvePair(req.GetTradingPair()); err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid trading_pair: %v", err)
+		}
+	}
+	if err := s.checkMarketAllowed(market); err != nil {
+		return err
+	}
+
+	grinexSvc := s.grinexSvc
+	if !s.dbOnly() {
+		if grinexSvc, err = s.grinexServiceFor(ctx); err != nil {
+			return err
+		}
+	}
+
+	ratesReq := &pb.GetRatesReq{Timezone: req.GetTimezone(), Source: req.GetSource(), TradingPair: req.GetTradingPair()}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last *service.Rate
+	for {
+		rate, source, err := s.streamRate(ctx, grinexSvc, market, req.GetSource())
+		if err != nil {
+			s.logger.Warn("Failed to get rate for stream", zap.String("market", market), zap.Error(err))
+		} else if last == nil || rate.AskPrice != last.AskPrice || rate.BidPrice != last.BidPrice {
+			if err := stream.Send(s.finishRatesResp(rate.ToProtoIn(loc), ratesReq, market, source)); err != nil {
+				return err
+			}
+			last = rate
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// streamRate returns the next rate for StreamRates along with its data source: the stored one
+// in db_only serve mode, and otherwise a live rate, stored when this call fetched it from the
+// configured Grinex base URL rather than reading it from the cache or an override
+func (s *RateServiceServer) streamRate(ctx context.Context, grinexSvc *service.GrinexService, market string, source pb.RateSource) (*service.Rate, string, error) {
+	if s.dbOnly() {
+		rate, err := s.storedRate(market)
+		return rate, dataSourceDatabase, err
+	}
+
+	rate, fetched, err := s.cachedLiveRate(ctx, grinexSvc, market, source)
+	if err != nil {
+		return nil, "", err
+	}
+	if !fetched {
+		return rate, dataSourceCache, nil
+	}
+	if grinexSvc != s.grinexSvc {
+		return rate, dataSourceGrinex, nil
+	}
+	if err := s.saveRate(rate); err != nil {
+		s.logger.Error("Failed to save rate to database", zap.Error(err))
+	}
+	return rate, dataSourceGrinex, nil
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+// sequenceTradesHandler serves a single trade whose price is taken from prices in turn, repeating the last one
+func sequenceTradesHandler(requests *atomic.Int32, prices ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		i := min(int(requests.Add(1))-1, len(prices)-1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `[{"id": 1, "price": %q, "market": "usdtrub", "created_at": "2025-07-28T21:22:14+03:00"}]`, prices[i])
+	}
+}
+
+func TestStreamRates(t *testing.T) {
+	var requests atomic.Int32
+	srv, mock := newTestServer(t, sequenceTradesHandler(&requests, "81.20", "81.25"))
+	client := newTestClient(t, srv)
+
+	mock.MatchExpectationsInOrder(false)
+	for i := 0; i < 3; i++ {
+		mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(i + 1))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.StreamRates(ctx, &pb.StreamRatesReq{Interval: durationpb.New(time.Millisecond)})
+	require.NoError(t, err)
+
+	first, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "USDT/RUB", first.TradingPair)
+	assert.Equal(t, 81.20, first.AskPrice)
+
+	second, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, 81.25, second.AskPrice)
+
+	cancel()
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Canceled, status.Code(err))
+}
+
+func TestStreamRates_SkipsUnchangedRates(t *testing.T) {
+	var requests atomic.Int32
+	srv, mock := newTestServer(t, sequenceTradesHandler(&requests, "81.20", "81.20", "81.30"))
+	client := newTestClient(t, srv)
+
+	// Unchanged rates are still stored, only the stream skips them
+	mock.MatchExpectationsInOrder(false)
+	for i := 0; i < 4; i++ {
+		mock.ExpectQuery("INSERT INTO rates").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(i + 1))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.StreamRates(ctx, &pb.StreamRatesReq{Interval: durationpb.New(minStreamInterval)})
+	require.NoError(t, err)
+
+	first, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, 81.20, first.AskPrice)
+
+	second, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, 81.30, second.AskPrice)
+	assert.GreaterOrEqual(t, requests.Load(), int32(3))
+}
+
+func TestStreamRates_InvalidRequest(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	client := newTestClient(t, srv)
+
+	tests := []struct {
+		name string
+		req  *pb.StreamRatesReq
+	}{
+		{"negative interval", &pb.StreamRatesReq{Interval: durationpb.New(-time.Second)}},
+		{"unknown pair", &pb.StreamRatesReq{TradingPair: "DOGE/RUB"}},
+		{"invalid timezone", &pb.StreamRatesReq{Timezone: "Mars/Olympus"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream, err := client.StreamRates(context.Background(), tt.req)
+			require.NoError(t, err)
+
+			_, err = stream.Recv()
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
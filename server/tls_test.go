@@ -0,0 +1,257 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "github.com/atadzan/grinex-rate-service/proto/v1"
+)
+
+// testCert is a generated certificate with its key, signed by parent or self-signed
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+func newTestCert(t *testing.T, cn string, parent *testCert, isCA bool) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:              []string{cn},
+		BasicConstraintsValid: true,
+	}
+	if isCA {
+		template.IsCA = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	}
+
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+// writePEM writes the certificate and its key as PEM files in dir
+func (c *testCert) writePEM(t *testing.T, dir, name string) (certFile, keyFile string) {
+	t.Helper()
+
+	certFile = filepath.Join(dir, name+".crt")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0o600))
+
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+	keyFile = filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	return certFile, keyFile
+}
+
+// serveTLS serves srv over bufconn with the TLS configuration and returns the listener
+func serveTLS(t *testing.T, srv *RateServiceServer, tlsConfig *tls.Config, opts ...grpc.ServerOption) *bufconn.Listener {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer(append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))...)
+	pb.RegisterRateServiceServer(s, srv)
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+
+	return lis
+}
+
+// dialBufconn returns a client connecting to lis with the transport credentials
+func dialBufconn(t *testing.T, lis *bufconn.Listener, creds credentials.TransportCredentials) pb.RateServiceClient {
+	t.Helper()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(creds),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewRateServiceClient(conn)
+}
+
+// newMTLSServer serves srv over bufconn with mutual TLS and returns a dialer for clients
+func newMTLSServer(t *testing.T, srv *RateServiceServer, opts ...grpc.ServerOption) (*testCert, func(*testing.T, *tls.Config) pb.RateServiceClient) {
+	t.Helper()
+
+	dir := t.TempDir()
+	ca := newTestCert(t, "Test CA", nil, true)
+	serverCert := newTestCert(t, "localhost", ca, false)
+
+	certFile, keyFile := serverCert.writePEM(t, dir, "server")
+	caFile, _ := ca.writePEM(t, dir, "ca")
+
+	tlsConfig, err := LoadServerTLSConfig(certFile, keyFile, caFile)
+	require.NoError(t, err)
+	require.Equal(t, tls.RequireAndVerifyClientCert, tlsConfig.ClientAuth)
+
+	lis := serveTLS(t, srv, tlsConfig, opts...)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	dial := func(t *testing.T, clientTLS *tls.Config) pb.RateServiceClient {
+		clientTLS.RootCAs = roots
+		clientTLS.ServerName = "localhost"
+		return dialBufconn(t, lis, credentials.NewTLS(clientTLS))
+	}
+
+	return ca, dial
+}
+
+// newSelfSignedTLSServer serves srv over bufconn with server-side TLS only, using a self-signed
+// certificate, and returns the listener with the certificate clients have to trust
+func newSelfSignedTLSServer(t *testing.T, srv *RateServiceServer) (*bufconn.Listener, *testCert) {
+	t.Helper()
+
+	serverCert := newTestCert(t, "localhost", nil, false)
+	certFile, keyFile := serverCert.writePEM(t, t.TempDir(), "server")
+
+	tlsConfig, err := LoadServerTLSConfig(certFile, keyFile, "")
+	require.NoError(t, err)
+	require.Equal(t, tls.NoClientCert, tlsConfig.ClientAuth)
+
+	return serveTLS(t, srv, tlsConfig), serverCert
+}
+
+func TestTLS_SelfSignedClientConnects(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	lis, serverCert := newSelfSignedTLSServer(t, srv)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(serverCert.cert)
+	client := dialBufconn(t, lis, credentials.NewTLS(&tls.Config{RootCAs: roots, ServerName: "localhost"}))
+
+	resp, err := client.Healthcheck(context.Background(), &pb.HealthcheckReq{})
+
+	require.NoError(t, err)
+	assert.Equal(t, "healthy", resp.Status)
+}
+
+func TestTLS_RejectsPlaintextClient(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	lis, _ := newSelfSignedTLSServer(t, srv)
+
+	client := dialBufconn(t, lis, insecure.NewCredentials())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := client.Healthcheck(ctx, &pb.HealthcheckReq{})
+	assert.Error(t, err)
+}
+
+func TestMutualTLS_SignedClient(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+
+	var commonName string
+	capture := func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		commonName, _ = ClientCommonName(ctx)
+		return handler(ctx, req)
+	}
+	ca, dial := newMTLSServer(t, srv, grpc.UnaryInterceptor(capture))
+
+	clientCert := newTestCert(t, "billing-service", ca, false)
+	client := dial(t, &tls.Config{Certificates: []tls.Certificate{clientCert.tlsCertificate()}})
+
+	_, err := client.GetClockInfo(context.Background(), &pb.ClockInfoReq{})
+
+	require.NoError(t, err)
+	assert.Equal(t, "billing-service", commonName)
+}
+
+func TestMutualTLS_RejectsUnsignedClient(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	_, dial := newMTLSServer(t, srv)
+
+	unsigned := newTestCert(t, "intruder", nil, false)
+	client := dial(t, &tls.Config{Certificates: []tls.Certificate{unsigned.tlsCertificate()}})
+
+	_, err := client.GetClockInfo(context.Background(), &pb.ClockInfoReq{})
+	assert.Error(t, err)
+}
+
+func TestMutualTLS_RejectsClientWithoutCert(t *testing.T) {
+	srv, _ := newTestServer(t, tradesHandler)
+	_, dial := newMTLSServer(t, srv)
+
+	client := dial(t, &tls.Config{})
+
+	_, err := client.GetClockInfo(context.Background(), &pb.ClockInfoReq{})
+	assert.Error(t, err)
+}
+
+func TestLoadServerTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	cert := newTestCert(t, "localhost", nil, false)
+	certFile, keyFile := cert.writePEM(t, dir, "server")
+
+	tlsConfig, err := LoadServerTLSConfig("", "", "")
+	assert.NoError(t, err)
+	assert.Nil(t, tlsConfig, "plaintext without a certificate")
+
+	tlsConfig, err = LoadServerTLSConfig(certFile, keyFile, "")
+	require.NoError(t, err)
+	assert.Equal(t, tls.NoClientCert, tlsConfig.ClientAuth)
+
+	_, err = LoadServerTLSConfig("", "", filepath.Join(dir, "ca.crt"))
+	assert.Error(t, err, "a client CA requires a server certificate")
+
+	_, err = LoadServerTLSConfig(certFile, keyFile, keyFile)
+	assert.Error(t, err, "the client CA file must contain certificates")
+}
+
+func TestClientCommonName_NoPeer(t *testing.T) {
+	_, ok := ClientCommonName(context.Background())
+	assert.False(t, ok)
+}